@@ -0,0 +1,33 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// protoc-gen-md-diagrams generates the markdown documentation of the files as a
+// protoc or buf plugin, e.g. `protoc --md-diagrams_out=docs library.proto`.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/GoogleCloudPlatform/proto-gen-md-diagrams/pkg/proto"
+)
+
+func main() {
+	if err := proto.ExecutePlugin(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "protoc-gen-md-diagrams: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -4,7 +4,10 @@ go 1.21.3
 
 replace github.com/GoogleCloudPlatform/proto-gen-md-diagrams => ./pkg/proto
 
-require github.com/stretchr/testify v1.10.0
+require (
+	github.com/stretchr/testify v1.10.0
+	google.golang.org/protobuf v1.34.2
+)
 
 require github.com/davecgh/go-spew v1.1.1 // indirect
 
@@ -20,8 +20,9 @@ import "strings"
 
 // Annotation is an inline structure applicable only to attributes
 type Annotation struct {
-	Name  string
-	Value any
+	Name     string
+	Value    any
+	Constant *OptionValue
 }
 
 // NewAnnotation is the Annotation Constructor
@@ -30,16 +31,72 @@ func NewAnnotation(name string, value any) *Annotation {
 }
 
 // ParseAnnotations is used for reading the annotation line and marshalling it into
-// the annotation structure.
+// the annotation structure. Multiple annotations are separated by commas,
+// e.g. [default = 10, deprecated = true]. Values may be aggregates, e.g.
+// [(my.rules) = { min: 1, max: 10 }].
 func ParseAnnotations(in string) []*Annotation {
 	Log.Debug("Processing Annotation")
 	out := make([]*Annotation, 0)
-	if strings.Contains(in, OpenBracket) && strings.Contains(in, ClosedBracket) {
-		annotationString := in[strings.Index(in, OpenBracket)+1 : strings.Index(in, ClosedBracket)]
-		split := strings.Split(strings.ReplaceAll(annotationString, SingleQuote, Empty), "=")
+	for _, a := range CompactOptions(in) {
+		split := strings.SplitN(a, "=", 2)
 		if len(split) > 1 {
-			out = append(out, NewAnnotation(strings.TrimSpace(split[0]), strings.TrimSpace(split[1])))
+			value := strings.TrimSpace(split[1])
+			annotation := NewAnnotation(SpaceRemover.ReplaceAllString(split[0], Empty), strings.ReplaceAll(value, SingleQuote, Empty))
+			annotation.Constant = NewOptionValue(value)
+			out = append(out, annotation)
 		}
 	}
 	return out
 }
+
+// CompactOptions returns the `name = value` entries of the bracketed options
+// of a field or an enum value.
+func CompactOptions(in string) []string {
+	start := strings.Index(in, OpenBracket)
+	end := strings.LastIndex(in, ClosedBracket)
+	if start < 0 || end < start {
+		return make([]string, 0)
+	}
+	return SplitAnnotations(in[start+1 : end])
+}
+
+// SplitAnnotations splits an annotation body on the commas that are not
+// enclosed in a quoted string or in an aggregate value.
+func SplitAnnotations(in string) []string {
+	out := make([]string, 0)
+	quote := Empty
+	escaped := false
+	depth := 0
+	current := ""
+	for _, r := range in {
+		c := string(r)
+		switch {
+		case escaped:
+			escaped = false
+		case len(quote) > 0 && c == "\\":
+			escaped = true
+		case len(quote) > 0:
+			if c == quote {
+				quote = Empty
+			}
+		case c == DoubleQuote || c == SingleQuote:
+			quote = c
+		case c == OpenBrace || c == OpenBracket || c == "<":
+			depth++
+		case c == CloseBrace || c == ClosedBracket || c == ">":
+			depth--
+		case c == Comma && depth == 0:
+			out = append(out, current)
+			current = Empty
+			continue
+		}
+		current += c
+	}
+	return append(out, current)
+}
+
+// IsOpenAnnotation determines if the line ends inside of an annotation with an
+// aggregate value, e.g. `string name = 1 [(my.rules) = {`.
+func IsOpenAnnotation(in *Line) bool {
+	return in.Token == OpenBrace && strings.Count(in.Syntax, OpenBracket) > strings.Count(in.Syntax, ClosedBracket)
+}
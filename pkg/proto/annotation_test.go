@@ -32,7 +32,7 @@ func TestNewAnnotation(t *testing.T) {
 		args args
 		want *Annotation
 	}{
-		{name: "test 001", args: args{name: "test", value: "test"}, want: &Annotation{"test", "test"}},
+		{name: "test 001", args: args{name: "test", value: "test"}, want: &Annotation{Name: "test", Value: "test"}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -50,10 +50,17 @@ func TestParseAnnotations(t *testing.T) {
 		args args
 		want []*Annotation
 	}{
-		{name: "Test 001", args: args{in: "int32 longitude_degrees = 3 [json_name = 'lng_d'];"}, want: []*Annotation{{Name: "json_name", Value: "lng_d"}}},
+		{name: "Test 001", args: args{in: "int32 longitude_degrees = 3 [json_name = 'lng_d'];"}, want: []*Annotation{{Name: "json_name", Value: "lng_d", Constant: &OptionValue{Value: "lng_d", Quoted: true}}}},
 		// note that even if the source file declares the annotation with white space around `=` some pre-processor upstream of the annotation parser strips it
-		{name: "Test without whitespace", args: args{in: "optional uint32 weight = 18 [deprecated=true];"}, want: []*Annotation{{Name: "deprecated", Value: "true"}}},
+		{name: "Test without whitespace", args: args{in: "optional uint32 weight = 18 [deprecated=true];"}, want: []*Annotation{{Name: "deprecated", Value: "true", Constant: &OptionValue{Kind: OptionValueIdentifier, Value: "true"}}}},
 		// projects that import google/protobuf/timestamp.proto end up parsing the large comment block for annotation and runs into [toISOString()]. There must be another bug upstream, but the Annotation parser shall be protected too.
+		{name: "Test multiple", args: args{in: `optional string name = 1 [default = "a, b", deprecated = true];`}, want: []*Annotation{{Name: "default", Value: `"a, b"`, Constant: &OptionValue{Value: "a, b", Quoted: true}}, {Name: "deprecated", Value: "true", Constant: &OptionValue{Kind: OptionValueIdentifier, Value: "true"}}}},
+		{name: "Test aggregate", args: args{in: `string name = 1 [(my.rules) = { min: 1, in: ["a", "b"] }, ( my.ext ) = 'c'];`}, want: []*Annotation{
+			{Name: "(my.rules)", Value: `{ min: 1, in: ["a", "b"] }`, Constant: &OptionValue{Kind: OptionValueMessage, Fields: []*OptionField{
+				{Name: "min", Value: &OptionValue{Value: "1"}},
+				{Name: "in", Value: &OptionValue{Kind: OptionValueList, List: []*OptionValue{{Value: "a", Quoted: true}, {Value: "b", Quoted: true}}}},
+			}}},
+			{Name: "(my.ext)", Value: "c", Constant: &OptionValue{Value: "c", Quoted: true}}}},
 		{name: "Test google.protobuf.timestamp.proto", args: args{in: "...using the // standard // [toISOString()](https://developer.mozilla.org/en-US/docs/Web/JavaScript/Reference/Global_Objects/Date/toISOString) // method"}, want: []*Annotation{}},
 	}
 	for _, tt := range tests {
@@ -62,3 +69,13 @@ func TestParseAnnotations(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitAnnotations(t *testing.T) {
+	assert.Equal(t, []string{"a = 1", ` b = { c: 1, d: "e,f" }`, ` g = 'h\', i'`}, SplitAnnotations(`a = 1, b = { c: 1, d: "e,f" }, g = 'h\', i'`))
+}
+
+func TestIsOpenAnnotation(t *testing.T) {
+	assert.True(t, IsOpenAnnotation(&Line{Syntax: "string name = 1 [(my.rules) =", Token: OpenBrace}))
+	assert.False(t, IsOpenAnnotation(&Line{Syntax: "message Test", Token: OpenBrace}))
+	assert.False(t, IsOpenAnnotation(&Line{Syntax: "string name = 1 [deprecated = true]", Token: Semicolon}))
+}
@@ -3,6 +3,7 @@ package proto
 import (
 	"encoding/json"
 	"flag"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -15,6 +16,32 @@ var writeOutputFlag *bool
 var visualizeFlag *bool
 var outputFlag *string
 var pureMdOutputFlag *bool
+var strictFlag *bool
+var commentsFlag *string
+var importsFlag *bool
+var externalFlag *string
+var diagramFlag *string
+var includeFlag includeRoots
+var descriptorSetFlag *string
+var descriptorSetOutFlag *string
+var descriptorSetFormatFlag *string
+var lintFlag *bool
+var lintConfigFlag *string
+var docCoverageFlag *string
+var minDocCoverageFlag *float64
+var dotOutFlag *string
+
+// includeRoots are the directories given with repeated -I flags.
+type includeRoots []string
+
+func (ir *includeRoots) String() string {
+	return strings.Join(*ir, string(filepath.ListSeparator))
+}
+
+func (ir *includeRoots) Set(value string) error {
+	*ir = append(*ir, filepath.SplitList(value)...)
+	return nil
+}
 
 const (
 	ProtobufSuffix = ".proto"
@@ -27,6 +54,21 @@ func init() {
 	writeOutputFlag = flag.Bool("w", true, "Enable writing output")
 	pureMdOutputFlag = flag.Bool("md", false, "Enable pure MD output")
 	visualizeFlag = flag.Bool("v", true, "Enable Visualization")
+	strictFlag = flag.Bool("strict", false, "Exit with a non-zero status when errors are found in the protobuf files.")
+	commentsFlag = flag.String("comments", "all", "The comments to render: all, attached (leading and trailing) or leading.")
+	importsFlag = flag.Bool("imports", false, "Generate documentation for the imported files read from the include roots.")
+	externalFlag = flag.String("external", "node", "How the bundled well-known and googleapis types are rendered: node, hide or alias.")
+	diagramFlag = flag.String("diagram", "mermaid", "The syntax of the diagrams embedded in the markdown: mermaid or plantuml.")
+	flag.Var(&includeFlag, "I", "A directory to search for imports, may be repeated. (default the directoryFlag)")
+	flag.Var(&includeFlag, "proto_path", "Same as -I.")
+	descriptorSetFlag = flag.String("descriptor_set", "", "Read a binary FileDescriptorSet, e.g. written by protoc --descriptor_set_out --include_source_info, instead of the directoryFlag.")
+	descriptorSetOutFlag = flag.String("descriptor_set_out", "", "Write the read files and their imports as a FileDescriptorSet with source info to the file.")
+	descriptorSetFormatFlag = flag.String("descriptor_set_format", "binary", "The format of the -descriptor_set_out file: binary or json.")
+	lintFlag = flag.Bool("lint", false, "Check the files against the protobuf style guide instead of writing markdown, exits with a non-zero status when problems are found.")
+	lintConfigFlag = flag.String("lint_config", "", "A JSON file enabling or disabling lint rules, e.g. {\"rules\": {\"ENUM_VALUE_PREFIX\": false}}.")
+	docCoverageFlag = flag.String("doc-coverage", "", "Write a documentation coverage report to the file, as JSON when it ends with .json, as markdown otherwise.")
+	minDocCoverageFlag = flag.Float64("min-doc-coverage", 0, "Exit with a non-zero status when the percentage of documented messages, fields, enums, enum values, services and rpcs is below the value.")
+	dotOutFlag = flag.String("dot_out", "", "Write a Graphviz DOT graph of each file, and of all the files as tree.dot, to the directory.")
 	outputFlag = flag.String("o", ".", "Specifies the outputFlag directoryFlag, if not specified, the processor will write markdown in the proto directories.")
 }
 
@@ -44,47 +86,297 @@ func debugPackages(packages []*Package, logger *Logger) {
 	}
 }
 
+// reportDiagnostics prints the diagnostics found while reading the packages
+// followed by a summary.
+func reportDiagnostics(diagnostics Diagnostics, logger *Logger) {
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError {
+			logger.Errorf("%s\n", d)
+		} else {
+			logger.Warnf("%s\n", d)
+		}
+	}
+	if len(diagnostics) > 0 {
+		logger.Infof("Diagnostics: %s\n", diagnostics.Summary())
+	}
+}
+
+// readDescriptorSetFile reads the packages of a binary FileDescriptorSet file,
+// the files that are bundled are marked as such.
+func readDescriptorSetFile(path string) ([]*Package, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	packages, err := ReadDescriptorSet(file)
+	if err != nil {
+		return nil, err
+	}
+	MarkBundled(packages...)
+	return packages, nil
+}
+
+// writeDescriptorSetFile writes the packages as a FileDescriptorSet file. The
+// files are named by their import paths when an importer is given, the options
+// that cannot be written are returned as warnings.
+func writeDescriptorSetFile(path string, format DescriptorFormat, packages []*Package, importer *Importer) (Diagnostics, error) {
+	names := make(map[*Package]string)
+	if importer != nil {
+		for _, p := range packages {
+			if name, ok := importer.ImportPath(p.Path); ok && !p.Bundled {
+				names[p] = name
+			}
+		}
+	}
+	set, diagnostics := PackagesToDescriptorSet(packages, names)
+	data, err := MarshalDescriptorSet(set, format)
+	if err != nil {
+		return diagnostics, err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return diagnostics, err
+	}
+	return diagnostics, os.WriteFile(path, data, 0644)
+}
+
+// writeReport writes a report as JSON when the path ends with .json, as
+// markdown otherwise.
+func writeReport(path string, markdown string, toJSON func() ([]byte, error)) error {
+	data := []byte(markdown)
+	if strings.HasSuffix(path, ".json") {
+		var err error
+		if data, err = toJSON(); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// writeDotFiles writes the DOT graph of each file to the directory, named after
+// its path with a .dot suffix, and the graph of all the files as DotTreeFile.
+func writeDotFiles(directory string, packages []*Package, paths map[*Package]string, wc *WriterConfig) error {
+	for _, p := range packages {
+		out := filepath.Join(directory, paths[p]+".dot")
+		if err := os.MkdirAll(filepath.Dir(out), 0750); err != nil {
+			return err
+		}
+		if err := os.WriteFile(out, []byte(PackagesToDot(filepath.ToSlash(paths[p]), []*Package{p}, wc)), 0644); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(directory, 0750); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(directory, DotTreeFile), []byte(PackagesToDot("tree", packages, wc)), 0644)
+}
+
+// CommandBreaking is the command comparing two directories for breaking
+// changes.
+const CommandBreaking = "breaking"
+
+// treeRoots returns the include roots of a directory, the relative roots are
+// relative to the directory.
+func treeRoots(directory string, roots includeRoots) []string {
+	out := make([]string, 0, len(roots))
+	for _, root := range roots {
+		if !filepath.IsAbs(root) {
+			root = filepath.Join(directory, root)
+		}
+		out = append(out, root)
+	}
+	return out
+}
+
+// readTrees reads the old and new directories of a comparison command.
+func readTrees(old string, new string, roots includeRoots, logger *Logger) (*Tree, *Tree, error) {
+	trees := make([]*Tree, 0, 2)
+	for _, directory := range []string{old, new} {
+		logger.Infof("Reading Directory : %s\n", directory)
+		tree, err := ReadTree(directory, treeRoots(directory, roots)...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to process directory: %s with error: %v", directory, err)
+		}
+		reportDiagnostics(tree.Diagnostics, logger)
+		trees = append(trees, tree)
+	}
+	return trees[0], trees[1], nil
+}
+
+// ExecuteBreaking runs the breaking command with its arguments, and returns the
+// exit status: 1 when breaking changes are found, 2 when the directories
+// cannot be read.
+func ExecuteBreaking(args []string) int {
+	flags := flag.NewFlagSet(CommandBreaking, flag.ExitOnError)
+	oldFlag := flags.String("old", "", "The directory of the previous version of the protobuf files, e.g. a checkout of the last release.")
+	newFlag := flags.String("new", ".", "The directory of the current version of the protobuf files.")
+	outFlag := flags.String("o", "", "Write the report to the file, as JSON when it ends with .json, as markdown otherwise. (default the standard output)")
+	var roots includeRoots
+	flags.Var(&roots, "I", "A directory to search for imports, relative to the old and new directories, may be repeated. (default the directories)")
+	_ = flags.Parse(args)
+
+	// The report may be printed to the standard output, the messages and the
+	// diagnostics are kept apart
+	SetOutput(os.Stderr)
+	logger := Log
+	if len(*oldFlag) == 0 {
+		logger.Errorf("the -old directory is required\n")
+		return 2
+	}
+	old, current, err := readTrees(*oldFlag, *newFlag, roots, logger)
+	if err != nil {
+		logger.Errorf("%v\n", err)
+		return 2
+	}
+	report := Breaking(old, current)
+	if len(*outFlag) == 0 {
+		fmt.Print(report.ToMarkdown())
+	} else if err = writeReport(*outFlag, report.ToMarkdown(), report.ToJSON); err != nil {
+		logger.Errorf("failed to write report: %s with error: %v", *outFlag, err)
+		return 2
+	}
+	if report.HasChanges() {
+		logger.Errorf("%d breaking changes found\n", len(report.Changes))
+		return 1
+	}
+	return 0
+}
+
+// CommandDiff is the command writing the changelog between two directories.
+const CommandDiff = "diff"
+
+// ExecuteDiff runs the diff command with its arguments, and returns the exit
+// status: 2 when the directories cannot be read, 0 otherwise.
+func ExecuteDiff(args []string) int {
+	flags := flag.NewFlagSet(CommandDiff, flag.ExitOnError)
+	oldFlag := flags.String("old", "", "The directory of the previous version of the protobuf files, e.g. a checkout of the last release.")
+	newFlag := flags.String("new", ".", "The directory of the current version of the protobuf files.")
+	outFlag := flags.String("o", "", "Write the changelog to the file, as JSON when it ends with .json, as markdown otherwise. (default the standard output)")
+	var roots includeRoots
+	flags.Var(&roots, "I", "A directory to search for imports, relative to the old and new directories, may be repeated. (default the directories)")
+	_ = flags.Parse(args)
+
+	// The changelog may be printed to the standard output, the messages and
+	// the diagnostics are kept apart
+	SetOutput(os.Stderr)
+	logger := Log
+	if len(*oldFlag) == 0 {
+		logger.Errorf("the -old directory is required\n")
+		return 2
+	}
+	old, current, err := readTrees(*oldFlag, *newFlag, roots, logger)
+	if err != nil {
+		logger.Errorf("%v\n", err)
+		return 2
+	}
+	changelog := Diff(old, current)
+	if len(*outFlag) == 0 {
+		fmt.Print(changelog.ToMarkdown())
+	} else if err = writeReport(*outFlag, changelog.ToMarkdown(), changelog.ToJSON); err != nil {
+		logger.Errorf("failed to write changelog: %s with error: %v", *outFlag, err)
+		return 2
+	}
+	return 0
+}
+
 func Execute() {
+	if len(os.Args) > 1 && os.Args[1] == CommandBreaking {
+		os.Exit(ExecuteBreaking(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == CommandDiff {
+		os.Exit(ExecuteDiff(os.Args[2:]))
+	}
 	flag.Parse()
 
 	SetDebug(*debugFlag)
 	logger := Log
-	logger.Infof("Reading Directory : %s\n", *directoryFlag)
-	logger.Infof("Recursively: %v\n", *recursiveFlag)
-
 	packages := make([]*Package, 0)
+	imported := make([]*Package, 0)
+	diagnostics := make(Diagnostics, 0)
+	var importer *Importer
+	var err error
+
+	// An invalid descriptor set format fails before the files are read
+	format, err := ParseDescriptorFormat(*descriptorSetFormatFlag)
+	if err != nil {
+		logger.Errorf("%v\n", err)
+		os.Exit(1)
+	}
 
-	err := filepath.Walk(*directoryFlag, func(path string, info os.FileInfo, err error) error {
+	if len(*descriptorSetFlag) > 0 {
+		logger.Infof("Reading Descriptor Set : %s\n", *descriptorSetFlag)
+		packages, err = readDescriptorSetFile(*descriptorSetFlag)
 		if err != nil {
-			return err
+			logger.Errorf("failed to read descriptor set: %s with error: %v", *descriptorSetFlag, err)
 		}
-		if strings.HasSuffix(path, ProtobufSuffix) {
+		diagnostics = append(diagnostics, Validate(packages...)...)
+	} else {
+		logger.Infof("Reading Directory : %s\n", *directoryFlag)
+		logger.Infof("Recursively: %v\n", *recursiveFlag)
 
-			pkg := NewPackage(path)
-			err := pkg.Read(*debugFlag)
-			if err != nil {
-				logger.Errorf("error while reading package %s with value: %v", path, err)
-			}
-			packages = append(packages, pkg)
+		var tree *Tree
+		tree, err = ReadTree(*directoryFlag, includeFlag...)
+		packages, imported, importer = tree.Packages, tree.Imported, tree.Importer
+		diagnostics = append(diagnostics, tree.Diagnostics...)
+		if err != nil {
+			logger.Errorf("failed to process directoryFlag: %s with error: %v", *directoryFlag, err)
 		}
-		return nil
-	})
+	}
+
+	if len(*descriptorSetOutFlag) > 0 {
+		logger.Infof("Writing Descriptor Set : %s\n", *descriptorSetOutFlag)
+		setDiagnostics, err := writeDescriptorSetFile(*descriptorSetOutFlag, format, append(append(make([]*Package, 0), packages...), imported...), importer)
+		if err != nil {
+			logger.Errorf("failed to write descriptor set: %s with error: %v", *descriptorSetOutFlag, err)
+		}
+		diagnostics = append(diagnostics, setDiagnostics...)
+	}
 
 	// Send outputFlag to debugFlag if enabled.
 	debugPackages(packages, logger)
 
+	comments, err := ParseCommentStyle(*commentsFlag)
+	if err != nil {
+		logger.Errorf("%v\n", err)
+	}
+	external, err := ParseExternalStyle(*externalFlag)
 	if err != nil {
-		logger.Errorf("failed to process directoryFlag: %s with error: %v", *directoryFlag, err)
+		logger.Errorf("%v\n", err)
+	}
+	diagram, err := ParseDiagramStyle(*diagramFlag)
+	if err != nil {
+		logger.Errorf("%v\n", err)
 	}
 
 	config := &WriterConfig{
 		visualize:    *visualizeFlag,
 		pureMarkdown: *pureMdOutputFlag,
+		comments:     comments,
+		external:     external,
+		diagram:      diagram,
+		outputs:      make(map[*Package]string),
 	}
 
+	documented := make([]*Package, 0)
+	// paths are the paths of the documented files, relative to the output
+	// directory
+	paths := make(map[*Package]string)
 	for _, pkg := range packages {
+		if len(*descriptorSetFlag) > 0 {
+			// The files of a descriptor set are written with their names,
+			// the bundled files included with the imports are not documented
+			if pkg.Bundled {
+				continue
+			}
+			paths[pkg] = filepath.FromSlash(pkg.Path)
+			config.outputs[pkg] = filepath.Join(*outputFlag, paths[pkg]+".md")
+			documented = append(documented, pkg)
+			continue
+		}
 		bName := filepath.Base(pkg.Path)
-		out := *outputFlag + string(filepath.Separator) + bName + ".md"
 		// get the relative path to the protofile based on the input directory
 		fileRelativeToInputDir, err := filepath.Rel(*directoryFlag, pkg.Path)
 		if err != nil {
@@ -92,7 +384,48 @@ func Execute() {
 		}
 
 		relativeDir := filepath.Dir(fileRelativeToInputDir)
-		out = filepath.Join(*outputFlag, relativeDir, bName+".md")
+		paths[pkg] = filepath.Join(relativeDir, bName)
+		config.outputs[pkg] = filepath.Join(*outputFlag, paths[pkg]+".md")
+		documented = append(documented, pkg)
+	}
+	packages = documented
+	if *importsFlag && importer != nil {
+		// Imported files are written with their import path, the bundled
+		// files are not documented
+		for _, pkg := range imported {
+			if pkg.Bundled {
+				continue
+			}
+			importPath, _ := importer.ImportPath(pkg.Path)
+			paths[pkg] = filepath.FromSlash(importPath)
+			config.outputs[pkg] = filepath.Join(*outputFlag, paths[pkg]+".md")
+			packages = append(packages, pkg)
+		}
+	}
+
+	if *lintFlag {
+		lintConfig := NewLintConfig()
+		if len(*lintConfigFlag) > 0 {
+			lintConfig, err = ReadLintConfig(*lintConfigFlag)
+			if err != nil {
+				logger.Errorf("failed to read lint config: %s with error: %v", *lintConfigFlag, err)
+				os.Exit(1)
+			}
+		}
+		// Only the lint findings and the errors fail the lint, other warnings
+		// are reported
+		findings := Lint(lintConfig, packages...)
+		failed := len(findings) > 0 || diagnostics.HasErrors()
+		diagnostics = append(diagnostics, findings...)
+		reportDiagnostics(diagnostics, logger)
+		if failed {
+			os.Exit(1)
+		}
+		return
+	}
+
+	for _, pkg := range packages {
+		out := config.outputs[pkg]
 		markdown := PackageToMarkDown(pkg, config)
 
 		if *writeOutputFlag {
@@ -100,7 +433,7 @@ func Execute() {
 			// we first have to ensure the directory exists before writing the file
 			err = os.MkdirAll(filepath.Dir(out), 0750)
 			if err != nil {
-				logger.Errorf("Could not create subdirectories", err)
+				logger.Errorf("Could not create subdirectories %v\n", err)
 				return
 			}
 			err = os.WriteFile(out, []byte(markdown), 0644)
@@ -109,4 +442,31 @@ func Execute() {
 			logger.Errorf("failed to write file %v\n", err)
 		}
 	}
+
+	if len(*dotOutFlag) > 0 {
+		logger.Infof("Writing DOT graphs : %s\n", *dotOutFlag)
+		if err = writeDotFiles(*dotOutFlag, packages, paths, config); err != nil {
+			logger.Errorf("failed to write DOT graphs: %s with error: %v", *dotOutFlag, err)
+		}
+	}
+
+	reportDiagnostics(diagnostics, logger)
+
+	failed := *strictFlag && diagnostics.HasErrors()
+	if len(*docCoverageFlag) > 0 || *minDocCoverageFlag > 0 {
+		report := NewCoverageReport(packages...)
+		logger.Infof("Documentation Coverage: %s\n", report.Coverage)
+		if len(*docCoverageFlag) > 0 {
+			if err = writeReport(*docCoverageFlag, report.ToMarkdown(), report.ToJSON); err != nil {
+				logger.Errorf("failed to write coverage report: %s with error: %v", *docCoverageFlag, err)
+			}
+		}
+		if report.Percentage < *minDocCoverageFlag {
+			logger.Errorf("documentation coverage %.2f%% is below the minimum of %.2f%%\n", report.Percentage, *minDocCoverageFlag)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
 }
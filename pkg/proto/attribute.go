@@ -16,15 +16,31 @@
 
 package proto
 
+import "strings"
+
 // An Attribute is a component in the message structure.
 type Attribute struct {
 	*Qualified
 	Repeated    bool
 	Optional    bool
+	Required    bool
 	Map         bool
+	Group       bool
 	Kind        []string
 	Ordinal     int
+	Default     string
+	Oneof       string
 	Annotations []*Annotation
+	// Features is the effective editions feature set of the attribute, it is
+	// resolved once the whole package has been read.
+	Features *Features
+	// ExplicitFeatures are the features of Features set explicitly, on the
+	// attribute, its enclosing declarations or its enum, or by the edition of
+	// the file. The labels of the attribute only report these.
+	ExplicitFeatures *Features
+	// Resolved are the declarations of the Kind types, set by Link, the
+	// entries of scalar types are nil.
+	Resolved []*Symbol
 }
 
 // IsValid implements the Validatable interface
@@ -32,16 +48,58 @@ func (a *Attribute) IsValid() bool {
 	return len(a.Name) > 0 && a.Kind != nil && len(a.Kind) >= 1 && a.Ordinal >= 1
 }
 
+// ResolvedKind returns the declaration of the i-th Kind type, nil when the
+// attribute is not linked or the type is a scalar.
+func (a *Attribute) ResolvedKind(i int) *Symbol {
+	if i < len(a.Resolved) {
+		return a.Resolved[i]
+	}
+	return nil
+}
+
+// HasDefault returns true if the attribute declares an explicit proto2 default value.
+func (a *Attribute) HasDefault() bool {
+	return len(a.Default) > 0
+}
+
 // ToMermaid implements a Mermaid Syntax per Attribute
 func (a *Attribute) ToMermaid() string {
+	out := ""
+	if a.Repeated {
+		out = Join("", "+ List~", a.Kind[0], "~ ", a.Name)
+	} else if a.Map {
+		out = Join("", "+ Map~", a.Kind[0], ", ", a.Kind[1], "~ ", a.Name)
+	} else if a.Optional {
+		out = Join("", "+ Optional~", a.Kind[0], "~ ", a.Name)
+	} else if a.Required {
+		out = Join("", "+ Required~", a.Kind[0], "~ ", a.Name)
+	} else {
+		out = Join(Space, "+", a.Kind[0], a.Name)
+	}
+	if a.HasDefault() {
+		out = Join(Space, out, "=", RemoveDoubleQuotes(a.Default))
+	}
+	return out
+}
+
+// ToPlantUML implements a PlantUML Syntax per Attribute
+func (a *Attribute) ToPlantUML() string {
+	out := ""
 	if a.Repeated {
-		return Join("", "+ List~", a.Kind[0], "~ ", a.Name)
+		out = Join("", "+ List<", a.Kind[0], "> ", a.Name)
 	} else if a.Map {
-		return Join("", "+ Map~", a.Kind[0], ", ", a.Kind[1], "~ ", a.Name)
+		out = Join("", "+ Map<", a.Kind[0], ", ", strings.TrimSpace(a.Kind[1]), "> ", a.Name)
 	} else if a.Optional {
-		return Join("", "+ Optional~", a.Kind[0], "~ ", a.Name)
+		out = Join("", "+ Optional<", a.Kind[0], "> ", a.Name)
+	} else if a.Required {
+		out = Join("", "+ Required<", a.Kind[0], "> ", a.Name)
+	} else {
+		out = Join(Space, "+", a.Kind[0], a.Name)
+	}
+	if a.HasDefault() {
+		out = Join(Space, out, "=", RemoveDoubleQuotes(a.Default))
 	}
-	return Join(Space, "+", a.Kind[0], a.Name)
+	return out
 }
 
 // NewAttribute is the Attribute constructor
@@ -61,9 +61,11 @@ func TestAttribute_ToMermaid(t *testing.T) {
 	type fields struct {
 		Qualified   *Qualified
 		Repeated    bool
+		Required    bool
 		Map         bool
 		Kind        []string
 		Ordinal     int
+		Default     string
 		Annotations []*Annotation
 	}
 	tests := []struct {
@@ -76,12 +78,18 @@ func TestAttribute_ToMermaid(t *testing.T) {
 			Name:      "Test",
 			Comment:   "This is a test",
 		}, Repeated: false, Map: false, Kind: []string{"string"}, Ordinal: 1}, want: "+ string Test"},
+		{name: "Test Required", fields: fields{Qualified: &Qualified{
+			Qualifier: "test.qualifier",
+			Name:      "Test",
+		}, Required: true, Kind: []string{"string"}, Ordinal: 1, Default: `"none"`}, want: "+ Required~string~ Test = none"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			a := &Attribute{
 				Qualified:   tt.fields.Qualified,
 				Repeated:    tt.fields.Repeated,
+				Required:    tt.fields.Required,
+				Default:     tt.fields.Default,
 				Map:         tt.fields.Map,
 				Kind:        tt.fields.Kind,
 				Ordinal:     tt.fields.Ordinal,
@@ -92,6 +100,24 @@ func TestAttribute_ToMermaid(t *testing.T) {
 	}
 }
 
+func TestAttribute_ToPlantUML(t *testing.T) {
+	tests := []struct {
+		name string
+		a    *Attribute
+		want string
+	}{
+		{name: "Scalar", a: &Attribute{Qualified: &Qualified{Name: "Test"}, Kind: []string{"string"}}, want: "+ string Test"},
+		{name: "Repeated", a: &Attribute{Qualified: &Qualified{Name: "Test"}, Repeated: true, Kind: []string{"string"}}, want: "+ List<string> Test"},
+		{name: "Map", a: &Attribute{Qualified: &Qualified{Name: "Test"}, Map: true, Kind: []string{"string", " int32"}}, want: "+ Map<string, int32> Test"},
+		{name: "Required", a: &Attribute{Qualified: &Qualified{Name: "Test"}, Required: true, Kind: []string{"string"}, Default: `"none"`}, want: "+ Required<string> Test = none"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, tt.a.ToPlantUML(), "ToPlantUML()")
+		})
+	}
+}
+
 func TestNewAttribute(t *testing.T) {
 	type args struct {
 		namespace string
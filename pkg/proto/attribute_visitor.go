@@ -17,6 +17,7 @@
 package proto
 
 import (
+	"fmt"
 	"strings"
 )
 
@@ -30,13 +31,18 @@ func NewAttributeVisitor() *AttributeVisitor {
 type AttributeVisitor struct {
 }
 
-// CanVisit - Determines if the line is an attribute, it doesn't end in a brace,
-// it's a map, repeated, optional, or can effectively be split
+// CanVisit - Determines if the line is an attribute, it ends in a semicolon
+// or an aggregate annotation value, it's a map, repeated, optional, required,
+// or can effectively be split
 func (av *AttributeVisitor) CanVisit(in *Line) bool {
-	return (!strings.HasSuffix(in.Syntax, OpenBrace) || !strings.HasSuffix(in.Syntax, CloseBrace)) &&
-			strings.HasPrefix(in.Syntax, "repeated") ||
-			strings.HasPrefix(in.Syntax, "optional") ||
-			strings.HasPrefix(in.Syntax, "map") || len(in.SplitSyntax()) >= 4
+	return (in.Token == Semicolon || IsOpenAnnotation(in)) &&
+		!strings.HasPrefix(in.Syntax, PrefixReserved) &&
+		!strings.HasPrefix(in.Syntax, PrefixExtensions+Space) &&
+		!strings.HasPrefix(in.Syntax, PrefixOption+Space) &&
+		(strings.HasPrefix(in.Syntax, PrefixRepeated) ||
+			strings.HasPrefix(in.Syntax, PrefixOptional) ||
+			strings.HasPrefix(in.Syntax, PrefixRequired) ||
+			strings.HasPrefix(in.Syntax, PrefixMap) || len(in.SplitSyntax()) >= 4)
 }
 
 // HandleRepeated marshals the attribute into a repeated representation, e.g. List.
@@ -59,17 +65,45 @@ func HandleOptional(out *Attribute, split []string) {
 	out.Ordinal = ParseOrdinal(split[4])
 }
 
-// handleMap marshals the attribute into a Map type by using multiple types for key and value.
-func HandleMap(out *Attribute, split []string) {
+// HandleRequired marshals the attribute into a proto2 required representation
+func HandleRequired(out *Attribute, split []string) {
+	Log.Debugf("\t processing required attribute %s", split[2])
+	// 0 - 4 required, type, name, equals, ordinal
+	out.Required = true
+	out.Kind = append(out.Kind, split[1])
+	out.Name = split[2]
+	out.Ordinal = ParseOrdinal(split[4])
+}
+
+// HandleDefaultValue copies a proto2 `[default = ...]` annotation into the
+// attribute's Default.
+func HandleDefaultValue(out *Attribute) {
+	for _, a := range out.Annotations {
+		if a.Name == AnnotationDefault {
+			out.Default = fmt.Sprintf("%v", a.Value)
+		}
+	}
+}
+
+// HandleMap marshals the attribute into a Map type by using multiple types for
+// key and value, it returns false when the map type is not `map<K, V>`.
+func HandleMap(out *Attribute, split []string) bool {
 	Log.Debugf("\t processing map attribute %s", split[2])
 	// map1, map2, name, equals, ordinal
 	mapValue := Join(Space, split[0], split[1])
-	innerTypes := mapValue[strings.Index(mapValue, OpenMap)+len(OpenMap) : strings.Index(mapValue, CloseMap)]
-	splitTypes := strings.Split(innerTypes, Comma)
+	start, end := strings.Index(mapValue, OpenMap), strings.Index(mapValue, CloseMap)
+	if start < 0 || end < start {
+		return false
+	}
+	splitTypes := strings.Split(mapValue[start+len(OpenMap):end], Comma)
+	if len(splitTypes) != 2 {
+		return false
+	}
 	out.Name = split[2]
 	out.Map = true
 	out.Kind = append(out.Kind, splitTypes...)
 	out.Ordinal = ParseOrdinal(split[4])
+	return true
 }
 
 // HandleDefaultAttribute marshals a standard attribute type.
@@ -83,23 +117,52 @@ func HandleDefaultAttribute(out *Attribute, split []string) {
 }
 
 // Visit is used for marshalling an attribute into a struct.
-func (av *AttributeVisitor) Visit(_ Scanner, in *Line, namespace string) interface{} {
+func (av *AttributeVisitor) Visit(scanner Scanner, in *Line, namespace string) interface{} {
 	Log.Debug("Visiting Attribute")
+	if IsOpenAnnotation(in) {
+		in = ReadStatement(scanner, in)
+	}
 	out := NewAttribute(namespace, in.Comment)
+	out.Location = in.Location
+	out.Comments = in.Comments
 	out.Annotations = ParseAnnotations(in.Syntax)
 	split := in.SplitSyntax()
 
+	if fields := attributeFieldCount(in.Syntax); len(split) < fields {
+		Report(scanner, SeverityError, in, namespace, "invalid field `%s`", in.Syntax)
+		return out
+	}
 	if strings.HasPrefix(in.Syntax, PrefixReserved) {
 		Log.Debug("\t processing reserved attribute")
 		out.Comment += Space + in.Comment
 	} else if strings.HasPrefix(in.Syntax, PrefixRepeated) {
 		HandleRepeated(out, split)
 	} else if strings.HasPrefix(in.Syntax, PrefixMap) {
-		HandleMap(out, split)
+		if !HandleMap(out, split) {
+			Report(scanner, SeverityError, in, namespace, "invalid map field `%s`", in.Syntax)
+			return out
+		}
 	} else if strings.HasPrefix(in.Syntax, PrefixOptional) {
 		HandleOptional(out, split)
+	} else if strings.HasPrefix(in.Syntax, PrefixRequired) {
+		HandleRequired(out, split)
 	} else {
 		HandleDefaultAttribute(out, split)
 	}
+	HandleDefaultValue(out)
+	if !out.IsValid() {
+		Report(scanner, SeverityError, in, namespace, "invalid field `%s`", in.Syntax)
+	}
 	return out
 }
+
+// attributeFieldCount returns the number of space separated values of a field
+// declaration, e.g. 5 for `repeated string names = 1;`.
+func attributeFieldCount(syntax string) int {
+	for _, prefix := range []string{PrefixRepeated, PrefixMap, PrefixOptional, PrefixRequired} {
+		if strings.HasPrefix(syntax, prefix) {
+			return 5
+		}
+	}
+	return 4
+}
@@ -60,6 +60,18 @@ func Test_attributeVisitor_CanVisit(t *testing.T) {
 			Token:   "{",
 			Comment: "",
 		}}, want: false},
+		{name: "Can Visit Required", args: args{in: &Line{
+			Syntax: "required string line1 = 1",
+			Token:  ";",
+		}}, want: true},
+		{name: "Can Not Visit Extensions", args: args{in: &Line{
+			Syntax: "extensions 100 to 199",
+			Token:  ";",
+		}}, want: false},
+		{name: "Can Not Visit Option", args: args{in: &Line{
+			Syntax: "option java_multiple_files = true",
+			Token:  ";",
+		}}, want: false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -155,7 +167,7 @@ func Test_HandleMap(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			HandleMap(tt.args.out, tt.args.split)
+			assert.True(t, HandleMap(tt.args.out, tt.args.split))
 			assert.True(t, tt.args.out.Map)
 		})
 	}
@@ -182,3 +194,44 @@ func Test_HandleRepeated(t *testing.T) {
 		})
 	}
 }
+
+func Test_HandleRequired(t *testing.T) {
+	out := NewAttribute("test", "Test")
+	HandleRequired(out, []string{"required", "string", "name", "=", "1"})
+	assert.True(t, out.Required)
+	assert.Equal(t, "name", out.Name)
+	assert.Equal(t, []string{"string"}, out.Kind)
+	assert.Equal(t, 1, out.Ordinal)
+}
+
+func Test_attributeVisitor_VisitDefault(t *testing.T) {
+	av := AttributeVisitor{}
+	out := av.Visit(nil, NewLine(`optional int32 page_size = 2 [default = 10, deprecated = true];`), "test").(*Attribute)
+	assert.True(t, out.Optional)
+	assert.Equal(t, "page_size", out.Name)
+	assert.Equal(t, 2, out.Ordinal)
+	assert.Equal(t, "10", out.Default)
+
+	out = av.Visit(nil, NewLine(`required string query = 1 [default = "a, b"];`), "test").(*Attribute)
+	assert.True(t, out.Required)
+	assert.Equal(t, "query", out.Name)
+	assert.Equal(t, `"a, b"`, out.Default)
+}
+
+func Test_attributeVisitor_VisitAggregateAnnotation(t *testing.T) {
+	av := AttributeVisitor{}
+	in := &Line{Syntax: "string name = 1 [(my.rules) =", Token: OpenBrace, Comment: "Name"}
+	assert.True(t, av.CanVisit(in))
+	scanner := NewTestScanner(`min_len: 1 }
+];
+string next = 2;`)
+	out := av.Visit(scanner, in, "test").(*Attribute)
+	assert.Equal(t, "name", out.Name)
+	assert.Equal(t, 1, out.Ordinal)
+	assert.Equal(t, Comment("Name"), out.Comment)
+	assert.Len(t, out.Annotations, 1)
+	assert.Equal(t, "(my.rules)", out.Annotations[0].Name)
+	assert.Equal(t, "1", out.Annotations[0].Constant.Get("min_len").Value)
+	assert.True(t, scanner.Scan())
+	assert.Equal(t, "string next = 2;", scanner.Text())
+}
@@ -0,0 +1,439 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// The rules of the breaking change detection.
+const (
+	BreakingFileRemoved                 = "FILE_REMOVED"
+	BreakingPackageChanged              = "PACKAGE_CHANGED"
+	BreakingMessageRemoved              = "MESSAGE_REMOVED"
+	BreakingFieldRemoved                = "FIELD_REMOVED"
+	BreakingFieldRemovedNotReserved     = "FIELD_REMOVED_NOT_RESERVED"
+	BreakingFieldNumberChanged          = "FIELD_NUMBER_CHANGED"
+	BreakingFieldNameChanged            = "FIELD_NAME_CHANGED"
+	BreakingFieldTypeChanged            = "FIELD_TYPE_CHANGED"
+	BreakingFieldLabelChanged           = "FIELD_LABEL_CHANGED"
+	BreakingEnumRemoved                 = "ENUM_REMOVED"
+	BreakingEnumValueRemoved            = "ENUM_VALUE_REMOVED"
+	BreakingEnumValueRemovedNotReserved = "ENUM_VALUE_REMOVED_NOT_RESERVED"
+	BreakingEnumValueNumberChanged      = "ENUM_VALUE_NUMBER_CHANGED"
+	BreakingEnumValueNameChanged        = "ENUM_VALUE_NAME_CHANGED"
+	BreakingServiceRemoved              = "SERVICE_REMOVED"
+	BreakingRpcRemoved                  = "RPC_REMOVED"
+	BreakingRpcStreamingChanged         = "RPC_STREAMING_CHANGED"
+	BreakingRpcRequestTypeChanged       = "RPC_REQUEST_TYPE_CHANGED"
+	BreakingRpcResponseTypeChanged      = "RPC_RESPONSE_TYPE_CHANGED"
+)
+
+// BreakingChange is a change of the new files breaking the code generated from,
+// or the data encoded with, the old files. Wire is set when data encoded with
+// one version cannot be read with the other, otherwise only the sources using
+// the generated code are broken.
+type BreakingChange struct {
+	Rule     string `json:"rule"`
+	Wire     bool   `json:"wire"`
+	Element  string `json:"element"`
+	Message  string `json:"message"`
+	Location string `json:"location"`
+}
+
+// BreakingReport is the list of breaking changes between two trees, in the
+// declaration order of the old files.
+type BreakingReport struct {
+	Changes []*BreakingChange `json:"changes"`
+}
+
+// HasChanges determines if any breaking change was found.
+func (br *BreakingReport) HasChanges() bool {
+	return len(br.Changes) > 0
+}
+
+// ToMarkdown formats the report as a markdown table.
+func (br *BreakingReport) ToMarkdown() string {
+	out := "# Breaking Changes\n\n"
+	if !br.HasChanges() {
+		return out + "No breaking changes.\n"
+	}
+	wire := 0
+	table := NewMarkdownTable()
+	table.AddHeader("Rule", "Element", "Change", "Wire", "Location")
+	for _, c := range br.Changes {
+		breaks := "no"
+		if c.Wire {
+			breaks = "yes"
+			wire++
+		}
+		table.Insert(c.Rule, c.Element, c.Message, breaks, c.Location)
+	}
+	out += fmt.Sprintf("%d breaking changes, %d of them wire breaking.\n\n", len(br.Changes), wire)
+	return out + table.String()
+}
+
+// ToJSON formats the report as indented JSON.
+func (br *BreakingReport) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(br, "", "  ")
+}
+
+// declarations are the messages, enums and services of a tree by their
+// fully-qualified names.
+type declarations struct {
+	messages map[string]*Message
+	enums    map[string]*Enum
+	services map[string]*Service
+}
+
+func newDeclarations(packages []*Package) *declarations {
+	out := &declarations{messages: make(map[string]*Message), enums: make(map[string]*Enum), services: make(map[string]*Service)}
+	var addMessage func(m *Message)
+	addMessage = func(m *Message) {
+		out.messages[QualifiedName(m.Qualifier)] = m
+		for _, nested := range m.Messages {
+			addMessage(nested)
+		}
+		for _, e := range m.Enums {
+			out.enums[QualifiedName(e.Qualifier)] = e
+		}
+	}
+	for _, p := range packages {
+		for _, m := range p.Messages {
+			addMessage(m)
+		}
+		for _, e := range p.Enums {
+			out.enums[QualifiedName(e.Qualifier)] = e
+		}
+		for _, s := range p.Services {
+			out.services[QualifiedName(Join(Period, s.Qualifier, s.Name))] = s
+		}
+	}
+	return out
+}
+
+// treeComparison pairs the declarations of an old and a new tree, the files
+// are paired by their paths in the trees, and the declarations by their names
+// once the package of their file is renamed.
+type treeComparison struct {
+	old      *Tree
+	new      *Tree
+	current  *declarations
+	files    map[string]*Package
+	packages map[*Package]string
+}
+
+func newTreeComparison(old *Tree, new *Tree) *treeComparison {
+	out := &treeComparison{old: old, new: new, current: newDeclarations(new.Packages), files: make(map[string]*Package), packages: make(map[*Package]string)}
+	for _, p := range new.Packages {
+		out.files[new.RelativePath(p)] = p
+	}
+	for _, p := range old.Packages {
+		if np, ok := out.files[old.RelativePath(p)]; ok && np.Name != p.Name {
+			out.packages[p] = np.Name
+		}
+	}
+	return out
+}
+
+// rename returns the name of an old declaration in the new tree, the package
+// of its file replaced by the new package of the file.
+func (tc *treeComparison) rename(p *Package, name string) string {
+	name = QualifiedName(name)
+	if renamed, ok := tc.packages[p]; ok && strings.HasPrefix(name, p.Name+Period) {
+		return Join(Period, renamed, strings.TrimPrefix(name, p.Name+Period))
+	}
+	return name
+}
+
+// typeName returns the fully-qualified name of a type in the new tree.
+func (tc *treeComparison) typeName(kind string, resolved *Symbol) string {
+	if resolved == nil {
+		return QualifiedName(kind)
+	}
+	if resolved.Package != nil {
+		return tc.rename(resolved.Package, resolved.Name)
+	}
+	return resolved.Name
+}
+
+// Breaking compares the files of the old and new trees, and reports the
+// changes breaking the wire format or the sources using the generated code:
+// removed files, types, fields, enum values and rpcs, renamed packages,
+// renumbered or renamed fields and enum values, field type and label changes,
+// rpc streaming and type changes, and fields or enum values deleted without
+// reserving their numbers and names.
+func Breaking(old *Tree, new *Tree) *BreakingReport {
+	b := &breakingChecker{treeComparison: newTreeComparison(old, new), out: &BreakingReport{Changes: make([]*BreakingChange, 0)}}
+	for _, p := range old.Packages {
+		path := old.RelativePath(p)
+		if np, ok := b.files[path]; !ok {
+			b.report(BreakingFileRemoved, false, p.Name, Location{File: p.Path, Start: Position{Line: 1, Column: 1}},
+				"file `%s` was removed", path)
+		} else if np.Name != p.Name {
+			b.report(BreakingPackageChanged, true, p.Name, np.Location,
+				"package of `%s` changed from `%s` to `%s`", path, p.Name, np.Name)
+		}
+		for _, m := range p.Messages {
+			b.message(p, m)
+		}
+		for _, e := range p.Enums {
+			b.enum(p, e)
+		}
+		for _, s := range p.Services {
+			b.service(p, s)
+		}
+	}
+	return b.out
+}
+
+// breakingChecker collects the breaking changes of a comparison.
+type breakingChecker struct {
+	*treeComparison
+	out *BreakingReport
+}
+
+func (b *breakingChecker) report(rule string, wire bool, element string, location Location, format string, args ...any) {
+	b.out.Changes = append(b.out.Changes, &BreakingChange{
+		Rule: rule, Wire: wire, Element: QualifiedName(element), Message: fmt.Sprintf(format, args...), Location: location.String(),
+	})
+}
+
+func (b *breakingChecker) message(p *Package, m *Message) {
+	nm, ok := b.current.messages[b.rename(p, m.Qualifier)]
+	if !ok {
+		b.report(BreakingMessageRemoved, false, m.Qualifier, m.Location, "message `%s` was removed", m.Name)
+		return
+	}
+	byName := make(map[string]*Attribute)
+	byNumber := make(map[int]*Attribute)
+	for _, a := range nm.Attributes {
+		byName[a.Name] = a
+		byNumber[a.Ordinal] = a
+	}
+	// The new fields matched by name are not renamed from another field
+	matched := make(map[*Attribute]bool)
+	for _, a := range m.Attributes {
+		if na, ok := byName[a.Name]; ok {
+			matched[na] = true
+		}
+	}
+	for _, a := range m.Attributes {
+		element := Join(Period, m.Qualifier, a.Name)
+		if na, ok := byName[a.Name]; ok {
+			if na.Ordinal != a.Ordinal {
+				b.report(BreakingFieldNumberChanged, true, element, na.Location,
+					"field `%s` changed number from %d to %d", a.Name, a.Ordinal, na.Ordinal)
+			}
+			b.field(element, a, na)
+		} else if na, ok := byNumber[a.Ordinal]; ok && !matched[na] {
+			b.report(BreakingFieldNameChanged, false, element, na.Location,
+				"field %d was renamed from `%s` to `%s`", a.Ordinal, a.Name, na.Name)
+			b.field(element, a, na)
+		} else if missing := unreserved(nm.Reserved, a.Ordinal, a.Name); len(missing) > 0 {
+			b.report(BreakingFieldRemovedNotReserved, false, element, a.Location,
+				"field `%s` (%d) was deleted without reserving its %s", a.Name, a.Ordinal, missing)
+		} else {
+			b.report(BreakingFieldRemoved, false, element, a.Location,
+				"field `%s` (%d) was deleted", a.Name, a.Ordinal)
+		}
+	}
+	for _, nested := range m.Messages {
+		b.message(p, nested)
+	}
+	for _, e := range m.Enums {
+		b.enum(p, e)
+	}
+}
+
+// unreserved describes what is not reserved of a deleted field or enum value:
+// `number`, `name`, `number and name`, or nothing.
+func unreserved(reserved []*Reserved, number int, name string) string {
+	numberReserved, nameReserved := false, false
+	for _, r := range reserved {
+		numberReserved = numberReserved || r.ContainsNumber(number)
+		nameReserved = nameReserved || r.ContainsName(name)
+	}
+	switch {
+	case !numberReserved && !nameReserved:
+		return "number and name"
+	case !numberReserved:
+		return "number"
+	case !nameReserved:
+		return "name"
+	}
+	return Empty
+}
+
+// cardinality returns map, repeated or singular.
+func cardinality(a *Attribute) string {
+	if a.Map {
+		return "map"
+	} else if a.Repeated {
+		return "repeated"
+	}
+	return "singular"
+}
+
+// wireClass groups the types that are wire compatible, as listed in the
+// protobuf language guide. Messages are only compatible with themselves.
+func wireClass(kind string, resolved *Symbol) string {
+	switch kind {
+	case "int32", "uint32", "int64", "uint64", "bool":
+		return "varint"
+	case "sint32", "sint64":
+		return "zigzag"
+	case "fixed32", "sfixed32":
+		return "fixed32"
+	case "fixed64", "sfixed64":
+		return "fixed64"
+	case "string", "bytes":
+		return "bytes"
+	case "float", "double":
+		return kind
+	}
+	if resolved != nil && resolved.Kind == SymbolEnum {
+		return "varint"
+	}
+	return Empty
+}
+
+// field compares the label and the types of a field of the old and new trees.
+func (b *breakingChecker) field(element string, a *Attribute, na *Attribute) {
+	if cardinality(a) != cardinality(na) {
+		b.report(BreakingFieldLabelChanged, true, element, na.Location,
+			"field `%s` changed from %s to %s", na.Name, cardinality(a), cardinality(na))
+		return
+	}
+	if a.Required != na.Required {
+		state := "is now required"
+		if a.Required {
+			state = "is no longer required"
+		}
+		b.report(BreakingFieldLabelChanged, true, element, na.Location, "field `%s` %s", na.Name, state)
+	}
+	for i := range a.Kind {
+		if i >= len(na.Kind) {
+			break
+		}
+		oldKind, newKind := strings.TrimSpace(a.Kind[i]), strings.TrimSpace(na.Kind[i])
+		oldType, newType := b.typeName(oldKind, a.ResolvedKind(i)), QualifiedName(newKind)
+		if s := na.ResolvedKind(i); s != nil {
+			newType = s.Name
+		}
+		if oldType == newType {
+			continue
+		}
+		oldClass, newClass := wireClass(oldKind, a.ResolvedKind(i)), wireClass(newKind, na.ResolvedKind(i))
+		if len(oldClass) > 0 && oldClass == newClass {
+			b.report(BreakingFieldTypeChanged, false, element, na.Location,
+				"field `%s` changed type from `%s` to `%s`, which is wire compatible", na.Name, oldKind, newKind)
+		} else {
+			b.report(BreakingFieldTypeChanged, true, element, na.Location,
+				"field `%s` changed type from `%s` to `%s`", na.Name, oldKind, newKind)
+		}
+	}
+}
+
+func (b *breakingChecker) enum(p *Package, e *Enum) {
+	ne, ok := b.current.enums[b.rename(p, e.Qualifier)]
+	if !ok {
+		b.report(BreakingEnumRemoved, false, e.Qualifier, e.Location, "enum `%s` was removed", e.Name)
+		return
+	}
+	byName := make(map[string]*EnumValue)
+	byNumber := make(map[int]*EnumValue)
+	for _, v := range ne.Values {
+		byName[v.Value] = v
+		if _, ok := byNumber[v.Ordinal]; !ok {
+			byNumber[v.Ordinal] = v
+		}
+	}
+	// The new values matched by name are not renamed from another value
+	matched := make(map[*EnumValue]bool)
+	for _, v := range e.Values {
+		if nv, ok := byName[v.Value]; ok {
+			matched[nv] = true
+		}
+	}
+	for _, v := range e.Values {
+		element := Join(Period, e.Qualifier, v.Value)
+		if nv, ok := byName[v.Value]; ok {
+			if nv.Ordinal != v.Ordinal {
+				b.report(BreakingEnumValueNumberChanged, true, element, nv.Location,
+					"enum value `%s` changed number from %d to %d", v.Value, v.Ordinal, nv.Ordinal)
+			}
+		} else if nv, ok := byNumber[v.Ordinal]; ok && !matched[nv] {
+			b.report(BreakingEnumValueNameChanged, false, element, nv.Location,
+				"enum value %d was renamed from `%s` to `%s`", v.Ordinal, v.Value, nv.Value)
+		} else if missing := unreserved(ne.Reserved, v.Ordinal, v.Value); len(missing) > 0 {
+			b.report(BreakingEnumValueRemovedNotReserved, false, element, v.Location,
+				"enum value `%s` (%d) was deleted without reserving its %s", v.Value, v.Ordinal, missing)
+		} else {
+			b.report(BreakingEnumValueRemoved, false, element, v.Location,
+				"enum value `%s` (%d) was deleted", v.Value, v.Ordinal)
+		}
+	}
+}
+
+func (b *breakingChecker) service(p *Package, s *Service) {
+	name := Join(Period, s.Qualifier, s.Name)
+	ns, ok := b.current.services[b.rename(p, name)]
+	if !ok {
+		b.report(BreakingServiceRemoved, true, name, s.Location, "service `%s` was removed", s.Name)
+		return
+	}
+	methods := make(map[string]*Rpc)
+	for _, rpc := range ns.Methods {
+		methods[rpc.Name] = rpc
+	}
+	for _, rpc := range s.Methods {
+		element := Join(Period, rpc.Qualifier, rpc.Name)
+		nrpc, ok := methods[rpc.Name]
+		if !ok {
+			b.report(BreakingRpcRemoved, true, element, rpc.Location, "rpc `%s` was removed", rpc.Name)
+			continue
+		}
+		b.parameters(element, nrpc, "request", rpc.InputParameters, nrpc.InputParameters, BreakingRpcRequestTypeChanged)
+		b.parameters(element, nrpc, "response", rpc.ReturnParameters, nrpc.ReturnParameters, BreakingRpcResponseTypeChanged)
+	}
+}
+
+// parameters compares the streaming mode and the type of the request or the
+// response of an rpc.
+func (b *breakingChecker) parameters(element string, rpc *Rpc, name string, old []*Parameter, new []*Parameter, typeRule string) {
+	if len(old) == 0 || len(new) == 0 {
+		return
+	}
+	if old[0].Stream != new[0].Stream {
+		state := "is now streamed"
+		if old[0].Stream {
+			state = "is no longer streamed"
+		}
+		b.report(BreakingRpcStreamingChanged, true, element, rpc.Location, "the %s of `%s` %s", name, rpc.Name, state)
+	}
+	newType := QualifiedName(new[0].Type)
+	if new[0].Resolved != nil {
+		newType = new[0].Resolved.Name
+	}
+	if b.typeName(old[0].Type, old[0].Resolved) != newType {
+		b.report(typeRule, true, element, rpc.Location,
+			"the %s of `%s` changed type from `%s` to `%s`", name, rpc.Name, old[0].Type, new[0].Type)
+	}
+}
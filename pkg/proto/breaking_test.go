@@ -0,0 +1,136 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readBreakingTrees(t *testing.T) (*Tree, *Tree) {
+	old, err := ReadTree("data/breaking/old")
+	assert.Nil(t, err)
+	assert.Empty(t, old.Diagnostics)
+	current, err := ReadTree("data/breaking/new")
+	assert.Nil(t, err)
+	assert.Empty(t, current.Diagnostics)
+	return old, current
+}
+
+func TestBreaking(t *testing.T) {
+	old, current := readBreakingTrees(t)
+	report := Breaking(old, current)
+	got := make([]string, 0)
+	for _, c := range report.Changes {
+		got = append(got, c.Rule+" "+c.Element)
+	}
+	// The type of Book.shelf is renamed with its package, it is not changed
+	assert.Equal(t, []string{
+		"FIELD_NUMBER_CHANGED test.library.Book.title",
+		"FIELD_TYPE_CHANGED test.library.Book.pages",
+		"FIELD_LABEL_CHANGED test.library.Book.authors",
+		"FIELD_REMOVED test.library.Book.isbn",
+		"FIELD_NAME_CHANGED test.library.Book.summary",
+		"FIELD_REMOVED_NOT_RESERVED test.library.Book.year",
+		"ENUM_VALUE_REMOVED test.library.Book.Status.STATUS_LOST",
+		"ENUM_VALUE_REMOVED_NOT_RESERVED test.library.Book.Status.STATUS_RETIRED",
+		"ENUM_VALUE_NUMBER_CHANGED test.library.Book.Status.STATUS_ORDERED",
+		"ENUM_VALUE_NAME_CHANGED test.library.Book.Status.STATUS_MISSING",
+		"MESSAGE_REMOVED test.library.Author",
+		// The number taken by a field or value matched by name is not a rename
+		"FIELD_NUMBER_CHANGED test.library.Review.text",
+		"FIELD_REMOVED_NOT_RESERVED test.library.Review.title",
+		"ENUM_VALUE_NUMBER_CHANGED test.library.Review.Rating.RATING_GOOD",
+		"ENUM_VALUE_REMOVED_NOT_RESERVED test.library.Review.Rating.RATING_BAD",
+		"ENUM_REMOVED test.library.Genre",
+		"RPC_REQUEST_TYPE_CHANGED test.library.Library.GetBook",
+		"RPC_STREAMING_CHANGED test.library.Library.ListBooks",
+		"RPC_RESPONSE_TYPE_CHANGED test.library.Library.UpdateBook",
+		"RPC_REMOVED test.library.Library.DeleteBook",
+		"SERVICE_REMOVED test.library.Archive",
+		"FILE_REMOVED test.library",
+		"MESSAGE_REMOVED test.library.Loan",
+		"PACKAGE_CHANGED test.shelf",
+	}, got)
+
+	assert.Equal(t, &BreakingChange{
+		Rule: BreakingFieldTypeChanged, Element: "test.library.Book.pages",
+		Message:  "field `pages` changed type from `int32` to `int64`, which is wire compatible",
+		Location: "data/breaking/new/library/library.proto:26:3",
+	}, report.Changes[1])
+	assert.Equal(t, &BreakingChange{
+		Rule: BreakingFieldRemovedNotReserved, Element: "test.library.Book.year",
+		Message:  "field `year` (8) was deleted without reserving its number and name",
+		Location: "data/breaking/old/library/library.proto:28:3",
+	}, report.Changes[5])
+	// Deleting a field or an enum value without reserving it only breaks the sources
+	assert.Equal(t, &BreakingChange{
+		Rule: BreakingFieldRemovedNotReserved, Element: "test.library.Review.title",
+		Message:  "field `title` (2) was deleted without reserving its number and name",
+		Location: "data/breaking/old/library/library.proto:62:3",
+	}, report.Changes[12])
+	assert.Equal(t, &BreakingChange{
+		Rule: BreakingEnumValueRemovedNotReserved, Element: "test.library.Review.Rating.RATING_BAD",
+		Message:  "enum value `RATING_BAD` (2) was deleted without reserving its number and name",
+		Location: "data/breaking/old/library/library.proto:67:5",
+	}, report.Changes[14])
+	assert.False(t, report.Changes[7].Wire)
+	assert.Equal(t, "the response of `ListBooks` is no longer streamed", report.Changes[17].Message)
+	assert.Equal(t, "package of `shelf/shelf.proto` changed from `test.shelf` to `test.shelves`", report.Changes[23].Message)
+
+	assert.Empty(t, Breaking(current, current).Changes)
+}
+
+func TestBreakingReport(t *testing.T) {
+	report := &BreakingReport{Changes: []*BreakingChange{
+		{Rule: BreakingRpcRemoved, Wire: true, Element: "a.S.Get", Message: "rpc `Get` was removed", Location: "a.proto:4:3"},
+		{Rule: BreakingMessageRemoved, Element: "a.A", Message: "message `A` was removed", Location: "a.proto:2:1"},
+	}}
+	assert.True(t, report.HasChanges())
+	assert.Equal(t, `# Breaking Changes
+
+2 breaking changes, 1 of them wire breaking.
+
+| Rule            | Element | Change                  | Wire | Location    |
+|-----------------|---------|-------------------------|------|-------------|
+| RPC_REMOVED     | a.S.Get | rpc `+"`Get`"+` was removed   | yes  | a.proto:4:3 |
+| MESSAGE_REMOVED | a.A     | message `+"`A`"+` was removed | no   | a.proto:2:1 |
+`, report.ToMarkdown())
+
+	data, err := report.ToJSON()
+	assert.Nil(t, err)
+	read := &BreakingReport{}
+	assert.Nil(t, json.Unmarshal(data, read))
+	assert.Equal(t, report, read)
+
+	empty := &BreakingReport{Changes: make([]*BreakingChange, 0)}
+	assert.False(t, empty.HasChanges())
+	assert.Equal(t, "# Breaking Changes\n\nNo breaking changes.\n", empty.ToMarkdown())
+}
+
+func TestWireClass(t *testing.T) {
+	enum := &Symbol{Name: "a.E", Kind: SymbolEnum}
+	message := &Symbol{Name: "a.M", Kind: SymbolMessage}
+	assert.Equal(t, wireClass("int32", nil), wireClass("E", enum))
+	assert.Equal(t, wireClass("uint64", nil), wireClass("bool", nil))
+	assert.NotEqual(t, wireClass("int32", nil), wireClass("sint32", nil))
+	assert.Equal(t, wireClass("string", nil), wireClass("bytes", nil))
+	assert.NotEqual(t, wireClass("fixed32", nil), wireClass("float", nil))
+	assert.Empty(t, wireClass("M", message))
+}
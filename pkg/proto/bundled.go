@@ -0,0 +1,105 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+)
+
+// The well-known types and the common google/api and google/rpc protos are
+// bundled so their imports resolve without include roots.
+//
+//go:embed include/google/*/*.proto
+var bundled embed.FS
+
+// BundledFiles returns the bundled protos by their import paths, e.g.
+// google/protobuf/timestamp.proto.
+func BundledFiles() fs.FS {
+	out, err := fs.Sub(bundled, "include")
+	if err != nil {
+		panic(err)
+	}
+	return out
+}
+
+// MarkBundled marks the packages read from other sources, such as a descriptor
+// set, whose files are bundled.
+func MarkBundled(packages ...*Package) {
+	files := BundledFiles()
+	for _, p := range packages {
+		if _, err := fs.Stat(files, p.Path); err == nil {
+			p.Bundled = true
+		}
+	}
+}
+
+// ExternalStyle selects how the writers render the types of the bundled
+// packages.
+type ExternalStyle int
+
+const (
+	// ExternalNodes renders the relationships to bundled types with an
+	// external class for each type.
+	ExternalNodes ExternalStyle = iota
+	// ExternalHidden omits the relationships to bundled types.
+	ExternalHidden
+	// ExternalAliased omits the relationships to bundled types and displays
+	// their ExternalAliases in the tables.
+	ExternalAliased
+)
+
+// ExternalStyleNames are the names of the external styles, used by the
+// -external flag.
+var ExternalStyleNames = map[string]ExternalStyle{
+	"node":  ExternalNodes,
+	"hide":  ExternalHidden,
+	"alias": ExternalAliased,
+}
+
+// ParseExternalStyle reads an external style from its name.
+func ParseExternalStyle(in string) (ExternalStyle, error) {
+	if style, ok := ExternalStyleNames[in]; ok {
+		return style, nil
+	}
+	return ExternalNodes, fmt.Errorf("unknown external style %q, expected node, hide or alias", in)
+}
+
+// ExternalAliases are the friendly names of the bundled types displayed with
+// the alias style, by fully-qualified name.
+var ExternalAliases = map[string]string{
+	"google.protobuf.Any":         "any message",
+	"google.protobuf.BoolValue":   "nullable bool",
+	"google.protobuf.BytesValue":  "nullable bytes",
+	"google.protobuf.DoubleValue": "nullable double",
+	"google.protobuf.Duration":    "duration",
+	"google.protobuf.Empty":       "empty",
+	"google.protobuf.FieldMask":   "field mask",
+	"google.protobuf.FloatValue":  "nullable float",
+	"google.protobuf.Int32Value":  "nullable int32",
+	"google.protobuf.Int64Value":  "nullable int64",
+	"google.protobuf.ListValue":   "JSON array",
+	"google.protobuf.NullValue":   "JSON null",
+	"google.protobuf.StringValue": "nullable string",
+	"google.protobuf.Struct":      "JSON object",
+	"google.protobuf.Timestamp":   "timestamp (RFC 3339)",
+	"google.protobuf.UInt32Value": "nullable uint32",
+	"google.protobuf.UInt64Value": "nullable uint64",
+	"google.protobuf.Value":       "JSON value",
+	"google.rpc.Status":           "error status",
+}
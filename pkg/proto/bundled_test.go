@@ -0,0 +1,98 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBundledFiles(t *testing.T) {
+	for _, path := range []string{"google/protobuf/timestamp.proto", "google/api/http.proto", "google/rpc/status.proto"} {
+		p := NewPackage(path)
+		file, err := BundledFiles().Open(path)
+		assert.Nil(t, err)
+		diagnostics, err := p.ReadSource(file)
+		assert.Nil(t, err)
+		assert.Empty(t, diagnostics)
+		assert.NotEmpty(t, p.Messages)
+		file.Close()
+	}
+}
+
+func TestMarkBundled(t *testing.T) {
+	timestamp, library := NewPackage("google/protobuf/timestamp.proto"), NewPackage("library.proto")
+	MarkBundled(timestamp, library)
+	assert.True(t, timestamp.Bundled)
+	assert.False(t, library.Bundled)
+}
+
+func TestParseExternalStyle(t *testing.T) {
+	for name, want := range ExternalStyleNames {
+		got, err := ParseExternalStyle(name)
+		assert.Nil(t, err)
+		assert.Equal(t, want, got)
+	}
+	_, err := ParseExternalStyle("none")
+	assert.EqualError(t, err, `unknown external style "none", expected node, hide or alias`)
+}
+
+func TestExternalAliases(t *testing.T) {
+	// Every alias names a bundled type
+	packages := make([]*Package, 0)
+	for _, path := range []string{"google/protobuf/any.proto", "google/protobuf/duration.proto", "google/protobuf/empty.proto",
+		"google/protobuf/field_mask.proto", "google/protobuf/struct.proto", "google/protobuf/timestamp.proto",
+		"google/protobuf/wrappers.proto", "google/rpc/status.proto"} {
+		file, err := BundledFiles().Open(path)
+		assert.Nil(t, err)
+		p, err := ParseReader(path, file)
+		assert.Nil(t, err)
+		packages = append(packages, p)
+		file.Close()
+	}
+	st := NewSymbolTable(packages...)
+	for name := range ExternalAliases {
+		assert.True(t, st.Lookup(name).IsType(), name)
+	}
+}
+
+func TestBundled_GoogleAPIOptions(t *testing.T) {
+	// The custom options of the common googleapis protos resolve without include roots
+	options := NewPackage("data/test/options/options.proto")
+	_, err := options.Read(false)
+	assert.Nil(t, err)
+	imported, diagnostics := NewImporter().Load(options)
+	assert.Equal(t, "data/test/options/options.proto:19:1: error: import `validate/validate.proto` not found in the include roots (test.options)",
+		diagnostics.String())
+	client := options.Imports[1].Package
+	assert.Equal(t, "google/api/client.proto", options.Imports[1].Path)
+	assert.NotNil(t, client)
+	assert.True(t, client.Bundled)
+	assert.Empty(t, Link(imported...))
+
+	assert.Equal(t, "google.protobuf.ServiceOptions", client.Extends[0].Resolved.Name)
+	assert.Equal(t, "default_host", client.Extends[0].Attributes[0].Name)
+	for _, path := range []string{"google/api/field_behavior.proto", "google/api/resource.proto"} {
+		file, err := BundledFiles().Open(path)
+		assert.Nil(t, err)
+		p, err := ParseReader(path, file)
+		assert.Nil(t, err)
+		assert.NotEmpty(t, p.Extends, path)
+		file.Close()
+	}
+}
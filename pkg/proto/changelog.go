@@ -0,0 +1,638 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The kinds of the changes of a Changelog.
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeRenamed = "renamed"
+	ChangeChanged = "changed"
+)
+
+// The elements of the changes of a Changelog.
+const (
+	ChangeFile      = "file"
+	ChangePackage   = "package"
+	ChangeMessage   = "message"
+	ChangeField     = "field"
+	ChangeEnum      = "enum"
+	ChangeEnumValue = "enum value"
+	ChangeService   = "service"
+	ChangeRpc       = "rpc"
+)
+
+const mermaidSnippetTemplate = "```mermaid\nclassDiagram\ndirection LR\n%s\n```\n"
+
+// Change is a difference between the old and new versions of an element. Name
+// is the name of the element in the new version, or in the old version when
+// it is removed. Before and After are the values that changed, e.g. the old and
+// new names of a renamed element, or the old and new comments.
+type Change struct {
+	Kind        string `json:"kind"`
+	Element     string `json:"element"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Before      string `json:"before,omitempty"`
+	After       string `json:"after,omitempty"`
+}
+
+// EntityChanges are the changes of a message, an enum or a service, and of its
+// fields, values or rpcs. The Mermaid diagrams of a changed message before and
+// after the changes are included.
+type EntityChanges struct {
+	Element string    `json:"element"`
+	Name    string    `json:"name"`
+	Changes []*Change `json:"changes"`
+	Before  string    `json:"before,omitempty"`
+	After   string    `json:"after,omitempty"`
+}
+
+// PackageChanges are the changes of the files of a package, and of their
+// entities in declaration order.
+type PackageChanges struct {
+	Package  string           `json:"package"`
+	Changes  []*Change        `json:"changes"`
+	Entities []*EntityChanges `json:"entities"`
+}
+
+// Changelog is the set of changes between two trees, grouped by package, the
+// packages sorted by name.
+type Changelog struct {
+	Packages []*PackageChanges `json:"packages"`
+}
+
+// HasChanges determines if any change was found.
+func (cl *Changelog) HasChanges() bool {
+	return len(cl.Packages) > 0
+}
+
+// Diff compares the files of the old and new trees, and returns the added,
+// removed and renamed files, packages, messages, fields, enums, enum values,
+// services and rpcs, and the elements whose comments, options, types or
+// numbers changed. Messages, enums and rpcs are considered renamed when an
+// element is removed and an element with the same content is added in the same
+// scope, fields and enum values when their number is kept.
+func Diff(old *Tree, new *Tree) *Changelog {
+	d := &differ{
+		treeComparison: newTreeComparison(old, new),
+		matched:        make(map[any]bool),
+		changes:        make(map[string]*PackageChanges),
+		config:         &WriterConfig{},
+	}
+	files := make(map[string]bool)
+	for _, p := range old.Packages {
+		path := old.RelativePath(p)
+		files[path] = true
+		if np, ok := d.files[path]; !ok {
+			d.packageChange(p.Name, &Change{Kind: ChangeRemoved, Element: ChangeFile, Name: path, Description: "file removed"})
+		} else if np.Name != p.Name {
+			d.packageChange(np.Name, &Change{Kind: ChangeRenamed, Element: ChangePackage, Name: np.Name,
+				Description: fmt.Sprintf("package of `%s` renamed", path), Before: p.Name, After: np.Name})
+		}
+	}
+	for _, p := range new.Packages {
+		if path := new.RelativePath(p); !files[path] {
+			d.packageChange(p.Name, &Change{Kind: ChangeAdded, Element: ChangeFile, Name: path, Description: "file added"})
+		}
+	}
+
+	// The elements of the old tree are paired first, the elements left in
+	// either tree are then paired by their content, or reported
+	removed := make([]*diffEntity, 0)
+	for _, p := range old.Packages {
+		removed = append(removed, d.declarations(p, p.Messages, p.Enums, p.Services, true)...)
+	}
+	added := make([]*diffEntity, 0)
+	for _, p := range new.Packages {
+		added = append(added, d.declarations(p, p.Messages, p.Enums, p.Services, false)...)
+	}
+	for _, r := range removed {
+		if a := d.renamed(r, added); a != nil {
+			d.matched[a.value] = true
+			d.compare(r, a)
+		} else {
+			e := d.entity(r.pkg, r.element, r.name)
+			e.Changes = append(e.Changes, &Change{Kind: ChangeRemoved, Element: r.element, Name: r.name, Description: r.element + " removed"})
+		}
+	}
+	for _, a := range added {
+		if !d.matched[a.value] {
+			e := d.entity(a.pkg, a.element, a.name)
+			e.Changes = append(e.Changes, &Change{Kind: ChangeAdded, Element: a.element, Name: a.name, Description: a.element + " added"})
+		}
+	}
+
+	out := &Changelog{Packages: make([]*PackageChanges, 0)}
+	for _, pc := range d.order {
+		entities := make([]*EntityChanges, 0, len(pc.Entities))
+		for _, e := range pc.Entities {
+			if len(e.Changes) > 0 {
+				entities = append(entities, e)
+			}
+		}
+		pc.Entities = entities
+		if len(pc.Changes) > 0 || len(pc.Entities) > 0 {
+			out.Packages = append(out.Packages, pc)
+		}
+	}
+	sort.SliceStable(out.Packages, func(i, j int) bool {
+		return out.Packages[i].Package < out.Packages[j].Package
+	})
+	return out
+}
+
+// diffEntity is a message, an enum or a service of a tree.
+type diffEntity struct {
+	pkg     string
+	element string
+	name    string
+	value   any
+}
+
+// differ collects the changes of a comparison.
+type differ struct {
+	*treeComparison
+	matched map[any]bool
+	changes map[string]*PackageChanges
+	order   []*PackageChanges
+	config  *WriterConfig
+}
+
+func (d *differ) packageChanges(name string) *PackageChanges {
+	pc, ok := d.changes[name]
+	if !ok {
+		pc = &PackageChanges{Package: name, Changes: make([]*Change, 0), Entities: make([]*EntityChanges, 0)}
+		d.changes[name] = pc
+		d.order = append(d.order, pc)
+	}
+	return pc
+}
+
+func (d *differ) packageChange(name string, c *Change) {
+	pc := d.packageChanges(name)
+	pc.Changes = append(pc.Changes, c)
+}
+
+// entity returns the changes of an entity of the package, in the order the
+// entities are first changed.
+func (d *differ) entity(pkg string, element string, name string) *EntityChanges {
+	pc := d.packageChanges(pkg)
+	for _, e := range pc.Entities {
+		if e.Element == element && e.Name == name {
+			return e
+		}
+	}
+	e := &EntityChanges{Element: element, Name: name, Changes: make([]*Change, 0)}
+	pc.Entities = append(pc.Entities, e)
+	return e
+}
+
+// declarations compares the entities of the old tree found in the new tree, and
+// returns the entities that are not found. With old unset, the entities of the
+// new tree not yet matched are returned.
+func (d *differ) declarations(p *Package, messages []*Message, enums []*Enum, services []*Service, old bool) []*diffEntity {
+	out := make([]*diffEntity, 0)
+	for _, m := range messages {
+		entity := &diffEntity{pkg: p.Name, element: ChangeMessage, name: QualifiedName(m.Qualifier), value: m}
+		if !old {
+			if !d.matched[m] {
+				out = append(out, entity)
+				continue
+			}
+		} else if nm, ok := d.current.messages[d.rename(p, m.Qualifier)]; ok {
+			d.matched[nm] = true
+			d.compare(entity, &diffEntity{pkg: d.packageName(p), element: ChangeMessage, name: QualifiedName(nm.Qualifier), value: nm})
+		} else {
+			out = append(out, entity)
+			continue
+		}
+		out = append(out, d.declarations(p, m.Messages, m.Enums, nil, old)...)
+	}
+	for _, e := range enums {
+		entity := &diffEntity{pkg: p.Name, element: ChangeEnum, name: QualifiedName(e.Qualifier), value: e}
+		if !old {
+			if !d.matched[e] {
+				out = append(out, entity)
+			}
+		} else if ne, ok := d.current.enums[d.rename(p, e.Qualifier)]; ok {
+			d.matched[ne] = true
+			d.compare(entity, &diffEntity{pkg: d.packageName(p), element: ChangeEnum, name: QualifiedName(ne.Qualifier), value: ne})
+		} else {
+			out = append(out, entity)
+		}
+	}
+	for _, s := range services {
+		name := Join(Period, s.Qualifier, s.Name)
+		entity := &diffEntity{pkg: p.Name, element: ChangeService, name: QualifiedName(name), value: s}
+		if !old {
+			if !d.matched[s] {
+				out = append(out, entity)
+			}
+		} else if ns, ok := d.current.services[d.rename(p, name)]; ok {
+			d.matched[ns] = true
+			d.compare(entity, &diffEntity{pkg: d.packageName(p), element: ChangeService, name: QualifiedName(Join(Period, ns.Qualifier, ns.Name)), value: ns})
+		} else {
+			out = append(out, entity)
+		}
+	}
+	return out
+}
+
+// packageName returns the name of the package of an old file in the new tree.
+func (d *differ) packageName(p *Package) string {
+	if renamed, ok := d.packages[p]; ok {
+		return renamed
+	}
+	return p.Name
+}
+
+// renamed returns the added entity of the same kind, scope and content as a
+// removed entity, if any.
+func (d *differ) renamed(r *diffEntity, added []*diffEntity) *diffEntity {
+	scope := func(name string) string {
+		return name[:max(strings.LastIndex(name, Period), 0)]
+	}
+	signature := d.signature(r.value)
+	for _, a := range added {
+		if d.matched[a.value] || a.element != r.element || scope(a.name) != scope(d.renameName(r.name)) {
+			continue
+		}
+		if len(signature) > 0 && d.signature(a.value) == signature {
+			return a
+		}
+	}
+	return nil
+}
+
+// renameName returns a fully-qualified name of the old tree with its package
+// renamed, as the package of its file is.
+func (d *differ) renameName(name string) string {
+	for p := range d.packages {
+		if strings.HasPrefix(name, p.Name+Period) {
+			return d.rename(p, name)
+		}
+	}
+	return name
+}
+
+// signature describes the content of a message, an enum or a service, empty
+// entities have no signature and are never considered renamed.
+func (d *differ) signature(value any) string {
+	parts := make([]string, 0)
+	switch v := value.(type) {
+	case *Message:
+		for _, a := range v.Attributes {
+			parts = append(parts, fmt.Sprintf("%s %s %d", strings.Join(a.Kind, Comma), a.Name, a.Ordinal))
+		}
+	case *Enum:
+		// The value names are compared without the prefix of the enum name,
+		// which changes with the enum, e.g. STATUS_OK and STATE_OK
+		prefix := UpperSnakeCase(v.Name) + "_"
+		for _, ev := range v.Values {
+			parts = append(parts, fmt.Sprintf("%s %d", strings.TrimPrefix(ev.Value, prefix), ev.Ordinal))
+		}
+	case *Service:
+		for _, rpc := range v.Methods {
+			parts = append(parts, rpcSignature(rpc))
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+// rpcSignature describes the request and response of an rpc.
+func rpcSignature(rpc *Rpc) string {
+	return FormatParametersForMermaid(rpc.InputParameters) + " " + FormatParametersForMermaid(rpc.ReturnParameters)
+}
+
+// compare adds the changes between the old and new versions of an entity.
+func (d *differ) compare(old *diffEntity, new *diffEntity) {
+	e := d.entity(new.pkg, new.element, new.name)
+	if old.name != new.name && d.renameName(old.name) != new.name {
+		e.Changes = append(e.Changes, &Change{Kind: ChangeRenamed, Element: new.element, Name: new.name,
+			Description: new.element + " renamed", Before: old.name, After: new.name})
+	}
+	switch o := old.value.(type) {
+	case *Message:
+		n := new.value.(*Message)
+		d.comment(e, ChangeMessage, new.name, o.Comment, n.Comment)
+		d.options(e, ChangeMessage, new.name, optionsText(o.Options), optionsText(n.Options))
+		d.fields(e, new.name, o.Attributes, n.Attributes)
+		if len(e.Changes) > 0 {
+			e.Before = fmt.Sprintf(mermaidSnippetTemplate, MessageToMermaid(o, d.config))
+			e.After = fmt.Sprintf(mermaidSnippetTemplate, MessageToMermaid(n, d.config))
+		}
+	case *Enum:
+		n := new.value.(*Enum)
+		d.comment(e, ChangeEnum, new.name, o.Comment, n.Comment)
+		d.options(e, ChangeEnum, new.name, optionsText(o.Options), optionsText(n.Options))
+		d.values(e, new.name, o.Values, n.Values)
+	case *Service:
+		n := new.value.(*Service)
+		d.comment(e, ChangeService, new.name, o.Comment, n.Comment)
+		d.options(e, ChangeService, new.name, optionsText(o.Options), optionsText(n.Options))
+		d.rpcs(e, new.name, o.Methods, n.Methods)
+	}
+}
+
+func (d *differ) comment(e *EntityChanges, element string, name string, old Comment, new Comment) {
+	if old.TrimSpace() != new.TrimSpace() {
+		e.Changes = append(e.Changes, &Change{Kind: ChangeChanged, Element: element, Name: name,
+			Description: "comment changed", Before: string(old.TrimSpace()), After: string(new.TrimSpace())})
+	}
+}
+
+func (d *differ) options(e *EntityChanges, element string, name string, old string, new string) {
+	if old != new {
+		e.Changes = append(e.Changes, &Change{Kind: ChangeChanged, Element: element, Name: name,
+			Description: "options changed", Before: old, After: new})
+	}
+}
+
+func (d *differ) changed(e *EntityChanges, element string, name string, description string, old string, new string) {
+	if old != new {
+		e.Changes = append(e.Changes, &Change{Kind: ChangeChanged, Element: element, Name: name,
+			Description: description, Before: old, After: new})
+	}
+}
+
+// optionsText formats options as they are declared, e.g. `deprecated = true`.
+func optionsText(options []*Option) string {
+	out := make([]string, 0, len(options))
+	for _, o := range options {
+		out = append(out, o.Name+" = "+o.Value)
+	}
+	return strings.Join(out, Comma+Space)
+}
+
+// annotationsText formats the annotations of a field as they are declared.
+func annotationsText(annotations []*Annotation) string {
+	out := make([]string, 0, len(annotations))
+	for _, a := range annotations {
+		out = append(out, fmt.Sprintf("%s = %v", a.Name, a.Value))
+	}
+	return strings.Join(out, Comma+Space)
+}
+
+// fieldType returns the fully-qualified types of a field, or of the key and
+// value of a map, the types of the old tree named as in the new tree.
+func (d *differ) fieldType(a *Attribute, old bool) string {
+	out := make([]string, 0, len(a.Kind))
+	for i, kind := range a.Kind {
+		if s := a.ResolvedKind(i); !old && s != nil {
+			out = append(out, s.Name)
+		} else {
+			out = append(out, d.typeName(strings.TrimSpace(kind), s))
+		}
+	}
+	return strings.Join(out, Comma)
+}
+
+// parametersType returns the fully-qualified types of the parameters of an
+// rpc, the types of the old tree named as in the new tree.
+func (d *differ) parametersType(parameters []*Parameter, old bool) string {
+	out := make([]string, 0, len(parameters))
+	for _, p := range parameters {
+		name := d.typeName(p.Type, p.Resolved)
+		if !old && p.Resolved != nil {
+			name = p.Resolved.Name
+		}
+		out = append(out, fmt.Sprintf("%t %s", p.Stream, name))
+	}
+	return strings.Join(out, Comma)
+}
+
+// label describes the cardinality and presence of a field.
+func label(a *Attribute) string {
+	switch {
+	case a.Required:
+		return PrefixRequired
+	case a.Optional:
+		return PrefixOptional
+	}
+	return cardinality(a)
+}
+
+func (d *differ) fields(e *EntityChanges, message string, old []*Attribute, new []*Attribute) {
+	byName := make(map[string]*Attribute)
+	byNumber := make(map[int]*Attribute)
+	for _, a := range new {
+		byName[a.Name] = a
+		byNumber[a.Ordinal] = a
+	}
+	// All the fields are paired by name before the remaining ones are paired
+	// by number
+	pairs := make([]*Attribute, len(old))
+	matched := make(map[*Attribute]bool)
+	for i, a := range old {
+		if na, ok := byName[a.Name]; ok {
+			pairs[i], matched[na] = na, true
+		}
+	}
+	for i, a := range old {
+		if na, ok := byNumber[a.Ordinal]; ok && pairs[i] == nil && !matched[na] {
+			pairs[i], matched[na] = na, true
+		}
+	}
+	for i, a := range old {
+		na := pairs[i]
+		if na == nil {
+			e.Changes = append(e.Changes, &Change{Kind: ChangeRemoved, Element: ChangeField, Name: Join(Period, message, a.Name),
+				Description: fmt.Sprintf("field %d removed", a.Ordinal)})
+			continue
+		}
+		name := Join(Period, message, na.Name)
+		if na.Name != a.Name {
+			e.Changes = append(e.Changes, &Change{Kind: ChangeRenamed, Element: ChangeField, Name: name,
+				Description: fmt.Sprintf("field %d renamed", a.Ordinal), Before: a.Name, After: na.Name})
+		}
+		d.changed(e, ChangeField, name, "number changed", strconv.Itoa(a.Ordinal), strconv.Itoa(na.Ordinal))
+		if d.fieldType(a, true) != d.fieldType(na, false) {
+			d.changed(e, ChangeField, name, "type changed", strings.Join(a.Kind, Comma), strings.Join(na.Kind, Comma))
+		}
+		d.changed(e, ChangeField, name, "label changed", label(a), label(na))
+		d.comment(e, ChangeField, name, a.Comment, na.Comment)
+		d.options(e, ChangeField, name, annotationsText(a.Annotations), annotationsText(na.Annotations))
+	}
+	for _, a := range new {
+		if !matched[a] {
+			e.Changes = append(e.Changes, &Change{Kind: ChangeAdded, Element: ChangeField, Name: Join(Period, message, a.Name),
+				Description: fmt.Sprintf("field %d added", a.Ordinal)})
+		}
+	}
+}
+
+func (d *differ) values(e *EntityChanges, enum string, old []*EnumValue, new []*EnumValue) {
+	byName := make(map[string]*EnumValue)
+	byNumber := make(map[int]*EnumValue)
+	for _, v := range new {
+		byName[v.Value] = v
+		if _, ok := byNumber[v.Ordinal]; !ok {
+			byNumber[v.Ordinal] = v
+		}
+	}
+	// All the values are paired by name before the remaining ones are paired
+	// by number
+	pairs := make([]*EnumValue, len(old))
+	matched := make(map[*EnumValue]bool)
+	for i, v := range old {
+		if nv, ok := byName[v.Value]; ok {
+			pairs[i], matched[nv] = nv, true
+		}
+	}
+	for i, v := range old {
+		if nv, ok := byNumber[v.Ordinal]; ok && pairs[i] == nil && !matched[nv] {
+			pairs[i], matched[nv] = nv, true
+		}
+	}
+	for i, v := range old {
+		nv := pairs[i]
+		if nv == nil {
+			e.Changes = append(e.Changes, &Change{Kind: ChangeRemoved, Element: ChangeEnumValue, Name: Join(Period, enum, v.Value),
+				Description: fmt.Sprintf("enum value %d removed", v.Ordinal)})
+			continue
+		}
+		name := Join(Period, enum, nv.Value)
+		if nv.Value != v.Value {
+			e.Changes = append(e.Changes, &Change{Kind: ChangeRenamed, Element: ChangeEnumValue, Name: name,
+				Description: fmt.Sprintf("enum value %d renamed", v.Ordinal), Before: v.Value, After: nv.Value})
+		}
+		d.changed(e, ChangeEnumValue, name, "number changed", strconv.Itoa(v.Ordinal), strconv.Itoa(nv.Ordinal))
+		d.comment(e, ChangeEnumValue, name, v.Comment, nv.Comment)
+		d.options(e, ChangeEnumValue, name, optionsText(v.Options), optionsText(nv.Options))
+	}
+	for _, v := range new {
+		if !matched[v] {
+			e.Changes = append(e.Changes, &Change{Kind: ChangeAdded, Element: ChangeEnumValue, Name: Join(Period, enum, v.Value),
+				Description: fmt.Sprintf("enum value %d added", v.Ordinal)})
+		}
+	}
+}
+
+func (d *differ) rpcs(e *EntityChanges, service string, old []*Rpc, new []*Rpc) {
+	byName := make(map[string]*Rpc)
+	for _, rpc := range new {
+		byName[rpc.Name] = rpc
+	}
+	matched := make(map[*Rpc]bool)
+	removed := make([]*Rpc, 0)
+	for _, rpc := range old {
+		if nrpc, ok := byName[rpc.Name]; ok {
+			matched[nrpc] = true
+			d.rpc(e, service, rpc, nrpc)
+		} else {
+			removed = append(removed, rpc)
+		}
+	}
+	for _, rpc := range removed {
+		renamed := false
+		for _, nrpc := range new {
+			if !matched[nrpc] && rpcSignature(nrpc) == rpcSignature(rpc) {
+				matched[nrpc] = true
+				renamed = true
+				e.Changes = append(e.Changes, &Change{Kind: ChangeRenamed, Element: ChangeRpc, Name: Join(Period, service, nrpc.Name),
+					Description: "rpc renamed", Before: rpc.Name, After: nrpc.Name})
+				d.rpc(e, service, rpc, nrpc)
+				break
+			}
+		}
+		if !renamed {
+			e.Changes = append(e.Changes, &Change{Kind: ChangeRemoved, Element: ChangeRpc, Name: Join(Period, service, rpc.Name),
+				Description: "rpc removed"})
+		}
+	}
+	for _, rpc := range new {
+		if !matched[rpc] {
+			e.Changes = append(e.Changes, &Change{Kind: ChangeAdded, Element: ChangeRpc, Name: Join(Period, service, rpc.Name),
+				Description: "rpc added"})
+		}
+	}
+}
+
+func (d *differ) rpc(e *EntityChanges, service string, old *Rpc, new *Rpc) {
+	name := Join(Period, service, new.Name)
+	if d.parametersType(old.InputParameters, true) != d.parametersType(new.InputParameters, false) {
+		d.changed(e, ChangeRpc, name, "request changed", FormatParametersForMermaid(old.InputParameters), FormatParametersForMermaid(new.InputParameters))
+	}
+	if d.parametersType(old.ReturnParameters, true) != d.parametersType(new.ReturnParameters, false) {
+		d.changed(e, ChangeRpc, name, "response changed", FormatParametersForMermaid(old.ReturnParameters), FormatParametersForMermaid(new.ReturnParameters))
+	}
+	d.comment(e, ChangeRpc, name, old.Comment, new.Comment)
+	d.options(e, ChangeRpc, name, rpcOptionsText(old.Options), rpcOptionsText(new.Options))
+}
+
+// rpcOptionsText formats the options of an rpc as they are declared.
+func rpcOptionsText(options []*RpcOption) string {
+	out := make([]string, 0, len(options))
+	for _, o := range options {
+		out = append(out, o.Name+" = "+o.Body)
+	}
+	return strings.Join(out, Comma+Space)
+}
+
+// ToMarkdown formats the changelog with a section per package and per entity,
+// the changes listed in a table, followed by the diagrams of changed messages.
+func (cl *Changelog) ToMarkdown() string {
+	out := "# API Changelog\n"
+	if !cl.HasChanges() {
+		return out + "\nNo changes.\n"
+	}
+	for _, pc := range cl.Packages {
+		out += fmt.Sprintf("\n## Package `%s`\n", pc.Package)
+		if len(pc.Changes) > 0 {
+			out += EndL + changesToMarkdown(pc.Changes)
+		}
+		for _, e := range pc.Entities {
+			out += fmt.Sprintf("\n### %s `%s`\n\n", strings.ToUpper(e.Element[:1])+e.Element[1:], e.Name)
+			out += changesToMarkdown(e.Changes)
+			if len(e.Before) > 0 {
+				out += "\n#### Before\n\n" + e.Before
+			}
+			if len(e.After) > 0 {
+				out += "\n#### After\n\n" + e.After
+			}
+		}
+	}
+	return out
+}
+
+func changesToMarkdown(changes []*Change) string {
+	table := NewMarkdownTable()
+	table.AddHeader("Change", "Element", "Name", "Description", "Before", "After")
+	for _, c := range changes {
+		table.Insert(c.Kind, c.Element, c.Name, c.Description, markdownCell(c.Before), markdownCell(c.After))
+	}
+	return table.String()
+}
+
+// markdownCell formats a value for a table cell, on one line without pipes.
+func markdownCell(in string) string {
+	return EscapeTableCell(strings.TrimSpace(Comment(in).ToMarkdownText(false)))
+}
+
+// ToJSON formats the changelog as indented JSON.
+func (cl *Changelog) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(cl, "", "  ")
+}
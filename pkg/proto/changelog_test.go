@@ -0,0 +1,265 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func changeNames(cl *Changelog) []string {
+	out := make([]string, 0)
+	for _, pc := range cl.Packages {
+		for _, c := range pc.Changes {
+			out = append(out, c.Kind+" "+c.Element+" "+c.Name)
+		}
+		for _, e := range pc.Entities {
+			for _, c := range e.Changes {
+				out = append(out, c.Kind+" "+c.Element+" "+c.Name+": "+c.Description)
+			}
+		}
+	}
+	return out
+}
+
+func parseTree(t *testing.T, source string) *Tree {
+	p, err := ParseString("a.proto", source)
+	assert.Nil(t, err)
+	assert.Empty(t, Link(p))
+	return &Tree{Packages: []*Package{p}}
+}
+
+func TestDiff(t *testing.T) {
+	old, current := readBreakingTrees(t)
+	cl := Diff(old, current)
+	assert.True(t, cl.HasChanges())
+	// The type of Book.shelf is renamed with its package, it is not changed
+	assert.Equal(t, []string{
+		"removed file library/loan.proto",
+		"changed field test.library.Book.title: number changed",
+		"changed field test.library.Book.pages: type changed",
+		"changed field test.library.Book.authors: label changed",
+		"removed field test.library.Book.isbn: field 5 removed",
+		"renamed field test.library.Book.description: field 7 renamed",
+		"removed field test.library.Book.year: field 8 removed",
+		"removed enum value test.library.Book.Status.STATUS_LOST: enum value 2 removed",
+		"removed enum value test.library.Book.Status.STATUS_RETIRED: enum value 3 removed",
+		"changed enum value test.library.Book.Status.STATUS_ORDERED: number changed",
+		"renamed enum value test.library.Book.Status.STATUS_MISPLACED: enum value 5 renamed",
+		"added enum value test.library.Book.Status.STATUS_ON_LOAN: enum value 7 added",
+		"changed field test.library.Review.text: number changed",
+		"removed field test.library.Review.title: field 2 removed",
+		"changed enum value test.library.Review.Rating.RATING_GOOD: number changed",
+		"removed enum value test.library.Review.Rating.RATING_BAD: enum value 2 removed",
+		"changed rpc test.library.Library.GetBook: request changed",
+		"changed rpc test.library.Library.ListBooks: response changed",
+		"changed rpc test.library.Library.UpdateBook: response changed",
+		"removed rpc test.library.Library.DeleteBook: rpc removed",
+		"removed message test.library.Author: message removed",
+		"removed enum test.library.Genre: enum removed",
+		"removed service test.library.Archive: service removed",
+		"removed message test.library.Loan: message removed",
+		"added message test.library.GetBookByTitleRequest: message added",
+		"added message test.library.UpdateBookResponse: message added",
+		"renamed package test.shelves",
+	}, changeNames(cl))
+
+	book := cl.Packages[0].Entities[0]
+	assert.Equal(t, &Change{Kind: ChangeChanged, Element: ChangeField, Name: "test.library.Book.pages",
+		Description: "type changed", Before: "int32", After: "int64"}, book.Changes[1])
+	assert.True(t, strings.HasPrefix(book.Before, "```mermaid\nclassDiagram\n"))
+	assert.Contains(t, book.Before, "+ int32 year")
+	assert.NotContains(t, book.After, "+ int32 year")
+	assert.Empty(t, cl.Packages[0].Entities[1].Before)
+	assert.Equal(t, &Change{Kind: ChangeChanged, Element: ChangeRpc, Name: "test.library.Library.ListBooks",
+		Description: "response changed", Before: "Stream~Book~", After: "Book"}, cl.Packages[0].Entities[4].Changes[1])
+
+	assert.False(t, Diff(current, current).HasChanges())
+}
+
+func TestDiff_Renamed(t *testing.T) {
+	old := parseTree(t, `syntax = "proto3";
+package a;
+
+// A book
+message Book {
+  string name = 1; // The name
+  int32 pages = 2;
+}
+
+message Empty {}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_OK = 1;
+}
+
+message Request {
+  string name = 1;
+}
+
+service Library {
+  option deprecated = false;
+  rpc Get(Request) returns (Request);
+}`)
+	current := parseTree(t, `syntax = "proto3";
+package a;
+
+// A book of the library
+message Volume {
+  string name = 1 [deprecated = true]; // The title
+  int32 pages = 2;
+}
+
+message Nothing {}
+
+enum State {
+  STATE_UNSPECIFIED = 0;
+  STATE_OK = 1;
+}
+
+message Request {
+  string name = 1;
+}
+
+service Library {
+  option deprecated = true;
+  rpc Read(Request) returns (Request) {
+    option deprecated = true;
+  }
+}`)
+	cl := Diff(old, current)
+	// The entities found by name come first, empty messages have no content to
+	// be paired with
+	assert.Equal(t, []string{
+		"changed service a.Library: options changed",
+		"renamed rpc a.Library.Read: rpc renamed",
+		"changed rpc a.Library.Read: options changed",
+		"renamed message a.Volume: message renamed",
+		"changed message a.Volume: comment changed",
+		"changed field a.Volume.name: comment changed",
+		"changed field a.Volume.name: options changed",
+		"removed message a.Empty: message removed",
+		"renamed enum a.State: enum renamed",
+		"renamed enum value a.State.STATE_UNSPECIFIED: enum value 0 renamed",
+		"renamed enum value a.State.STATE_OK: enum value 1 renamed",
+		"added message a.Nothing: message added",
+	}, changeNames(cl))
+	volume := cl.Packages[0].Entities[1]
+	assert.Equal(t, &Change{Kind: ChangeRenamed, Element: ChangeMessage, Name: "a.Volume",
+		Description: "message renamed", Before: "a.Book", After: "a.Volume"}, volume.Changes[0])
+	assert.Equal(t, &Change{Kind: ChangeChanged, Element: ChangeField, Name: "a.Volume.name",
+		Description: "options changed", After: "deprecated = true"}, volume.Changes[3])
+	assert.Contains(t, volume.Before, "class Book")
+	assert.Contains(t, volume.After, "class Volume")
+}
+
+func TestDiff_UnrelatedEnums(t *testing.T) {
+	old := parseTree(t, `syntax = "proto3";
+package a;
+
+enum Color {
+  COLOR_UNSPECIFIED = 0;
+  COLOR_RED = 1;
+}`)
+	current := parseTree(t, `syntax = "proto3";
+package a;
+
+enum Size {
+  SIZE_UNSPECIFIED = 0;
+  SIZE_LARGE = 1;
+}`)
+	// The enums are numbered alike but their values differ, they are not renamed
+	assert.Equal(t, []string{
+		"removed enum a.Color: enum removed",
+		"added enum a.Size: enum added",
+	}, changeNames(Diff(old, current)))
+}
+
+func TestDiff_NamesBeforeNumbers(t *testing.T) {
+	old := parseTree(t, `syntax = "proto3";
+package a;
+
+message Book {
+  string a = 1;
+  string b = 2;
+}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_A = 1;
+  STATUS_B = 2;
+}`)
+	current := parseTree(t, `syntax = "proto3";
+package a;
+
+message Book {
+  string b = 1;
+  string c = 2;
+}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_B = 1;
+  STATUS_C = 2;
+}`)
+	assert.Equal(t, []string{
+		"removed field a.Book.a: field 1 removed",
+		"changed field a.Book.b: number changed",
+		"added field a.Book.c: field 2 added",
+		"removed enum value a.Status.STATUS_A: enum value 1 removed",
+		"changed enum value a.Status.STATUS_B: number changed",
+		"added enum value a.Status.STATUS_C: enum value 2 added",
+	}, changeNames(Diff(old, current)))
+}
+
+func TestChangelog(t *testing.T) {
+	cl := &Changelog{Packages: []*PackageChanges{{
+		Package: "a",
+		Changes: []*Change{{Kind: ChangeAdded, Element: ChangeFile, Name: "a.proto", Description: "file added"}},
+		Entities: []*EntityChanges{{Element: ChangeEnumValue, Name: "a.E", Changes: []*Change{
+			{Kind: ChangeChanged, Element: ChangeEnumValue, Name: "a.E.A", Description: "comment changed", Before: "a | b", After: "c"},
+		}}},
+	}}}
+	assert.Equal(t, `# API Changelog
+
+## Package `+"`a`"+`
+
+| Change | Element | Name    | Description | Before | After |
+|--------|---------|---------|-------------|--------|-------|
+| added  | file    | a.proto | file added  |        |       |
+
+### Enum value `+"`a.E`"+`
+
+| Change  | Element    | Name  | Description     | Before | After |
+|---------|------------|-------|-----------------|--------|-------|
+| changed | enum value | a.E.A | comment changed | a \| b | c     |
+`, cl.ToMarkdown())
+
+	data, err := cl.ToJSON()
+	assert.Nil(t, err)
+	read := &Changelog{}
+	assert.Nil(t, json.Unmarshal(data, read))
+	assert.Equal(t, cl, read)
+
+	empty := &Changelog{Packages: make([]*PackageChanges, 0)}
+	assert.False(t, empty.HasChanges())
+	assert.Equal(t, "# API Changelog\n\nNo changes.\n", empty.ToMarkdown())
+}
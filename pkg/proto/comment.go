@@ -34,6 +34,19 @@ func (c Comment) ToMermaid() string {
 	return out
 }
 
+// ToPlantUML formats the comment as PlantUML comment lines, an empty comment has
+// no lines.
+func (c Comment) ToPlantUML() string {
+	if len(c.TrimSpace()) == 0 {
+		return Empty
+	}
+	out := ""
+	for _, line := range strings.Split(string(c), CommentNewLine) {
+		out += fmt.Sprintf("' %s\n", strings.TrimSpace(line))
+	}
+	return out
+}
+
 func (c Comment) ToMarkdownText(linebreak bool) string {
 	comments := strings.Split(string(c), CommentNewLine)
 	out := ""
@@ -78,3 +91,75 @@ func (c Comment) TrimSpace() Comment {
 func (c Comment) Clear() Comment {
 	return c[:0]
 }
+
+// Comments are the comments attached to a declaration following the rules of
+// protoc's SourceCodeInfo. The Leading comment directly precedes the
+// declaration, the Trailing comment follows it on the same line, or on the
+// next lines up to a blank line, and the Detached comments precede the leading
+// comment separated by blank lines.
+type Comments struct {
+	Leading  Comment
+	Trailing Comment
+	Detached []Comment
+}
+
+// CommentStyle selects the attached comments rendered by the writers.
+type CommentStyle int
+
+const (
+	// CommentsAll renders the detached, leading and trailing comments.
+	CommentsAll CommentStyle = iota
+	// CommentsAttached renders the leading and trailing comments.
+	CommentsAttached
+	// CommentsLeading renders the leading comments.
+	CommentsLeading
+)
+
+// CommentStyleNames are the names of the comment styles, used by the -comments flag.
+var CommentStyleNames = map[string]CommentStyle{
+	"all":      CommentsAll,
+	"attached": CommentsAttached,
+	"leading":  CommentsLeading,
+}
+
+// ParseCommentStyle reads a comment style from its name.
+func ParseCommentStyle(in string) (CommentStyle, error) {
+	if style, ok := CommentStyleNames[in]; ok {
+		return style, nil
+	}
+	return CommentsAll, fmt.Errorf("unknown comment style %q, expected all, attached or leading", in)
+}
+
+// Render joins the comments selected by the style, detached comments first.
+func (c *Comments) Render(style CommentStyle) Comment {
+	if c == nil {
+		return Empty
+	}
+	out := Comment(Empty)
+	if style == CommentsAll {
+		for _, d := range c.Detached {
+			out = out.Append(d)
+		}
+	}
+	out = out.Append(c.Leading)
+	if style != CommentsLeading {
+		out = out.Append(c.Trailing)
+	}
+	return out.TrimSpace()
+}
+
+// Merge combines the comments of two consecutive declarations documenting a
+// single element, e.g. the syntax and package statements of a file.
+func (c *Comments) Merge(other *Comments) *Comments {
+	if c == nil {
+		return other
+	}
+	if other == nil {
+		return c
+	}
+	return &Comments{
+		Leading:  c.Leading.Append(other.Leading).TrimSpace(),
+		Trailing: c.Trailing.Append(other.Trailing).TrimSpace(),
+		Detached: append(append(make([]Comment, 0), c.Detached...), other.Detached...),
+	}
+}
@@ -86,6 +86,22 @@ func TestComment_ToMermaid(t *testing.T) {
 	}
 }
 
+func TestComment_ToPlantUML(t *testing.T) {
+	tests := []struct {
+		name string
+		c    Comment
+		want string
+	}{
+		{name: "To PlantUML", c: Comment("Test:~: Lines"), want: "' Test\n' Lines\n"},
+		{name: "Empty", c: Comment(" "), want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, tt.c.ToPlantUML(), "ToPlantUML()")
+		})
+	}
+}
+
 func TestComment_TrimSpace(t *testing.T) {
 	tests := []struct {
 		name string
@@ -100,3 +116,39 @@ func TestComment_TrimSpace(t *testing.T) {
 		})
 	}
 }
+
+func TestComments_Render(t *testing.T) {
+	comments := &Comments{Leading: "Leading", Trailing: "Trailing", Detached: []Comment{"First", "Second"}}
+	tests := []struct {
+		name     string
+		comments *Comments
+		style    CommentStyle
+		want     Comment
+	}{
+		{name: "All", comments: comments, style: CommentsAll, want: "First Second Leading Trailing"},
+		{name: "Attached", comments: comments, style: CommentsAttached, want: "Leading Trailing"},
+		{name: "Leading", comments: comments, style: CommentsLeading, want: "Leading"},
+		{name: "Nil", comments: nil, style: CommentsAll, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, tt.comments.Render(tt.style), "Render(%v)", tt.style)
+		})
+	}
+}
+
+func TestComments_Merge(t *testing.T) {
+	syntax := &Comments{Leading: "License", Detached: []Comment{"Header"}}
+	pkg := &Comments{Leading: "Package", Trailing: "Trailing", Detached: []Comment{}}
+	assert.Equal(t, &Comments{Leading: "License Package", Trailing: "Trailing", Detached: []Comment{"Header"}}, syntax.Merge(pkg))
+	assert.Equal(t, pkg, (*Comments)(nil).Merge(pkg))
+	assert.Equal(t, syntax, syntax.Merge(nil))
+}
+
+func TestParseCommentStyle(t *testing.T) {
+	style, err := ParseCommentStyle("attached")
+	assert.Nil(t, err)
+	assert.Equal(t, CommentsAttached, style)
+	_, err = ParseCommentStyle("trailing")
+	assert.EqualError(t, err, `unknown comment style "trailing", expected all, attached or leading`)
+}
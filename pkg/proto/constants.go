@@ -24,6 +24,24 @@ const (
 	PrefixMap      = "map"
 	PrefixReserved = "reserved"
 	PrefixOptional = "optional"
+	PrefixRequired = "required"
+	PrefixOption   = "option"
+	PrefixGroup    = "group"
+
+	PrefixExtensions = "extensions"
+	KeywordTo        = "to"
+	KeywordMax       = "max"
+	KeywordStream    = "stream"
+
+	AnnotationDefault = "default"
+	AnnotationPacked  = "packed"
+
+	KeywordSyntax  = "syntax"
+	KeywordEdition = "edition"
+	PrefixFeatures = "features."
+
+	SyntaxProto2 = "proto2"
+	SyntaxProto3 = "proto3"
 
 	SpaceRemovalRegex = `\s+`
 	Period            = "."
@@ -49,9 +67,14 @@ const (
 	CommentNewLine             = `:~:`
 )
 
+// MaxFieldNumber is the largest field number allowed by the protobuf wire format,
+// and the value represented by the `max` keyword in ranges.
+const MaxFieldNumber = 536870911
+
 // From gist: https://gist.github.com/ik5/d8ecde700972d4378d87
 const (
 	InfoColor  = "\033[1;32mINFO: %s\033[0m"
 	ErrorColor = "\033[1;31mERROR: %s\033[0m"
+	WarnColor  = "\033[1;33mWARN: %s\033[0m"
 	DebugColor = "\033[1;36mDEBUG: %s\033[0m"
 )
@@ -0,0 +1,261 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// The kinds of the elements counted by the documentation coverage.
+const (
+	CoverageMessage   = "message"
+	CoverageField     = "field"
+	CoverageEnum      = "enum"
+	CoverageEnumValue = "enum value"
+	CoverageService   = "service"
+	CoverageRpc       = "rpc"
+)
+
+// CoverageElement is an element counted by the documentation coverage.
+type CoverageElement struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Location   string `json:"location"`
+	Documented bool   `json:"documented"`
+}
+
+// Coverage is the number of documented elements out of the elements counted,
+// the percentage is 100 when there are none.
+type Coverage struct {
+	Documented int     `json:"documented"`
+	Total      int     `json:"total"`
+	Percentage float64 `json:"percentage"`
+}
+
+// add counts the element, and updates the percentage.
+func (c *Coverage) add(documented bool) {
+	c.Total++
+	if documented {
+		c.Documented++
+	}
+	c.Percentage = float64(c.Documented) * 100 / float64(c.Total)
+}
+
+func newCoverage() Coverage {
+	return Coverage{Percentage: 100}
+}
+
+// String formats the coverage as a percentage, e.g. `75.00% (3/4)`.
+func (c Coverage) String() string {
+	return fmt.Sprintf("%.2f%% (%d/%d)", c.Percentage, c.Documented, c.Total)
+}
+
+// FileCoverage is the documentation coverage of a file, with the elements
+// without comments.
+type FileCoverage struct {
+	Coverage
+	Path         string             `json:"path"`
+	Package      string             `json:"package"`
+	Undocumented []*CoverageElement `json:"undocumented"`
+}
+
+// PackageCoverage is the documentation coverage of the files of a package.
+type PackageCoverage struct {
+	Coverage
+	Package string `json:"package"`
+}
+
+// CoverageReport is the documentation coverage of a set of files: the messages,
+// fields, enums, enum values, services and rpcs with a leading or trailing
+// comment out of all of them.
+type CoverageReport struct {
+	Coverage
+	Packages []*PackageCoverage `json:"packages"`
+	Files    []*FileCoverage    `json:"files"`
+}
+
+// NewCoverageReport computes the documentation coverage of the packages, the
+// packages of a name are reported together, sorted by name.
+func NewCoverageReport(packages ...*Package) *CoverageReport {
+	out := &CoverageReport{Coverage: newCoverage(), Packages: make([]*PackageCoverage, 0), Files: make([]*FileCoverage, 0)}
+	byName := make(map[string]*PackageCoverage)
+	for _, p := range packages {
+		file := &FileCoverage{Coverage: newCoverage(), Path: p.Path, Package: p.Name, Undocumented: make([]*CoverageElement, 0)}
+		pc, ok := byName[p.Name]
+		if !ok {
+			pc = &PackageCoverage{Coverage: newCoverage(), Package: p.Name}
+			byName[p.Name] = pc
+			out.Packages = append(out.Packages, pc)
+		}
+		for _, e := range CoverageElements(p) {
+			file.add(e.Documented)
+			pc.add(e.Documented)
+			out.add(e.Documented)
+			if !e.Documented {
+				file.Undocumented = append(file.Undocumented, e)
+			}
+		}
+		out.Files = append(out.Files, file)
+	}
+	sort.SliceStable(out.Packages, func(i, j int) bool {
+		return out.Packages[i].Package < out.Packages[j].Package
+	})
+	return out
+}
+
+// CoverageElements returns the elements of the package counted by the
+// documentation coverage, in declaration order. The messages of groups are
+// documented by their field, they are not counted.
+func CoverageElements(p *Package) []*CoverageElement {
+	c := &coverageCollector{out: make([]*CoverageElement, 0), groups: make(map[*Message]bool), groupLocations: make(map[Location]bool)}
+	for _, m := range p.Messages {
+		c.collectMessageGroups(m)
+	}
+	for _, e := range p.Extends {
+		c.collectGroups(e.Attributes)
+	}
+	for _, m := range p.Messages {
+		c.message(m)
+	}
+	for _, e := range p.Extends {
+		c.fields(e.Attributes)
+	}
+	for _, e := range p.Enums {
+		c.enum(e)
+	}
+	for _, s := range p.Services {
+		c.add(CoverageService, Join(Period, s.Qualifier, s.Name), s.Location, s.Comment, s.Comments)
+		for _, rpc := range s.Methods {
+			c.add(CoverageRpc, Join(Period, rpc.Qualifier, rpc.Name), rpc.Location, rpc.Comment, rpc.Comments)
+		}
+	}
+	return c.out
+}
+
+// IsDocumented determines if an element has a leading or trailing comment,
+// detached comments do not document it.
+func IsDocumented(comment Comment, comments *Comments) bool {
+	if comments != nil {
+		return len(comments.Render(CommentsAttached).TrimSpace()) > 0
+	}
+	return len(comment.TrimSpace()) > 0
+}
+
+// coverageCollector lists the elements of a package.
+type coverageCollector struct {
+	out            []*CoverageElement
+	groups         map[*Message]bool
+	groupLocations map[Location]bool
+}
+
+// collectGroups records the messages of the group fields, resolved by Link or
+// declared at the location of the field.
+func (c *coverageCollector) collectGroups(attributes []*Attribute) {
+	for _, a := range attributes {
+		if !a.Group {
+			continue
+		}
+		if s := a.ResolvedKind(0); s != nil && s.Message != nil {
+			c.groups[s.Message] = true
+		}
+		c.groupLocations[a.Location] = true
+	}
+}
+
+func (c *coverageCollector) collectMessageGroups(m *Message) {
+	c.collectGroups(m.Attributes)
+	for _, e := range m.Extends {
+		c.collectGroups(e.Attributes)
+	}
+	for _, nested := range m.Messages {
+		c.collectMessageGroups(nested)
+	}
+}
+
+func (c *coverageCollector) add(kind string, name string, location Location, comment Comment, comments *Comments) {
+	c.out = append(c.out, &CoverageElement{Kind: kind, Name: QualifiedName(name), Location: location.String(), Documented: IsDocumented(comment, comments)})
+}
+
+func (c *coverageCollector) message(m *Message) {
+	if !c.groups[m] && !(m.Location.IsValid() && c.groupLocations[m.Location]) {
+		c.add(CoverageMessage, m.Qualifier, m.Location, m.Comment, m.Comments)
+	}
+	c.fields(m.Attributes)
+	for _, e := range m.Extends {
+		c.fields(e.Attributes)
+	}
+	for _, e := range m.Enums {
+		c.enum(e)
+	}
+	for _, nested := range m.Messages {
+		c.message(nested)
+	}
+}
+
+func (c *coverageCollector) fields(attributes []*Attribute) {
+	for _, a := range attributes {
+		c.add(CoverageField, Join(Period, a.Qualifier, a.Name), a.Location, a.Comment, a.Comments)
+	}
+}
+
+func (c *coverageCollector) enum(e *Enum) {
+	c.add(CoverageEnum, e.Qualifier, e.Location, e.Comment, e.Comments)
+	for _, v := range e.Values {
+		c.add(CoverageEnumValue, Join(Period, e.Qualifier, v.Value), v.Location, v.Comment, v.Comments)
+	}
+}
+
+// ToMarkdown formats the report as markdown tables of the packages, the files
+// and the undocumented elements.
+func (cr *CoverageReport) ToMarkdown() string {
+	out := "# Documentation Coverage\n\n"
+	out += fmt.Sprintf("Overall: %s\n\n", cr.Coverage)
+
+	packages := NewMarkdownTable()
+	packages.AddHeader("Package", "Documented", "Total", "Coverage")
+	for _, p := range cr.Packages {
+		packages.Insert(p.Package, strconv.Itoa(p.Documented), strconv.Itoa(p.Total), fmt.Sprintf("%.2f%%", p.Percentage))
+	}
+	out += "## Packages\n\n" + packages.String() + EndL
+
+	files := NewMarkdownTable()
+	files.AddHeader("File", "Package", "Documented", "Total", "Coverage")
+	for _, f := range cr.Files {
+		files.Insert(f.Path, f.Package, strconv.Itoa(f.Documented), strconv.Itoa(f.Total), fmt.Sprintf("%.2f%%", f.Percentage))
+	}
+	out += "## Files\n\n" + files.String()
+
+	undocumented := NewMarkdownTable()
+	undocumented.AddHeader("Element", "Kind", "Location")
+	for _, f := range cr.Files {
+		for _, e := range f.Undocumented {
+			undocumented.Insert(e.Name, e.Kind, e.Location)
+		}
+	}
+	if len(undocumented.Data) > 0 {
+		out += "\n## Undocumented\n\n" + undocumented.String()
+	}
+	return out
+}
+
+// ToJSON formats the report as indented JSON.
+func (cr *CoverageReport) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(cr, "", "  ")
+}
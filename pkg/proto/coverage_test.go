@@ -0,0 +1,125 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoverageElements(t *testing.T) {
+	p, err := ParseString("a.proto", `syntax = "proto2";
+package test;
+
+// A book
+message Book {
+  // The title
+  optional string title = 1;
+  optional string isbn = 2; // The ISBN
+
+  // Detached comments do not document the field
+
+  optional int32 pages = 3;
+  // A chapter
+  repeated group Chapter = 4 {
+    optional string name = 5;
+  }
+  enum Format {
+    // Unspecified
+    FORMAT_UNSPECIFIED = 0;
+    FORMAT_EBOOK = 1;
+  }
+}
+
+service Library {
+  // Returns a book
+  rpc GetBook(Book) returns (Book);
+}`)
+	assert.Nil(t, err)
+	Link(p)
+
+	got := make([]string, 0)
+	for _, e := range CoverageElements(p) {
+		got = append(got, e.Kind+" "+e.Name+" "+e.Location+" "+map[bool]string{true: "documented", false: "undocumented"}[e.Documented])
+	}
+	assert.Equal(t, []string{
+		"message test.Book a.proto:5:1 documented",
+		"field test.Book.title a.proto:7:3 documented",
+		"field test.Book.isbn a.proto:8:3 documented",
+		"field test.Book.pages a.proto:12:3 undocumented",
+		"field test.Book.chapter a.proto:14:3 documented",
+		"enum test.Book.Format a.proto:17:3 undocumented",
+		"enum value test.Book.Format.FORMAT_UNSPECIFIED a.proto:19:5 documented",
+		"enum value test.Book.Format.FORMAT_EBOOK a.proto:20:5 undocumented",
+		"field test.Book.Chapter.name a.proto:15:5 undocumented",
+		"service test.Library a.proto:24:1 undocumented",
+		"rpc test.Library.GetBook a.proto:26:3 documented",
+	}, got)
+}
+
+func TestNewCoverageReport(t *testing.T) {
+	a, err := ParseString("a.proto", "package test;\n// A\nmessage A {\n  string a = 1;\n}")
+	assert.Nil(t, err)
+	b, err := ParseString("b.proto", "package test;\n// B\nmessage B {}")
+	assert.Nil(t, err)
+	c, err := ParseString("c.proto", "package other;")
+	assert.Nil(t, err)
+
+	report := NewCoverageReport(c, a, b)
+	assert.Equal(t, Coverage{Documented: 2, Total: 3, Percentage: 200.0 / 3}, report.Coverage)
+	assert.Equal(t, "66.67% (2/3)", report.Coverage.String())
+	assert.Equal(t, "other", report.Packages[0].Package)
+	assert.Equal(t, Coverage{Percentage: 100}, report.Packages[0].Coverage)
+	assert.Equal(t, Coverage{Documented: 2, Total: 3, Percentage: 200.0 / 3}, report.Packages[1].Coverage)
+	assert.Len(t, report.Files, 3)
+	assert.Equal(t, "a.proto", report.Files[1].Path)
+	assert.Equal(t, []*CoverageElement{{Kind: CoverageField, Name: "test.A.a", Location: "a.proto:4:3"}}, report.Files[1].Undocumented)
+
+	assert.Equal(t, `# Documentation Coverage
+
+Overall: 66.67% (2/3)
+
+## Packages
+
+| Package | Documented | Total | Coverage |
+|---------|------------|-------|----------|
+| other   | 0          | 0     | 100.00%  |
+| test    | 2          | 3     | 66.67%   |
+
+## Files
+
+| File    | Package | Documented | Total | Coverage |
+|---------|---------|------------|-------|----------|
+| c.proto | other   | 0          | 0     | 100.00%  |
+| a.proto | test    | 1          | 2     | 50.00%   |
+| b.proto | test    | 1          | 1     | 100.00%  |
+
+## Undocumented
+
+| Element  | Kind  | Location    |
+|----------|-------|-------------|
+| test.A.a | field | a.proto:4:3 |
+`, report.ToMarkdown())
+
+	data, err := report.ToJSON()
+	assert.Nil(t, err)
+	read := &CoverageReport{}
+	assert.Nil(t, json.Unmarshal(data, read))
+	assert.Equal(t, report, read)
+}
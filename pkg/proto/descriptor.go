@@ -0,0 +1,721 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	protobuf "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// The field numbers of the descriptor.proto messages used in the paths of the
+// SourceCodeInfo locations.
+const (
+	fileMessagesPath     = 4
+	fileEnumsPath        = 5
+	fileServicesPath     = 6
+	fileExtensionsPath   = 7
+	fileOptionsPath      = 8
+	filePackagePath      = 2
+	fileDependenciesPath = 3
+	fileSyntaxPath       = 12
+	fileEditionPath      = 14
+
+	messageFieldsPath          = 2
+	messageMessagesPath        = 3
+	messageEnumsPath           = 4
+	messageExtensionRangesPath = 5
+	messageExtensionsPath      = 6
+	messageOptionsPath         = 7
+	messageOneofsPath          = 8
+	messageReservedRangesPath  = 9
+	messageReservedNamesPath   = 10
+
+	enumValuesPath         = 2
+	enumOptionsPath        = 3
+	enumReservedRangesPath = 4
+	enumReservedNamesPath  = 5
+	enumValueOptionsPath   = 3
+
+	serviceMethodsPath = 2
+	serviceOptionsPath = 3
+	methodOptionsPath  = 4
+	fieldOptionsPath   = 8
+)
+
+// ReadDescriptorSet reads a binary FileDescriptorSet, such as the output of
+// `protoc --descriptor_set_out --include_source_info`, into packages.
+func ReadDescriptorSet(r io.Reader) ([]*Package, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := protobuf.Unmarshal(data, set); err != nil {
+		return nil, fmt.Errorf("invalid descriptor set: %w", err)
+	}
+	return DescriptorSetToPackages(set), nil
+}
+
+// DescriptorSetToPackages converts the files of a FileDescriptorSet into
+// packages, the comments are read from the SourceCodeInfo of the files. Type
+// names are written relative to their scope, as they would be in the sources.
+func DescriptorSetToPackages(set *descriptorpb.FileDescriptorSet) []*Package {
+	types := descriptorTypes(set)
+	out := make([]*Package, 0)
+	byName := make(map[string]*Package)
+	for _, file := range set.GetFile() {
+		p := newDescriptorReader(file, types).read()
+		byName[p.Path] = p
+		out = append(out, p)
+	}
+	for _, p := range out {
+		for _, i := range p.Imports {
+			i.Package = byName[i.Path]
+		}
+	}
+	relativizeTypes(NewSymbolTable(out...), out)
+	return out
+}
+
+// FileDescriptorToPackage converts a file descriptor into a package, custom
+// options are only read if their extensions are registered.
+func FileDescriptorToPackage(file *descriptorpb.FileDescriptorProto) *Package {
+	out := newDescriptorReader(file, nil).read()
+	relativizeTypes(NewSymbolTable(out), []*Package{out})
+	return out
+}
+
+// descriptorTypes returns the extensions declared in the set, so the custom
+// options of the files can be read. The imports of a file precede it in the
+// set, the files that cannot be resolved, and their extensions, are skipped.
+func descriptorTypes(set *descriptorpb.FileDescriptorSet) *protoregistry.Types {
+	files := new(protoregistry.Files)
+	for _, file := range set.GetFile() {
+		fd, err := protodesc.FileOptions{AllowUnresolvable: true}.New(file, files)
+		if err == nil {
+			err = files.RegisterFile(fd)
+		}
+		if err != nil {
+			Log.Debugf("Custom options of %s are not resolved: %v\n", file.GetName(), err)
+		}
+	}
+	return extensionTypes(files)
+}
+
+// extensionTypes returns the types of the extensions declared in the files.
+func extensionTypes(files *protoregistry.Files) *protoregistry.Types {
+	out := new(protoregistry.Types)
+	var register func(extensions protoreflect.ExtensionDescriptors, messages protoreflect.MessageDescriptors)
+	register = func(extensions protoreflect.ExtensionDescriptors, messages protoreflect.MessageDescriptors) {
+		for i := 0; i < extensions.Len(); i++ {
+			_ = out.RegisterExtension(dynamicpb.NewExtensionType(extensions.Get(i)))
+		}
+		for i := 0; i < messages.Len(); i++ {
+			register(messages.Get(i).Extensions(), messages.Get(i).Messages())
+		}
+	}
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		register(fd.Extensions(), fd.Messages())
+		return true
+	})
+	return out
+}
+
+// descriptorReader converts a file descriptor, locations are the
+// SourceCodeInfo locations by path. A path has several locations when it is
+// declared by several statements, e.g. the extend blocks of a scope.
+type descriptorReader struct {
+	file      *descriptorpb.FileDescriptorProto
+	types     *protoregistry.Types
+	locations map[string][]*descriptorpb.SourceCodeInfo_Location
+}
+
+func newDescriptorReader(file *descriptorpb.FileDescriptorProto, types *protoregistry.Types) *descriptorReader {
+	out := &descriptorReader{file: file, types: types, locations: make(map[string][]*descriptorpb.SourceCodeInfo_Location)}
+	for _, l := range file.GetSourceCodeInfo().GetLocation() {
+		out.locations[pathKey(l.GetPath())] = append(out.locations[pathKey(l.GetPath())], l)
+	}
+	return out
+}
+
+func pathKey(path []int32) string {
+	return fmt.Sprint(path)
+}
+
+// child returns the path of an element declared in the path.
+func child(path []int32, values ...int32) []int32 {
+	return append(append(make([]int32, 0, len(path)+len(values)), path...), values...)
+}
+
+// location returns the source location of a path.
+func (dr *descriptorReader) location(path []int32) Location {
+	if l := dr.locations[pathKey(path)]; len(l) > 0 {
+		return dr.spanLocation(l[0])
+	}
+	return Location{}
+}
+
+// spanLocation converts the span of a location, which is zero based and its
+// end exclusive.
+func (dr *descriptorReader) spanLocation(l *descriptorpb.SourceCodeInfo_Location) Location {
+	span := l.GetSpan()
+	if len(span) < 3 {
+		return Location{}
+	}
+	end := Position{Line: int(span[0]) + 1, Column: int(span[2])}
+	if len(span) == 4 {
+		end = Position{Line: int(span[2]) + 1, Column: int(span[3])}
+	}
+	return Location{File: dr.file.GetName(), Start: Position{Line: int(span[0]) + 1, Column: int(span[1]) + 1}, End: end}
+}
+
+// comments returns the comments of a path, nil if the file has no source
+// information for it.
+func (dr *descriptorReader) comments(path []int32) *Comments {
+	if l := dr.locations[pathKey(path)]; len(l) > 0 {
+		return locationComments(l[0])
+	}
+	return nil
+}
+
+func locationComments(l *descriptorpb.SourceCodeInfo_Location) *Comments {
+	out := &Comments{
+		Leading:  descriptorComment(l.GetLeadingComments()),
+		Trailing: descriptorComment(l.GetTrailingComments()),
+		Detached: make([]Comment, 0),
+	}
+	for _, d := range l.GetLeadingDetachedComments() {
+		out.Detached = append(out.Detached, descriptorComment(d))
+	}
+	return out
+}
+
+// descriptorComment joins the lines of a SourceCodeInfo comment, as the lines
+// of consecutive line comments are joined by the parser.
+func descriptorComment(in string) Comment {
+	out := Comment(Empty)
+	for _, line := range strings.Split(in, EndL) {
+		out = out.Append(Comment(line))
+	}
+	return out.TrimSpace()
+}
+
+func (dr *descriptorReader) read() *Package {
+	f := dr.file
+	out := NewPackage(f.GetName())
+	out.Name = f.GetPackage()
+	out.Location = dr.location([]int32{filePackagePath})
+	switch f.GetSyntax() {
+	case "editions":
+		out.Edition = strings.TrimPrefix(f.GetEdition().String(), "EDITION_")
+	case Empty:
+		out.Syntax = SyntaxProto2
+	default:
+		out.Syntax = f.GetSyntax()
+	}
+	syntaxPath := []int32{fileSyntaxPath}
+	if len(out.Edition) > 0 {
+		syntaxPath = []int32{fileEditionPath}
+	}
+	out.Comments = dr.comments(syntaxPath).Merge(dr.comments([]int32{filePackagePath}))
+	out.Comment = out.Comments.Render(CommentsAll)
+
+	for i, dependency := range f.GetDependency() {
+		imp := NewImport(dependency)
+		path := []int32{fileDependenciesPath, int32(i)}
+		imp.Location = dr.location(path)
+		imp.Comments = dr.comments(path)
+		imp.Comment = imp.Comments.Render(CommentsAll)
+		out.Imports = append(out.Imports, imp)
+	}
+	out.Options = dr.options(f.GetOptions(), []int32{fileOptionsPath})
+	for _, o := range out.Options {
+		if IsFeature(o.Name) {
+			out.Features = SetFeature(out.Features, o.Name, o.Value)
+		}
+	}
+	for i, m := range f.GetMessageType() {
+		out.Messages = append(out.Messages, dr.message(m, out.Name, []int32{fileMessagesPath, int32(i)}))
+	}
+	for i, e := range f.GetEnumType() {
+		out.Enums = append(out.Enums, dr.enum(e, out.Name, []int32{fileEnumsPath, int32(i)}))
+	}
+	for i, s := range f.GetService() {
+		out.Services = append(out.Services, dr.service(s, out.Name, []int32{fileServicesPath, int32(i)}))
+	}
+	out.Extends = dr.extensions(f.GetExtension(), out.Name, []int32{fileExtensionsPath})
+	ResolveFeatures(out)
+	return out
+}
+
+func (dr *descriptorReader) message(m *descriptorpb.DescriptorProto, namespace string, path []int32) *Message {
+	out := NewMessage()
+	out.Name = m.GetName()
+	out.Qualifier = Join(Period, namespace, out.Name)
+	out.Location = dr.location(path)
+	out.Comments = dr.comments(path)
+	out.Comment = out.Comments.Render(CommentsAll)
+	out.Options = dr.options(m.GetOptions(), child(path, messageOptionsPath))
+	for _, o := range out.Options {
+		if IsFeature(o.Name) {
+			out.Features = SetFeature(out.Features, o.Name, o.Value)
+		}
+	}
+
+	entries := make(map[string]*descriptorpb.DescriptorProto)
+	for i, nested := range m.GetNestedType() {
+		if nested.GetOptions().GetMapEntry() {
+			entries[Join(Period, out.Qualifier, nested.GetName())] = nested
+			continue
+		}
+		out.Messages = append(out.Messages, dr.message(nested, out.Qualifier, child(path, messageMessagesPath, int32(i))))
+	}
+	for i, e := range m.GetEnumType() {
+		out.Enums = append(out.Enums, dr.enum(e, out.Qualifier, child(path, messageEnumsPath, int32(i))))
+	}
+
+	oneofs := make([]*Oneof, len(m.GetOneofDecl()))
+	for i, o := range m.GetOneofDecl() {
+		oneofPath := child(path, messageOneofsPath, int32(i))
+		oneofs[i] = NewOneof(out.Qualifier, o.GetName(), Empty)
+		oneofs[i].Location = dr.location(oneofPath)
+		oneofs[i].Comments = dr.comments(oneofPath)
+		oneofs[i].Comment = oneofs[i].Comments.Render(CommentsAll)
+	}
+	for i, field := range m.GetField() {
+		a := dr.attribute(field, out.Qualifier, child(path, messageFieldsPath, int32(i)), entries)
+		out.Attributes = append(out.Attributes, a)
+		if field.OneofIndex != nil && !field.GetProto3Optional() {
+			oneofs[field.GetOneofIndex()].AddAttribute(a)
+		}
+	}
+	for _, o := range oneofs {
+		// The synthetic oneofs of proto3 optional fields are not declared
+		if len(o.Attributes) > 0 {
+			out.Oneofs = append(out.Oneofs, o)
+		}
+	}
+
+	for _, r := range m.GetExtensionRange() {
+		out.Extensions = append(out.Extensions, NewExtensionRange(r.GetStart(), r.GetEnd()-1))
+	}
+	ranges := make([]*Range, 0)
+	for _, r := range m.GetReservedRange() {
+		ranges = append(ranges, &Range{Start: r.GetStart(), End: r.GetEnd() - 1, Max: MaxFieldNumber})
+	}
+	out.Reserved = dr.reserved(ranges, m.GetReservedName(),
+		child(path, messageReservedRangesPath), child(path, messageReservedNamesPath))
+	out.Extends = dr.extensions(m.GetExtension(), out.Qualifier, child(path, messageExtensionsPath))
+	return out
+}
+
+// attribute converts a field, entries are the map entry messages of the
+// message declaring the field.
+func (dr *descriptorReader) attribute(field *descriptorpb.FieldDescriptorProto, namespace string, path []int32, entries map[string]*descriptorpb.DescriptorProto) *Attribute {
+	out := NewAttribute(namespace, Empty)
+	out.Name = field.GetName()
+	out.Ordinal = int(field.GetNumber())
+	out.Location = dr.location(path)
+	out.Comments = dr.comments(path)
+	out.Comment = out.Comments.Render(CommentsAll)
+
+	if entry, ok := entries[QualifiedName(field.GetTypeName())]; ok && field.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED {
+		out.Map = true
+		out.Kind = []string{fieldKind(entry.GetField()[0]), Space + fieldKind(entry.GetField()[1])}
+	} else {
+		out.Kind = []string{fieldKind(field)}
+		out.Group = field.GetType() == descriptorpb.FieldDescriptorProto_TYPE_GROUP
+		switch field.GetLabel() {
+		case descriptorpb.FieldDescriptorProto_LABEL_REPEATED:
+			out.Repeated = true
+		case descriptorpb.FieldDescriptorProto_LABEL_REQUIRED:
+			out.Required = true
+		default:
+			out.Optional = field.GetProto3Optional() ||
+				(dr.file.GetSyntax() != SyntaxProto3 && dr.file.GetSyntax() != "editions" && field.OneofIndex == nil)
+		}
+	}
+
+	if field.DefaultValue != nil {
+		constant := field.GetDefaultValue()
+		switch field.GetType() {
+		case descriptorpb.FieldDescriptorProto_TYPE_STRING:
+			constant = strconv.Quote(constant)
+		case descriptorpb.FieldDescriptorProto_TYPE_BYTES:
+			constant = DoubleQuote + constant + DoubleQuote
+		}
+		out.Default = constant
+		out.Annotations = append(out.Annotations, newDescriptorAnnotation(AnnotationDefault, constant))
+	}
+	if field.JsonName != nil && field.GetJsonName() != jsonName(field.GetName()) {
+		out.Annotations = append(out.Annotations, newDescriptorAnnotation("json_name", strconv.Quote(field.GetJsonName())))
+	}
+	for _, o := range dr.options(field.GetOptions(), child(path, fieldOptionsPath)) {
+		out.Annotations = append(out.Annotations, newDescriptorAnnotation(o.Name, o.Constant.String()))
+	}
+	return out
+}
+
+func newDescriptorAnnotation(name string, constant string) *Annotation {
+	out := NewAnnotation(name, strings.ReplaceAll(constant, SingleQuote, Empty))
+	out.Constant = NewOptionValue(constant)
+	return out
+}
+
+// fieldKind returns the type of a field, scalar types by their name and
+// message and enum types by their fully-qualified name.
+func fieldKind(field *descriptorpb.FieldDescriptorProto) string {
+	if len(field.GetTypeName()) > 0 {
+		return QualifiedName(field.GetTypeName())
+	}
+	return strings.ToLower(strings.TrimPrefix(field.GetType().String(), "TYPE_"))
+}
+
+// jsonName returns the default JSON name of a field, as computed by protoc.
+func jsonName(name string) string {
+	out := strings.Builder{}
+	upper := false
+	for _, r := range name {
+		if r == '_' {
+			upper = true
+		} else if upper {
+			out.WriteString(strings.ToUpper(string(r)))
+			upper = false
+		} else {
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
+
+// extensions converts the extension fields declared in a scope into their
+// extend blocks, whose locations are recorded at the path of the extensions of
+// the scope. Without source information, consecutive fields of the same
+// extendee are read as one block.
+func (dr *descriptorReader) extensions(fields []*descriptorpb.FieldDescriptorProto, namespace string, path []int32) []*Extension {
+	out := make([]*Extension, 0)
+	blocks := dr.locations[pathKey(path)]
+	var current *Extension
+	block := -1
+	for i, field := range fields {
+		extendee := QualifiedName(field.GetExtendee())
+		location := dr.location(child(path, int32(i)))
+		next := block
+		for next+1 < len(blocks) && !location.Start.Before(dr.spanLocation(blocks[next+1]).Start) {
+			next++
+		}
+		if current == nil || current.Extendee != extendee || next != block {
+			current = NewExtension(namespace, extendee, Empty)
+			current.Location = location
+			if next >= 0 && next != block {
+				current.Location = dr.spanLocation(blocks[next])
+				current.Comments = locationComments(blocks[next])
+				current.Comment = current.Comments.Render(CommentsAll)
+			}
+			out = append(out, current)
+		}
+		block = next
+		current.AddAttribute(dr.attribute(field, namespace, child(path, int32(i)), nil))
+	}
+	return out
+}
+
+func (dr *descriptorReader) enum(e *descriptorpb.EnumDescriptorProto, namespace string, path []int32) *Enum {
+	out := NewEnum(Join(Period, namespace, e.GetName()), e.GetName(), Empty)
+	out.Location = dr.location(path)
+	out.Comments = dr.comments(path)
+	out.Comment = out.Comments.Render(CommentsAll)
+	out.Options = dr.options(e.GetOptions(), child(path, enumOptionsPath))
+	for _, o := range out.Options {
+		if IsFeature(o.Name) {
+			out.Features = SetFeature(out.Features, o.Name, o.Value)
+		}
+	}
+	for i, v := range e.GetValue() {
+		valuePath := child(path, enumValuesPath, int32(i))
+		value := NewEnumValue(out.Qualifier, strconv.Itoa(int(v.GetNumber())), v.GetName(), Empty)
+		value.Location = dr.location(valuePath)
+		value.Comments = dr.comments(valuePath)
+		value.Comment = value.Comments.Render(CommentsAll)
+		value.Options = dr.options(v.GetOptions(), child(valuePath, enumValueOptionsPath))
+		out.Values = append(out.Values, value)
+	}
+	ranges := make([]*Range, 0)
+	for _, r := range e.GetReservedRange() {
+		ranges = append(ranges, &Range{Start: r.GetStart(), End: r.GetEnd(), Max: MaxEnumValue})
+	}
+	out.Reserved = dr.reserved(ranges, e.GetReservedName(),
+		child(path, enumReservedRangesPath), child(path, enumReservedNamesPath))
+	return out
+}
+
+// reserved converts the reserved ranges and names into their reserved
+// statements, whose locations are recorded at the paths of the ranges and
+// names. Without source information, each range is read as a statement and
+// the names as one statement.
+func (dr *descriptorReader) reserved(ranges []*Range, names []string, rangesPath []int32, namesPath []int32) []*Reserved {
+	out := make([]*Reserved, 0)
+	for i, r := range ranges {
+		reserved := dr.reservedStatement(&out, rangesPath, i, false)
+		reserved.Ranges = append(reserved.Ranges, r)
+	}
+	for i, name := range names {
+		reserved := dr.reservedStatement(&out, namesPath, i, i > 0)
+		reserved.Names = append(reserved.Names, name)
+	}
+	return out
+}
+
+// reservedStatement returns the statement declaring the element at the index
+// of the path, the last statement of out or a new one. Without source
+// information, the last statement is returned when merge is set.
+func (dr *descriptorReader) reservedStatement(out *[]*Reserved, path []int32, index int, merge bool) *Reserved {
+	statements := dr.locations[pathKey(path)]
+	location := dr.location(child(path, int32(index)))
+	statement := -1
+	for statement+1 < len(statements) && !location.Start.Before(dr.spanLocation(statements[statement+1]).Start) {
+		statement++
+	}
+	if statement < 0 && merge {
+		return (*out)[len(*out)-1]
+	}
+	if statement >= 0 && index > 0 && (*out)[len(*out)-1].Location == dr.spanLocation(statements[statement]) {
+		return (*out)[len(*out)-1]
+	}
+	reserved := &Reserved{Ranges: make([]*Range, 0), Names: make([]string, 0)}
+	if statement >= 0 {
+		reserved.Location = dr.spanLocation(statements[statement])
+		reserved.Comments = locationComments(statements[statement])
+		reserved.Comment = reserved.Comments.Render(CommentsAll)
+	}
+	*out = append(*out, reserved)
+	return reserved
+}
+
+func (dr *descriptorReader) service(s *descriptorpb.ServiceDescriptorProto, namespace string, path []int32) *Service {
+	out := NewService(namespace, s.GetName(), Empty)
+	out.Location = dr.location(path)
+	out.Comments = dr.comments(path)
+	out.Comment = out.Comments.Render(CommentsAll)
+	out.Options = dr.options(s.GetOptions(), child(path, serviceOptionsPath))
+	qualifier := Join(Period, namespace, out.Name)
+	for i, m := range s.GetMethod() {
+		methodPath := child(path, serviceMethodsPath, int32(i))
+		rpc := NewRpc(qualifier, m.GetName(), Empty)
+		rpc.Location = dr.location(methodPath)
+		rpc.Comments = dr.comments(methodPath)
+		rpc.Comment = rpc.Comments.Render(CommentsAll)
+		rpc.AddInputParameter(NewParameter(m.GetClientStreaming(), QualifiedName(m.GetInputType())))
+		rpc.AddReturnParameter(NewParameter(m.GetServerStreaming(), QualifiedName(m.GetOutputType())))
+		for _, o := range dr.options(m.GetOptions(), child(methodPath, methodOptionsPath)) {
+			option := NewRpcOption(Join(Period, qualifier, rpc.Name), o.Name, o.Comment, o.Constant.String())
+			option.Location = o.Location
+			option.Comments = o.Comments
+			rpc.Options = append(rpc.Options, option)
+		}
+		out.Methods = append(out.Methods, rpc)
+	}
+	return out
+}
+
+// options converts the set fields of an options message, custom options are
+// named with their extension in parentheses and the features are read as
+// `features.name` options. Repeated options are read as one option per value.
+// The options are ordered by field number, or by location in the source.
+func (dr *descriptorReader) options(options protobuf.Message, path []int32) []*Option {
+	out := make([]*Option, 0)
+	if options == nil || !options.ProtoReflect().IsValid() {
+		return out
+	}
+	m := dr.resolveExtensions(options).ProtoReflect()
+	for _, f := range sortedFields(m) {
+		fd, v := f.fd, f.v
+		name := string(fd.Name())
+		if fd.IsExtension() {
+			name = "(" + string(fd.FullName()) + ")"
+		}
+		fieldPath := child(path, int32(fd.Number()))
+		add := func(name string, constant string) {
+			option := NewOption(name, constant, Empty)
+			option.Location = dr.location(fieldPath)
+			option.Comments = dr.comments(fieldPath)
+			option.Comment = option.Comments.Render(CommentsAll)
+			out = append(out, option)
+		}
+		switch {
+		case name == "features" && fd.Kind() == protoreflect.MessageKind:
+			for _, feature := range sortedFields(v.Message()) {
+				add(PrefixFeatures+string(feature.fd.Name()), formatOptionValue(feature.fd, feature.v))
+			}
+		case fd.IsList():
+			for i := 0; i < v.List().Len(); i++ {
+				add(name, formatOptionValue(fd, v.List().Get(i)))
+			}
+		default:
+			add(name, formatOptionValue(fd, v))
+		}
+	}
+	// The options are declared in the order of their source locations
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i].Location, out[j].Location
+		return a.IsValid() && b.IsValid() && a.Start.Before(b.Start)
+	})
+	return out
+}
+
+// resolveExtensions reads the custom options held as unknown fields with the
+// extensions of the descriptor set.
+func (dr *descriptorReader) resolveExtensions(options protobuf.Message) protobuf.Message {
+	if dr.types == nil || len(options.ProtoReflect().GetUnknown()) == 0 {
+		return options
+	}
+	data, err := protobuf.Marshal(options)
+	if err != nil {
+		return options
+	}
+	out := options.ProtoReflect().New().Interface()
+	if err := (protobuf.UnmarshalOptions{Resolver: dr.types}).Unmarshal(data, out); err != nil {
+		Log.Debugf("Custom options are not resolved: %v\n", err)
+		return options
+	}
+	return out
+}
+
+type optionField struct {
+	fd protoreflect.FieldDescriptor
+	v  protoreflect.Value
+}
+
+// sortedFields returns the set fields of a message by field number.
+func sortedFields(m protoreflect.Message) []optionField {
+	out := make([]optionField, 0)
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		out = append(out, optionField{fd: fd, v: v})
+		return true
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].fd.Number() < out[j].fd.Number() })
+	return out
+}
+
+// formatOptionValue formats a value as an option constant of the protobuf
+// text format, e.g. `"text"`, `IMPLICIT` or `{ get: "/v1/books" }`.
+func formatOptionValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return strconv.Quote(v.String())
+	case protoreflect.BytesKind:
+		return strconv.Quote(string(v.Bytes()))
+	case protoreflect.EnumKind:
+		if value := fd.Enum().Values().ByNumber(v.Enum()); value != nil {
+			return string(value.Name())
+		}
+		return strconv.Itoa(int(v.Enum()))
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		fields := make([]string, 0)
+		for _, f := range sortedFields(v.Message()) {
+			fields = append(fields, formatOptionField(f.fd, f.v)...)
+		}
+		if len(fields) == 0 {
+			return OpenBrace + CloseBrace
+		}
+		return Join(Space, OpenBrace, strings.Join(fields, Space), CloseBrace)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// formatOptionField formats the `name: value` entries of a field of an
+// aggregate value.
+func formatOptionField(fd protoreflect.FieldDescriptor, v protoreflect.Value) []string {
+	name := fd.TextName()
+	if fd.IsExtension() {
+		name = "[" + string(fd.FullName()) + "]"
+	}
+	entry := func(value string) string {
+		if strings.HasPrefix(value, OpenBrace) {
+			return name + Space + value
+		}
+		return name + ": " + value
+	}
+	out := make([]string, 0)
+	switch {
+	case fd.IsList():
+		for i := 0; i < v.List().Len(); i++ {
+			out = append(out, entry(formatOptionValue(fd, v.List().Get(i))))
+		}
+	case fd.IsMap():
+		v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+			out = append(out, Join(Space, name, OpenBrace, "key: "+formatOptionValue(fd.MapKey(), k.Value()),
+				"value: "+formatOptionValue(fd.MapValue(), mv), CloseBrace))
+			return true
+		})
+		sort.Strings(out)
+	default:
+		out = append(out, entry(formatOptionValue(fd, v)))
+	}
+	return out
+}
+
+// relativizeTypes replaces the fully-qualified type names of the packages with
+// the shortest names resolving to the same types from their scope.
+func relativizeTypes(st *SymbolTable, packages []*Package) {
+	var messages func(messages []*Message)
+	attributes := func(attributes []*Attribute) {
+		for _, a := range attributes {
+			for i, kind := range a.Kind {
+				if !IsScalarType(kind) {
+					a.Kind[i] = strings.Replace(kind, strings.TrimSpace(kind), st.RelativeName(a.Qualifier, kind), 1)
+				}
+			}
+		}
+	}
+	extensions := func(extensions []*Extension) {
+		for _, e := range extensions {
+			attributes(e.Attributes)
+			e.Extendee = st.RelativeName(e.Qualifier, e.Extendee)
+			e.Name = e.Extendee
+		}
+	}
+	messages = func(in []*Message) {
+		for _, m := range in {
+			attributes(m.Attributes)
+			extensions(m.Extends)
+			messages(m.Messages)
+		}
+	}
+	for _, p := range packages {
+		messages(p.Messages)
+		extensions(p.Extends)
+		for _, s := range p.Services {
+			for _, rpc := range s.Methods {
+				for _, parameter := range append(append(make([]*Parameter, 0), rpc.InputParameters...), rpc.ReturnParameters...) {
+					parameter.Type = st.RelativeName(rpc.Qualifier, parameter.Type)
+				}
+			}
+		}
+	}
+}
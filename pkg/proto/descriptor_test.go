@@ -0,0 +1,191 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/encoding/protowire"
+	protobuf "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func readTestDescriptorSet(t *testing.T) *descriptorpb.FileDescriptorSet {
+	data, err := os.ReadFile("data/test/descriptor/library.textproto")
+	assert.Nil(t, err)
+	set := &descriptorpb.FileDescriptorSet{}
+	assert.Nil(t, prototext.Unmarshal(data, set))
+	return set
+}
+
+func TestReadDescriptorSet(t *testing.T) {
+	data, err := protobuf.Marshal(readTestDescriptorSet(t))
+	assert.Nil(t, err)
+	packages, err := ReadDescriptorSet(bytes.NewReader(data))
+	assert.Nil(t, err)
+	assert.Len(t, packages, 1)
+	assert.Empty(t, Link(packages...))
+
+	p := packages[0]
+	assert.Equal(t, "library.proto", p.Path)
+	assert.Equal(t, "test.library", p.Name)
+	assert.Equal(t, SyntaxProto3, p.Syntax)
+	assert.Equal(t, Comment("A library of books."), p.Comment)
+	assert.Equal(t, "example.com/test/library", p.Options[0].Value)
+
+	book := p.Messages[0]
+	assert.Equal(t, "test.library.Book", book.Qualifier)
+	assert.Equal(t, Location{File: "library.proto", Start: Position{Line: 9, Column: 1}, End: Position{Line: 25, Column: 1}}, book.Location)
+	assert.Empty(t, book.Messages)
+	assert.Len(t, book.Attributes, 6)
+	assert.Equal(t, Comment("The title of the book"), book.Attributes[0].Comment)
+	assert.Equal(t, []string{"Status"}, book.Attributes[1].Kind)
+	assert.Equal(t, "test.library.Book.Status", book.Attributes[1].Resolved[0].Name)
+	assert.True(t, book.Attributes[2].Map)
+	assert.Equal(t, []string{"string", " string"}, book.Attributes[2].Kind)
+	assert.True(t, book.Attributes[3].Optional)
+	assert.Len(t, book.Oneofs, 1)
+	assert.Equal(t, "format", book.Oneofs[0].Name)
+	assert.Len(t, book.Oneofs[0].Attributes, 2)
+	assert.Len(t, book.Reserved, 1)
+	assert.Equal(t, []*Range{{Start: 7, End: 9, Max: MaxFieldNumber}}, book.Reserved[0].Ranges)
+	assert.Equal(t, Comment("On the shelf"), book.Enums[0].Values[1].Comment)
+
+	rpc := p.Services[0].Methods[0]
+	assert.Equal(t, Comment("Returns a book."), rpc.Comment)
+	assert.False(t, rpc.InputParameters[0].Stream)
+	assert.True(t, rpc.ReturnParameters[0].Stream)
+	assert.Equal(t, "Book", rpc.ReturnParameters[0].Type)
+	assert.Equal(t, "deprecated", rpc.Options[0].Name)
+
+	_, err = ReadDescriptorSet(bytes.NewReader([]byte("not a descriptor set")))
+	assert.NotNil(t, err)
+}
+
+func TestDescriptorSetToPackages_Markdown(t *testing.T) {
+	// The markdown of a descriptor set is the markdown of its sources
+	file, err := os.Open("data/test/descriptor/library.proto")
+	assert.Nil(t, err)
+	defer file.Close()
+	parsed, err := ParseReader("library.proto", file)
+	assert.Nil(t, err)
+	assert.Empty(t, Link(parsed))
+	packages := DescriptorSetToPackages(readTestDescriptorSet(t))
+	assert.Empty(t, Link(packages...))
+
+	config := &WriterConfig{visualize: true}
+	assert.Equal(t, PackageToMarkDown(parsed, config), PackageToMarkDown(packages[0], config))
+}
+
+func TestDescriptorSetToPackages_Proto2(t *testing.T) {
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+		Name:    protobuf.String("search.proto"),
+		Package: protobuf.String("search"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: protobuf.String("Query"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:         protobuf.String("text"),
+				Number:       protobuf.Int32(1),
+				Label:        descriptorpb.FieldDescriptorProto_LABEL_REQUIRED.Enum(),
+				Type:         descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName:     protobuf.String("q"),
+				DefaultValue: protobuf.String("all"),
+			}, {
+				Name:   protobuf.String("page"),
+				Number: protobuf.Int32(2),
+				Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:   descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(),
+			}},
+			ExtensionRange: []*descriptorpb.DescriptorProto_ExtensionRange{{Start: protobuf.Int32(100), End: protobuf.Int32(200)}},
+		}},
+		Extension: []*descriptorpb.FieldDescriptorProto{{
+			Name:     protobuf.String("debug"),
+			Number:   protobuf.Int32(100),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_BOOL.Enum(),
+			Extendee: protobuf.String(".search.Query"),
+		}, {
+			Name:     protobuf.String("trace"),
+			Number:   protobuf.Int32(101),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			Extendee: protobuf.String(".search.Query"),
+		}},
+	}}}
+	p := DescriptorSetToPackages(set)[0]
+	assert.Equal(t, SyntaxProto2, p.Syntax)
+	assert.Nil(t, p.Comments)
+
+	text := p.Messages[0].Attributes[0]
+	assert.True(t, text.Required)
+	assert.Equal(t, `"all"`, text.Default)
+	assert.Equal(t, "json_name", text.Annotations[1].Name)
+	assert.Equal(t, `"q"`, text.Annotations[1].Value)
+	assert.True(t, p.Messages[0].Attributes[1].Optional)
+	assert.Equal(t, []*ExtensionRange{NewExtensionRange(100, 199)}, p.Messages[0].Extensions)
+
+	assert.Len(t, p.Extends, 1)
+	assert.Equal(t, "Query", p.Extends[0].Extendee)
+	assert.Len(t, p.Extends[0].Attributes, 2)
+}
+
+func TestDescriptorSetToPackages_Options(t *testing.T) {
+	// A custom option declared in the set, and the features of an edition
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+		Name:    protobuf.String("google/protobuf/descriptor.proto"),
+		Package: protobuf.String("google.protobuf"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name:           protobuf.String("MessageOptions"),
+			ExtensionRange: []*descriptorpb.DescriptorProto_ExtensionRange{{Start: protobuf.Int32(1000), End: protobuf.Int32(536870912)}},
+		}},
+	}, {
+		Name:       protobuf.String("custom.proto"),
+		Package:    protobuf.String("custom"),
+		Dependency: []string{"google/protobuf/descriptor.proto"},
+		Syntax:     protobuf.String("editions"),
+		Edition:    descriptorpb.Edition_EDITION_2023.Enum(),
+		Extension: []*descriptorpb.FieldDescriptorProto{{
+			Name:     protobuf.String("table"),
+			Number:   protobuf.Int32(50000),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			Extendee: protobuf.String(".google.protobuf.MessageOptions"),
+		}},
+		Options: &descriptorpb.FileOptions{Features: &descriptorpb.FeatureSet{
+			FieldPresence: descriptorpb.FeatureSet_IMPLICIT.Enum(),
+		}},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name:    protobuf.String("Row"),
+			Options: &descriptorpb.MessageOptions{},
+		}},
+	}}}
+	options := set.File[1].MessageType[0].Options
+	options.ProtoReflect().SetUnknown(protowire.AppendString(protowire.AppendTag(nil, 50000, protowire.BytesType), "rows"))
+
+	p := DescriptorSetToPackages(set)[1]
+	assert.Equal(t, "2023", p.Edition)
+	assert.Equal(t, "features.field_presence", p.Options[0].Name)
+	assert.Equal(t, "IMPLICIT", p.Options[0].Value)
+	assert.Equal(t, "IMPLICIT", p.Features.FieldPresence)
+	assert.Equal(t, "(custom.table)", p.Messages[0].Options[0].Name)
+	assert.Equal(t, "rows", p.Messages[0].Options[0].Value)
+	assert.Equal(t, "google.protobuf.MessageOptions", p.Extends[0].Extendee)
+}
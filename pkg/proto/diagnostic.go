@@ -0,0 +1,134 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity classifies a Diagnostic.
+type Severity int
+
+const (
+	// SeverityWarning is a problem that does not prevent reading the element,
+	// though it may be documented incorrectly.
+	SeverityWarning Severity = iota
+	// SeverityError is a problem causing the element, or part of it, to be
+	// skipped.
+	SeverityError
+)
+
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// Diagnostic is a problem found while reading a protobuf source. Element is
+// the qualified name of the element the problem was found in, if any.
+type Diagnostic struct {
+	Severity Severity
+	Message  string
+	Location Location
+	Element  string
+}
+
+// NewDiagnostic is the Diagnostic constructor.
+func NewDiagnostic(severity Severity, location Location, element string, message string) *Diagnostic {
+	return &Diagnostic{Severity: severity, Message: message, Location: location, Element: element}
+}
+
+// String formats the diagnostic as `file:line:column: severity: message`.
+func (d *Diagnostic) String() string {
+	out := fmt.Sprintf("%s: %s: %s", d.Location, d.Severity, d.Message)
+	if len(d.Element) > 0 {
+		out += fmt.Sprintf(" (%s)", d.Element)
+	}
+	return out
+}
+
+// Diagnostics is a list of diagnostics in the order they were found.
+type Diagnostics []*Diagnostic
+
+// Count returns the number of diagnostics with the given severity.
+func (d Diagnostics) Count(severity Severity) int {
+	count := 0
+	for _, diagnostic := range d {
+		if diagnostic.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// HasErrors determines if any diagnostic is an error.
+func (d Diagnostics) HasErrors() bool {
+	return d.Count(SeverityError) > 0
+}
+
+// Summary describes the number of errors and warnings, e.g. `2 errors, 1 warning`.
+func (d Diagnostics) Summary() string {
+	plural := func(count int, name string) string {
+		if count == 1 {
+			return fmt.Sprintf("%d %s", count, name)
+		}
+		return fmt.Sprintf("%d %ss", count, name)
+	}
+	return plural(d.Count(SeverityError), SeverityError.String()) + ", " +
+		plural(d.Count(SeverityWarning), SeverityWarning.String())
+}
+
+func (d Diagnostics) String() string {
+	lines := make([]string, 0, len(d))
+	for _, diagnostic := range d {
+		lines = append(lines, diagnostic.String())
+	}
+	return strings.Join(lines, EndL)
+}
+
+// Reporter is implemented by the scanners collecting the diagnostics of the
+// visitors.
+type Reporter interface {
+	Report(d *Diagnostic)
+	Diagnostics() Diagnostics
+}
+
+// Report adds a diagnostic for the line to the scanner, if the scanner is a
+// Reporter, the diagnostic is logged at debug level in any case.
+func Report(scanner Scanner, severity Severity, in *Line, element string, format string, args ...any) {
+	d := NewDiagnostic(severity, in.Location, element, fmt.Sprintf(format, args...))
+	Log.Debug(d.String())
+	if reporter, ok := scanner.(Reporter); ok {
+		reporter.Report(d)
+	}
+}
+
+// ReportUnrecognized reports a warning when none of the visitors can read the
+// line, lines without a token, e.g. blank lines, are ignored.
+func ReportUnrecognized(scanner Scanner, visitors []Visitor, in *Line, element string) {
+	if in.Token == Empty {
+		return
+	}
+	for _, visitor := range visitors {
+		if visitor.CanVisit(in) {
+			return
+		}
+	}
+	Report(scanner, SeverityWarning, in, element, "unrecognized statement `%s`", in.String())
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagnostic_String(t *testing.T) {
+	location := Location{File: "a.proto", Start: Position{Line: 3, Column: 5}}
+	tests := []struct {
+		name string
+		in   *Diagnostic
+		want string
+	}{
+		{name: "Error", in: NewDiagnostic(SeverityError, location, "test.A", "invalid field"), want: "a.proto:3:5: error: invalid field (test.A)"},
+		{name: "Warning", in: NewDiagnostic(SeverityWarning, location, Empty, "unrecognized"), want: "a.proto:3:5: warning: unrecognized"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, tt.in.String(), "String()")
+		})
+	}
+}
+
+func TestDiagnostics_Summary(t *testing.T) {
+	d := Diagnostics{
+		NewDiagnostic(SeverityError, Location{}, Empty, "a"),
+		NewDiagnostic(SeverityWarning, Location{}, Empty, "b"),
+		NewDiagnostic(SeverityError, Location{}, Empty, "c"),
+	}
+	assert.Equal(t, 2, d.Count(SeverityError))
+	assert.True(t, d.HasErrors())
+	assert.False(t, d[1:2].HasErrors())
+	assert.Equal(t, "2 errors, 1 warning", d.Summary())
+	assert.Equal(t, "0:0: error: a\n0:0: warning: b\n0:0: error: c", d.String())
+}
+
+func TestReport(t *testing.T) {
+	scanner := NewTestScanner(Empty)
+	line := &Line{Syntax: "string a = b", Token: Semicolon, Location: Location{File: "a.proto", Start: Position{Line: 1, Column: 1}}}
+	Report(scanner, SeverityError, line, "test", "invalid field `%s`", line.Syntax)
+	assert.Equal(t, Diagnostics{NewDiagnostic(SeverityError, line.Location, "test", "invalid field `string a = b`")}, scanner.Diagnostics())
+
+	ReportUnrecognized(scanner, RegisteredVisitors, NewLine("string a = 1;"), "test")
+	ReportUnrecognized(scanner, RegisteredVisitors, NewLine(Empty), "test")
+	assert.Len(t, scanner.Diagnostics(), 1)
+	ReportUnrecognized(scanner, RegisteredVisitors, NewLine("weird;"), "test")
+	assert.Len(t, scanner.Diagnostics(), 2)
+	assert.Equal(t, SeverityWarning, scanner.Diagnostics()[1].Severity)
+}
+
+func TestParseString_MalformedDiagnostics(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "Rpc Without Types", in: "package test;\nmessage A {}\nservice S {\n  rpc A(stream) returns (stream);\n  rpc B(A) returns (A);\n}",
+			want: "a.proto:4:3: error: invalid rpc `rpc A(stream) returns (stream)` (test.S)"},
+		{name: "Rpc Block Without Types", in: "package test;\nservice S {\n  rpc A() returns (stream) {\n    option deprecated = true;\n  }\n  rpc B(A) returns (A);\n}",
+			want: "a.proto:3:3: error: invalid rpc `rpc A() returns (stream)` (test.S)"},
+		{name: "Map With Three Types", in: "package test;\nmessage A {\n  map<string, B, C> m = 1;\n  string b = 2;\n}",
+			want: "a.proto:3:3: error: invalid map field `map<string, B, C> m = 1` (test.A)"},
+		{name: "Package Without Name", in: "package;\nmessage A {\n  string b = 2;\n}",
+			want: "a.proto:1:1: error: invalid package `package`"},
+		{name: "Service Without Name", in: "service {\n  rpc A(B) returns (B);\n}\nmessage A {\n  string b = 2;\n}",
+			want: "a.proto:1:1: error: invalid service `service`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParseString("a.proto", tt.in)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, p.Diagnostics.String())
+			// The rest of the file is read
+			for _, m := range p.Messages {
+				for _, a := range m.Attributes {
+					assert.Equal(t, "b", a.Name)
+				}
+			}
+			for _, s := range p.Services {
+				assert.Len(t, s.Methods, 1)
+				assert.Equal(t, "B", s.Methods[0].Name)
+			}
+		})
+	}
+}
@@ -19,7 +19,10 @@ package proto
 // Enum represents a Proto Enum type.
 type Enum struct {
 	*Qualified
-	Values []*EnumValue
+	Values   []*EnumValue
+	Reserved []*Reserved
+	Options  []*Option
+	Features *Features
 }
 
 // NewEnum is the Enum Constructor
@@ -30,6 +33,13 @@ func NewEnum(q string, name string, comment Comment) *Enum {
 			Name:      name,
 			Comment:   comment,
 		},
-		Values: make([]*EnumValue, 0),
+		Values:   make([]*EnumValue, 0),
+		Reserved: make([]*Reserved, 0),
+		Options:  make([]*Option, 0),
 	}
 }
+
+// IsValid implements the Validatable interface
+func (e *Enum) IsValid() bool {
+	return len(e.Name) > 0
+}
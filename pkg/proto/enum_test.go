@@ -39,7 +39,9 @@ func TestNewEnum(t *testing.T) {
 				Name:      "TEST",
 				Comment:   "Test",
 			},
-			Values: []*EnumValue{},
+			Values:   []*EnumValue{},
+			Reserved: []*Reserved{},
+			Options:  []*Option{},
 		}},
 	}
 	for _, tt := range tests {
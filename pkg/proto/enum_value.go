@@ -22,9 +22,12 @@ type EnumValue struct {
 	Ordinal   int
 	Value     string
 	Comment   Comment
+	Comments  *Comments
+	Options   []*Option
+	Location  Location
 }
 
 // NewEnumValue is the EnumValue constructor
 func NewEnumValue(namespace string, ordinal string, value string, comment Comment) *EnumValue {
-	return &EnumValue{Namespace: namespace, Ordinal: ParseOrdinal(ordinal), Value: value, Comment: comment}
+	return &EnumValue{Namespace: namespace, Ordinal: ParseOrdinal(ordinal), Value: value, Comment: comment, Options: make([]*Option, 0)}
 }
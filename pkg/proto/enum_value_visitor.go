@@ -16,18 +16,45 @@
 
 package proto
 
+import "strings"
+
 // EnumValueVisitor is responsible evaluating and processing Protobuf Enumerations.
 type EnumValueVisitor struct {
 }
 
-// CanVisit determines if the line is an enumeration.
+// CanVisit determines if the line is an enumeration, e.g. `NAME = 1;`, with optional
+// compact options `NAME = 1 [deprecated = true];`.
 func (evv EnumValueVisitor) CanVisit(in *Line) bool {
-	a := in.SplitSyntax()
-	return a != nil && len(a) == 3 && in.Token == Semicolon
+	a := enumValueDeclaration(in)
+	return a != nil && len(a) == 3 && a[1] == "=" && (in.Token == Semicolon || IsOpenAnnotation(in)) &&
+		a[0] != PrefixReserved && a[0] != PrefixOption
 }
 
 // Visit marshals a line into an enumeration
-func (evv EnumValueVisitor) Visit(_ Scanner, in *Line, namespace string) interface{} {
-	a := in.SplitSyntax()
-	return NewEnumValue(namespace, a[2], a[0], in.Comment)
+func (evv EnumValueVisitor) Visit(scanner Scanner, in *Line, namespace string) interface{} {
+	if IsOpenAnnotation(in) {
+		in = ReadStatement(scanner, in)
+	}
+	a := enumValueDeclaration(in)
+	if !IsNumeric(a[2]) {
+		Report(scanner, SeverityError, in, namespace, "invalid number `%s` of enum value %s", a[2], a[0])
+	}
+	out := NewEnumValue(namespace, a[2], a[0], in.Comment)
+	out.Options = ParseCompactOptions(in.Syntax)
+	out.Location = in.Location
+	out.Comments = in.Comments
+	for _, o := range out.Options {
+		o.Location = in.Location
+	}
+	return out
+}
+
+// enumValueDeclaration splits the name, equals and ordinal of an enum value
+// from its compact options.
+func enumValueDeclaration(in *Line) []string {
+	declaration := in.Syntax
+	if i := strings.Index(declaration, OpenBracket); i >= 0 {
+		declaration = declaration[:i]
+	}
+	return strings.Fields(declaration)
 }
@@ -36,6 +36,22 @@ func TestEnumValueVisitor_CanVisit(t *testing.T) {
 			Token:   ";",
 			Comment: "A residential address",
 		}}, want: true},
+		{name: "Test Enum Value With Options", args: args{in: &Line{
+			Syntax: "HARDBACK = 1 [deprecated = true]",
+			Token:  ";",
+		}}, want: true},
+		{name: "Test Enum Value With Aggregate Option", args: args{in: &Line{
+			Syntax: "EBOOK = 2 [(test.label) =",
+			Token:  "{",
+		}}, want: true},
+		{name: "Test Not Enum Option", args: args{in: &Line{
+			Syntax: "option allow_alias = true",
+			Token:  ";",
+		}}, want: false},
+		{name: "Test Not Enum Reserved", args: args{in: &Line{
+			Syntax: "reserved 2, 3",
+			Token:  ";",
+		}}, want: false},
 		{name: "Test Not Enum Value", args: args{in: &Line{
 			Syntax:  "message Address",
 			Token:   "{",
@@ -66,6 +82,15 @@ func TestEnumValueVisitor_Visit(t *testing.T) {
 			Token:   ";",
 			Comment: "A residential address",
 		}, namespace: "test"}, want: NewEnumValue("test", "0", "RESIDENTIAL", "A residential address")},
+		{name: "Test Visitor With Options", args: args{in0: nil, in: &Line{
+			Syntax: "HARDBACK = 1 [deprecated = true, (test.label) = 'Hard']",
+			Token:  ";",
+		}, namespace: "test"}, want: &EnumValue{
+			Namespace: "test",
+			Ordinal:   1,
+			Value:     "HARDBACK",
+			Options:   []*Option{NewOption("deprecated", "true", ""), NewOption("(test.label)", "'Hard'", "")},
+		}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -74,3 +99,14 @@ func TestEnumValueVisitor_Visit(t *testing.T) {
 		})
 	}
 }
+
+func TestEnumValueVisitor_VisitAggregateOption(t *testing.T) {
+	scanner := NewTestScanner(`text: "E-Book" }
+];`)
+	evv := EnumValueVisitor{}
+	out := evv.Visit(scanner, &Line{Syntax: "EBOOK = 2 [(test.label) =", Token: OpenBrace}, "test").(*EnumValue)
+	assert.Equal(t, "EBOOK", out.Value)
+	assert.Equal(t, 2, out.Ordinal)
+	assert.Len(t, out.Options, 1)
+	assert.Equal(t, "E-Book", out.Options[0].Constant.Get("text").Value)
+}
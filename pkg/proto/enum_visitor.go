@@ -26,6 +26,8 @@ func NewEnumVisitor() *EnumVisitor {
 	out := &EnumVisitor{Visitors: make([]Visitor, 0)}
 	out.Visitors = append(out.Visitors,
 		&CommentVisitor{},
+		&OptionVisitor{},
+		&ReservedVisitor{Max: MaxEnumValue},
 		&EnumValueVisitor{})
 	return out
 }
@@ -45,6 +47,8 @@ func (ev *EnumVisitor) Visit(scanner Scanner, in *Line, namespace string) interf
 	Log.Debugf("Visiting Enum: %d registered Visitors\n", len(ev.Visitors))
 	fValues := in.SplitSyntax()
 	out := NewEnum(Join(Period, namespace, fValues[1]), fValues[1], in.Comment)
+	out.Location = in.Location
+	out.Comments = in.Comments
 
 	var comment = Comment(Empty)
 
@@ -53,6 +57,7 @@ func (ev *EnumVisitor) Visit(scanner Scanner, in *Line, namespace string) interf
 		if strings.HasSuffix(n.Token, CloseBrace) {
 			break
 		}
+		ReportUnrecognized(scanner, ev.Visitors, n, out.Qualifier)
 		for _, visitor := range ev.Visitors {
 			if visitor.CanVisit(n) {
 				rt := visitor.Visit(
@@ -65,6 +70,17 @@ func (ev *EnumVisitor) Visit(scanner Scanner, in *Line, namespace string) interf
 					t.Comment = comment.AddSpace().Append(t.Comment).TrimSpace()
 					out.Values = append(out.Values, t)
 					comment = comment.Clear()
+				case *Reserved:
+					t.Comment = comment.AddSpace().Append(t.Comment).TrimSpace()
+					out.Reserved = append(out.Reserved, t)
+					comment = comment.Clear()
+				case *Option:
+					t.Comment = comment.AddSpace().Append(t.Comment).TrimSpace()
+					out.Options = append(out.Options, t)
+					if IsFeature(t.Name) {
+						out.Features = SetFeature(out.Features, t.Name, t.Value)
+					}
+					comment = comment.Clear()
 				case Comment:
 					comment = comment.Append(t).AddSpace()
 				default:
@@ -117,3 +117,14 @@ func TestNewEnumVisitor(t *testing.T) {
 		})
 	}
 }
+
+func TestEnumVisitor_VisitReserved(t *testing.T) {
+	scanner := NewTestScanner("T1 = 0;\n// Removed values\nreserved 2, 15, 9 to 11, 40 to max;\nreserved \"FOO\";\n}")
+	out := NewEnumVisitor().Visit(scanner, NewLine("enum Test {"), "test").(*Enum)
+	assert.Len(t, out.Values, 1)
+	assert.Len(t, out.Reserved, 2)
+	assert.Equal(t, Comment("Removed values"), out.Reserved[0].Comment)
+	assert.Equal(t, "40 to max", out.Reserved[0].Ranges[3].String())
+	assert.Equal(t, int32(MaxEnumValue), out.Reserved[0].Ranges[3].End)
+	assert.Equal(t, []string{"FOO"}, out.Reserved[1].Names)
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"strings"
+)
+
+// NewExtendVisitor creates an ExtendVisitor
+func NewExtendVisitor() *ExtendVisitor {
+	Log.Debug("Initializing ExtendVisitor")
+	out := &ExtendVisitor{Visitors: make([]Visitor, 0)}
+	out.Visitors = append(out.Visitors,
+		&CommentVisitor{},
+		&GroupVisitor{},
+		NewAttributeVisitor())
+	return out
+}
+
+// ExtendVisitor is responsible for evaluation and marshalling of an extend block.
+type ExtendVisitor struct {
+	Visitors []Visitor
+}
+
+// CanVisit determines if the current line starts an extend block.
+func (ev *ExtendVisitor) CanVisit(in *Line) bool {
+	return strings.HasPrefix(in.Syntax, "extend ") && in.Token == OpenBrace
+}
+
+// Visit marshals the extend block and its attributes until the closed brace is evaluated.
+func (ev *ExtendVisitor) Visit(scanner Scanner, in *Line, namespace string) interface{} {
+	Log.Debugf("Visiting Extend: %v\n", in)
+	values := in.SplitSyntax()
+	out := NewExtension(namespace, values[1], in.Comment)
+	out.Location = in.Location
+	out.Comments = in.Comments
+
+	var comment = Comment(Empty)
+
+	for scanner.Scan() {
+		line := scanner.ReadLine()
+		if strings.HasSuffix(line.Token, CloseBrace) {
+			break
+		}
+		for _, visitor := range ev.Visitors {
+			if visitor.CanVisit(line) {
+				rt := visitor.Visit(scanner, line, namespace)
+				switch t := rt.(type) {
+				case *Attribute:
+					if t.IsValid() {
+						t.Comment = comment.AddSpace().Append(t.Comment).TrimSpace()
+						out.AddAttribute(t)
+						comment = comment.Clear()
+					}
+				case *Group:
+					t.Attribute.Comment = comment.AddSpace().Append(t.Attribute.Comment).TrimSpace()
+					t.Message.Comment = t.Attribute.Comment
+					out.AddAttribute(t.Attribute)
+					out.Messages = append(out.Messages, t.Message)
+					comment = comment.Clear()
+				case Comment:
+					comment = comment.Append(t).AddSpace()
+				}
+			}
+		}
+	}
+	return out
+}
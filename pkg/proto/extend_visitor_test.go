@@ -0,0 +1,71 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtendVisitor_CanVisit(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *Line
+		want bool
+	}{
+		{name: "Extend", in: NewLine("extend google.protobuf.FieldOptions {"), want: true},
+		{name: "Extension Range", in: NewLine("extensions 100 to 199;"), want: false},
+		{name: "Attribute", in: NewLine("string extend = 1;"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev := NewExtendVisitor()
+			assert.Equalf(t, tt.want, ev.CanVisit(tt.in), "CanVisit(%v)", tt.in)
+		})
+	}
+}
+
+func TestExtendVisitor_Visit(t *testing.T) {
+	scanner := NewTestScanner(`// Marks a field as sensitive
+optional bool sensitive = 50000;
+optional string owner = 50001; // The owning team
+}`)
+	ev := NewExtendVisitor()
+	out, ok := ev.Visit(scanner, NewLine("extend google.protobuf.FieldOptions { // Custom options"), "test").(*Extension)
+	assert.True(t, ok)
+	assert.Equal(t, "google.protobuf.FieldOptions", out.Extendee)
+	assert.Equal(t, Comment("Custom options"), out.Comment)
+	assert.Len(t, out.Attributes, 2)
+	assert.Equal(t, "sensitive", out.Attributes[0].Name)
+	assert.Equal(t, 50000, out.Attributes[0].Ordinal)
+	assert.Equal(t, Comment("Marks a field as sensitive"), out.Attributes[0].Comment)
+	assert.Equal(t, "owner", out.Attributes[1].Name)
+	assert.Equal(t, Comment("The owning team"), out.Attributes[1].Comment)
+}
+
+func TestPackage_ReadExtensions(t *testing.T) {
+	p := NewPackage("data/test/extend/options.proto")
+	diagnostics, err := p.Read(false)
+	assert.Nil(t, err)
+	assert.Empty(t, diagnostics)
+	assert.Len(t, p.Extends, 1)
+	assert.Equal(t, Comment("Company wide field options"), p.Extends[0].Comment)
+	assert.Len(t, p.Messages[1].Extends, 1)
+	assert.Equal(t, "Resource", p.Messages[1].Extends[0].Extendee)
+	assert.Len(t, CollectExtensions(p), 2)
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+// Extension is an `extend` block, adding attributes to the Extendee message,
+// typically one of the google.protobuf.*Options messages for custom options.
+type Extension struct {
+	*Qualified
+	Extendee string
+	// Resolved is the declaration of the extended message, set by Link.
+	Resolved   *Symbol
+	Attributes []*Attribute
+	// Messages are the nested messages of proto2 groups declared in the block.
+	Messages []*Message
+}
+
+// NewExtension is the Extension constructor
+func NewExtension(namespace string, extendee string, comment Comment) *Extension {
+	return &Extension{
+		Qualified: &Qualified{
+			Qualifier: namespace,
+			Name:      extendee,
+			Comment:   comment,
+		},
+		Extendee:   extendee,
+		Attributes: make([]*Attribute, 0),
+		Messages:   make([]*Message, 0),
+	}
+}
+
+// AddAttribute adds attributes to the extension.
+func (e *Extension) AddAttribute(attributes ...*Attribute) {
+	e.Attributes = append(e.Attributes, attributes...)
+}
@@ -0,0 +1,34 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+// ExtensionRange is a proto2 range of field numbers reserved for extensions,
+// declared with `extensions 100 to 199;`.
+type ExtensionRange struct {
+	Start int32
+	End   int32
+}
+
+// NewExtensionRange is the ExtensionRange constructor
+func NewExtensionRange(start int32, end int32) *ExtensionRange {
+	return &ExtensionRange{Start: start, End: end}
+}
+
+// String formats the range as it would be declared in the protobuf source.
+func (er *ExtensionRange) String() string {
+	return (&Range{Start: er.Start, End: er.End, Max: MaxFieldNumber}).String()
+}
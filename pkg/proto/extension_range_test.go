@@ -0,0 +1,44 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewExtensionRange(t *testing.T) {
+	assert.Equal(t, &ExtensionRange{Start: 100, End: 199}, NewExtensionRange(100, 199))
+}
+
+func TestExtensionRange_String(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *ExtensionRange
+		want string
+	}{
+		{name: "Single", in: NewExtensionRange(4, 4), want: "4"},
+		{name: "Range", in: NewExtensionRange(100, 199), want: "100 to 199"},
+		{name: "Max", in: NewExtensionRange(1000, MaxFieldNumber), want: "1000 to max"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, tt.in.String(), "String()")
+		})
+	}
+}
@@ -0,0 +1,47 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"strings"
+)
+
+// ExtensionRangeVisitor reads proto2 `extensions` declarations in a message.
+type ExtensionRangeVisitor struct {
+}
+
+// CanVisit determines if the line is an extension range declaration.
+func (erv *ExtensionRangeVisitor) CanVisit(in *Line) bool {
+	return strings.HasPrefix(in.Syntax, PrefixExtensions+Space) && in.Token == Semicolon
+}
+
+// Visit marshals the line into one ExtensionRange per comma separated range,
+// e.g. `extensions 4, 20 to max;`.
+func (erv *ExtensionRangeVisitor) Visit(_ Scanner, in *Line, _ string) interface{} {
+	Log.Debug("Visiting Extension Range")
+	out := make([]*ExtensionRange, 0)
+	body := strings.TrimPrefix(in.Syntax, PrefixExtensions)
+	if strings.Contains(body, OpenBracket) {
+		body = body[:strings.Index(body, OpenBracket)]
+	}
+	for _, r := range strings.Split(body, Comma) {
+		if rng, ok := ParseRange(r, MaxFieldNumber); ok {
+			out = append(out, NewExtensionRange(rng.Start, rng.End))
+		}
+	}
+	return out
+}
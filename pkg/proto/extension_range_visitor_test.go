@@ -0,0 +1,60 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtensionRangeVisitor_CanVisit(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *Line
+		want bool
+	}{
+		{name: "Can Visit", in: NewLine("extensions 100 to 199;"), want: true},
+		{name: "Can't Visit Attribute", in: NewLine("string extensions = 1;"), want: false},
+		{name: "Can't Visit Comment", in: NewLine("// extensions 100 to 199;"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			erv := &ExtensionRangeVisitor{}
+			assert.Equalf(t, tt.want, erv.CanVisit(tt.in), "CanVisit(%v)", tt.in)
+		})
+	}
+}
+
+func TestExtensionRangeVisitor_Visit(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *Line
+		want []*ExtensionRange
+	}{
+		{name: "Range", in: NewLine("extensions 100 to 199;"),
+			want: []*ExtensionRange{NewExtensionRange(100, 199)}},
+		{name: "List and Max", in: NewLine("extensions 4, 20 to max;"),
+			want: []*ExtensionRange{NewExtensionRange(4, 4), NewExtensionRange(20, MaxFieldNumber)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			erv := &ExtensionRangeVisitor{}
+			assert.Equalf(t, tt.want, erv.Visit(nil, tt.in, "test"), "Visit(%v)", tt.in)
+		})
+	}
+}
@@ -0,0 +1,275 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Feature names and values, see google/protobuf/descriptor.proto FeatureSet.
+const (
+	FeatureFieldPresence         = "field_presence"
+	FeatureEnumType              = "enum_type"
+	FeatureRepeatedFieldEncoding = "repeated_field_encoding"
+	FeatureUtf8Validation        = "utf8_validation"
+	FeatureMessageEncoding       = "message_encoding"
+	FeatureJsonFormat            = "json_format"
+
+	FieldPresenceExplicit       = "EXPLICIT"
+	FieldPresenceImplicit       = "IMPLICIT"
+	FieldPresenceLegacyRequired = "LEGACY_REQUIRED"
+	EnumTypeOpen                = "OPEN"
+	EnumTypeClosed              = "CLOSED"
+	RepeatedFieldEncodingPacked = "PACKED"
+	RepeatedFieldEncodingExpand = "EXPANDED"
+	Utf8ValidationVerify        = "VERIFY"
+	Utf8ValidationNone          = "NONE"
+	MessageEncodingLength       = "LENGTH_PREFIXED"
+	MessageEncodingDelimited    = "DELIMITED"
+	JsonFormatAllow             = "ALLOW"
+	JsonFormatLegacyBestEffort  = "LEGACY_BEST_EFFORT"
+)
+
+// Features is a protobuf editions feature set. Unset features are empty strings,
+// which allows a Features value to hold either the overrides declared on an
+// element, or a fully resolved set.
+type Features struct {
+	FieldPresence         string
+	EnumType              string
+	RepeatedFieldEncoding string
+	Utf8Validation        string
+	MessageEncoding       string
+	JsonFormat            string
+}
+
+// DefaultFeatures returns the feature defaults for the given syntax or edition,
+// proto2 and proto3 are treated as their equivalent legacy editions.
+func DefaultFeatures(syntax string, edition string) *Features {
+	if len(edition) > 0 {
+		return &Features{
+			FieldPresence:         FieldPresenceExplicit,
+			EnumType:              EnumTypeOpen,
+			RepeatedFieldEncoding: RepeatedFieldEncodingPacked,
+			Utf8Validation:        Utf8ValidationVerify,
+			MessageEncoding:       MessageEncodingLength,
+			JsonFormat:            JsonFormatAllow,
+		}
+	}
+	if syntax == SyntaxProto3 {
+		return &Features{
+			FieldPresence:         FieldPresenceImplicit,
+			EnumType:              EnumTypeOpen,
+			RepeatedFieldEncoding: RepeatedFieldEncodingPacked,
+			Utf8Validation:        Utf8ValidationVerify,
+			MessageEncoding:       MessageEncodingLength,
+			JsonFormat:            JsonFormatAllow,
+		}
+	}
+	return &Features{
+		FieldPresence:         FieldPresenceExplicit,
+		EnumType:              EnumTypeClosed,
+		RepeatedFieldEncoding: RepeatedFieldEncodingExpand,
+		Utf8Validation:        Utf8ValidationNone,
+		MessageEncoding:       MessageEncodingLength,
+		JsonFormat:            JsonFormatLegacyBestEffort,
+	}
+}
+
+// IsFeature determines if an option or annotation name sets a feature.
+func IsFeature(name string) bool {
+	return strings.HasPrefix(name, PrefixFeatures)
+}
+
+// SetFeature records an option such as `features.field_presence = IMPLICIT`
+// on the feature set, creating it if required. Unknown features are ignored.
+func SetFeature(f *Features, name string, value string) *Features {
+	if f == nil {
+		f = &Features{}
+	}
+	value = strings.TrimSpace(value)
+	switch strings.TrimPrefix(name, PrefixFeatures) {
+	case FeatureFieldPresence:
+		f.FieldPresence = value
+	case FeatureEnumType:
+		f.EnumType = value
+	case FeatureRepeatedFieldEncoding:
+		f.RepeatedFieldEncoding = value
+	case FeatureUtf8Validation:
+		f.Utf8Validation = value
+	case FeatureMessageEncoding:
+		f.MessageEncoding = value
+	case FeatureJsonFormat:
+		f.JsonFormat = value
+	default:
+		Log.Debugf("Unsupported feature %s", name)
+	}
+	return f
+}
+
+// Merge returns a copy of the feature set with the set values of other applied.
+func (f *Features) Merge(other *Features) *Features {
+	out := *f
+	if other == nil {
+		return &out
+	}
+	if len(other.FieldPresence) > 0 {
+		out.FieldPresence = other.FieldPresence
+	}
+	if len(other.EnumType) > 0 {
+		out.EnumType = other.EnumType
+	}
+	if len(other.RepeatedFieldEncoding) > 0 {
+		out.RepeatedFieldEncoding = other.RepeatedFieldEncoding
+	}
+	if len(other.Utf8Validation) > 0 {
+		out.Utf8Validation = other.Utf8Validation
+	}
+	if len(other.MessageEncoding) > 0 {
+		out.MessageEncoding = other.MessageEncoding
+	}
+	if len(other.JsonFormat) > 0 {
+		out.JsonFormat = other.JsonFormat
+	}
+	return &out
+}
+
+// IsScalarType determines if the kind is one of the protobuf scalar value types.
+func IsScalarType(kind string) bool {
+	for _, t := range strings.Split(Protobuf3Types, Comma) {
+		if t == strings.TrimSpace(kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPackableType determines if a repeated field of the kind may use the packed encoding.
+func IsPackableType(kind string) bool {
+	return IsScalarType(kind) && kind != "string" && kind != "bytes"
+}
+
+// ResolveFeatures computes the effective feature set of every attribute in the
+// package from the edition defaults and the file, message and field level
+// overrides. Enum typed attributes take the enum type of their enumeration,
+// found by its fully-qualified name from the scope of the attribute.
+//
+// The features set explicitly are resolved as well, they are all the features
+// of an editions file, but only the declared ones of a proto2 or proto3 file.
+func ResolveFeatures(p *Package) {
+	file := &resolvedFeatures{effective: DefaultFeatures(p.Syntax, p.Edition).Merge(p.Features), explicit: (&Features{}).Merge(p.Features)}
+	if len(p.Edition) > 0 {
+		file.explicit = file.effective
+	}
+	enums := make(map[string]*resolvedFeatures)
+	for _, e := range p.Enums {
+		collectEnumFeatures(e, file, enums)
+	}
+	for _, m := range p.Messages {
+		collectMessageEnumFeatures(m, file, enums)
+	}
+	for _, m := range p.Messages {
+		resolveMessageFeatures(m, file, enums)
+	}
+	for _, e := range p.Extends {
+		resolveAttributeFeatures(e.Attributes, file, enums)
+	}
+}
+
+// resolvedFeatures are the effective and the explicitly set features of a
+// declaration.
+type resolvedFeatures struct {
+	effective *Features
+	explicit  *Features
+}
+
+func (rf *resolvedFeatures) merge(declared *Features) *resolvedFeatures {
+	return &resolvedFeatures{effective: rf.effective.Merge(declared), explicit: rf.explicit.Merge(declared)}
+}
+
+func collectEnumFeatures(e *Enum, parent *resolvedFeatures, enums map[string]*resolvedFeatures) {
+	enums[QualifiedName(e.Qualifier)] = parent.merge(e.Features)
+}
+
+func collectMessageEnumFeatures(m *Message, parent *resolvedFeatures, enums map[string]*resolvedFeatures) {
+	resolved := parent.merge(m.Features)
+	for _, e := range m.Enums {
+		collectEnumFeatures(e, resolved, enums)
+	}
+	for _, n := range m.Messages {
+		collectMessageEnumFeatures(n, resolved, enums)
+	}
+}
+
+func resolveMessageFeatures(m *Message, parent *resolvedFeatures, enums map[string]*resolvedFeatures) {
+	resolved := parent.merge(m.Features)
+	resolveAttributeFeatures(m.Attributes, resolved, enums)
+	for _, e := range m.Extends {
+		resolveAttributeFeatures(e.Attributes, resolved, enums)
+	}
+	for _, n := range m.Messages {
+		resolveMessageFeatures(n, resolved, enums)
+	}
+}
+
+func resolveAttributeFeatures(attributes []*Attribute, parent *resolvedFeatures, enums map[string]*resolvedFeatures) {
+	for _, a := range attributes {
+		var declared *Features
+		for _, an := range a.Annotations {
+			value := fmt.Sprintf("%v", an.Value)
+			if IsFeature(an.Name) {
+				declared = SetFeature(declared, an.Name, value)
+			} else if an.Name == AnnotationPacked && value == "true" {
+				declared = SetFeature(declared, FeatureRepeatedFieldEncoding, RepeatedFieldEncodingPacked)
+			} else if an.Name == AnnotationPacked && value == "false" {
+				declared = SetFeature(declared, FeatureRepeatedFieldEncoding, RepeatedFieldEncodingExpand)
+			}
+		}
+		resolved := parent.merge(declared)
+		a.Features, a.ExplicitFeatures = resolved.effective, resolved.explicit
+		if a.Required {
+			a.Features.FieldPresence = FieldPresenceLegacyRequired
+		} else if a.Optional || len(a.Oneof) > 0 {
+			a.Features.FieldPresence = FieldPresenceExplicit
+		}
+
+		// The enum type only applies to enum typed attributes.
+		a.Features.EnumType, a.ExplicitFeatures.EnumType = Empty, Empty
+		if e := lookupEnumFeatures(enums, a.Qualifier, a.Kind[len(a.Kind)-1]); e != nil {
+			a.Features.EnumType, a.ExplicitFeatures.EnumType = e.effective.EnumType, e.explicit.EnumType
+		}
+	}
+}
+
+// lookupEnumFeatures returns the features of the enum of a type name used from
+// the scope, searched from the innermost scope outwards, or nil.
+func lookupEnumFeatures(enums map[string]*resolvedFeatures, scope string, kind string) *resolvedFeatures {
+	kind = strings.TrimSpace(kind)
+	if strings.HasPrefix(kind, Period) {
+		return enums[QualifiedName(kind)]
+	}
+	scope = QualifiedName(scope)
+	for {
+		if e, ok := enums[qualify(scope, kind)]; ok {
+			return e
+		}
+		if len(scope) == 0 {
+			return nil
+		}
+		scope = scope[:max(strings.LastIndex(scope, Period), 0)]
+	}
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultFeatures(t *testing.T) {
+	assert.Equal(t, FieldPresenceExplicit, DefaultFeatures(SyntaxProto2, "").FieldPresence)
+	assert.Equal(t, EnumTypeClosed, DefaultFeatures(SyntaxProto2, "").EnumType)
+	assert.Equal(t, FieldPresenceImplicit, DefaultFeatures(SyntaxProto3, "").FieldPresence)
+	assert.Equal(t, RepeatedFieldEncodingPacked, DefaultFeatures(SyntaxProto3, "").RepeatedFieldEncoding)
+	assert.Equal(t, FieldPresenceExplicit, DefaultFeatures("", "2023").FieldPresence)
+	assert.Equal(t, EnumTypeOpen, DefaultFeatures("", "2023").EnumType)
+}
+
+func TestSetFeature(t *testing.T) {
+	f := SetFeature(nil, "features.field_presence", "IMPLICIT")
+	assert.Equal(t, &Features{FieldPresence: FieldPresenceImplicit}, f)
+	f = SetFeature(f, "features.enum_type", "CLOSED")
+	assert.Equal(t, &Features{FieldPresence: FieldPresenceImplicit, EnumType: EnumTypeClosed}, f)
+}
+
+func TestFeatures_Merge(t *testing.T) {
+	base := DefaultFeatures(SyntaxProto3, "")
+	merged := base.Merge(&Features{FieldPresence: FieldPresenceExplicit})
+	assert.Equal(t, FieldPresenceExplicit, merged.FieldPresence)
+	assert.Equal(t, FieldPresenceImplicit, base.FieldPresence)
+	assert.Equal(t, base, base.Merge(nil))
+}
+
+func TestIsScalarType(t *testing.T) {
+	assert.True(t, IsScalarType("int32"))
+	assert.False(t, IsScalarType("int"))
+	assert.False(t, IsScalarType("Stringer"))
+	assert.True(t, IsPackableType("sfixed64"))
+	assert.False(t, IsPackableType("bytes"))
+}
+
+func TestResolveFeatures(t *testing.T) {
+	p := NewPackage("data/test/editions/inventory.proto")
+	diagnostics, err := p.Read(false)
+	assert.Nil(t, err)
+	assert.Empty(t, diagnostics)
+	assert.Equal(t, "2023", p.Edition)
+	assert.Equal(t, &Features{EnumType: EnumTypeClosed}, p.Features)
+
+	item := p.Messages[0]
+	assert.Equal(t, FieldPresenceExplicit, item.Attributes[0].Features.FieldPresence)
+	assert.Equal(t, FieldPresenceImplicit, item.Attributes[1].Features.FieldPresence)
+	assert.Equal(t, FieldPresenceLegacyRequired, item.Attributes[2].Features.FieldPresence)
+	assert.Equal(t, RepeatedFieldEncodingExpand, item.Attributes[3].Features.RepeatedFieldEncoding)
+	assert.Equal(t, EnumTypeOpen, item.Attributes[4].Features.EnumType)
+	assert.Equal(t, EnumTypeClosed, item.Attributes[5].Features.EnumType)
+	assert.Equal(t, Empty, item.Attributes[0].Features.EnumType)
+
+	counter := p.Messages[1]
+	assert.Equal(t, FieldPresenceImplicit, counter.Attributes[0].Features.FieldPresence)
+	assert.Equal(t, RepeatedFieldEncodingPacked, counter.Attributes[1].Features.RepeatedFieldEncoding)
+}
+
+func TestResolveFeatures_Proto3(t *testing.T) {
+	p := NewPackage("data/test/location/model.proto")
+	diagnostics, err := p.Read(false)
+	assert.Nil(t, err)
+	assert.Empty(t, diagnostics)
+	assert.Equal(t, SyntaxProto3, p.Syntax)
+	for _, a := range p.Messages[0].Attributes {
+		assert.Equal(t, FieldPresenceImplicit, a.Features.FieldPresence)
+	}
+}
+
+func TestResolveFeatures_NestedEnums(t *testing.T) {
+	p, err := ParseString("a.proto", `edition = "2023";
+package test;
+
+message A {
+  enum Kind {
+    option features.enum_type = CLOSED;
+    KIND_UNSPECIFIED = 0;
+  }
+  Kind kind = 1;
+}
+
+message B {
+  enum Kind {
+    KIND_UNSPECIFIED = 0;
+  }
+  Kind kind = 1;
+  A.Kind other = 2;
+  .test.B.Kind absolute = 3;
+}`)
+	assert.Nil(t, err)
+	// The enums of the same short name are found from the scope of the field
+	assert.Equal(t, EnumTypeClosed, p.Messages[0].Attributes[0].Features.EnumType)
+	assert.Equal(t, EnumTypeOpen, p.Messages[1].Attributes[0].Features.EnumType)
+	assert.Equal(t, EnumTypeClosed, p.Messages[1].Attributes[1].Features.EnumType)
+	assert.Equal(t, EnumTypeOpen, p.Messages[1].Attributes[2].Features.EnumType)
+}
+
+func TestResolveFeatures_Explicit(t *testing.T) {
+	p, err := ParseString("a.proto", `syntax = "proto3";
+package test;
+
+message A {
+  repeated int32 implicit = 1;
+  repeated int32 expanded = 2 [packed = false];
+}`)
+	assert.Nil(t, err)
+	implicit, expanded := p.Messages[0].Attributes[0], p.Messages[0].Attributes[1]
+	assert.Equal(t, RepeatedFieldEncodingPacked, implicit.Features.RepeatedFieldEncoding)
+	assert.Equal(t, &Features{}, implicit.ExplicitFeatures)
+	assert.Equal(t, RepeatedFieldEncodingExpand, expanded.ExplicitFeatures.RepeatedFieldEncoding)
+	// The proto3 defaults are not reported
+	assert.Equal(t, "Repeated", AttributeLabel(implicit))
+	assert.Equal(t, "Repeated (Expanded)", AttributeLabel(expanded))
+}
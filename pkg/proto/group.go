@@ -0,0 +1,29 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+// Group is a proto2 group field, which declares both a field and a nested
+// message with the same name in a single statement.
+type Group struct {
+	Attribute *Attribute
+	Message   *Message
+}
+
+// NewGroup is the Group constructor
+func NewGroup(attribute *Attribute, message *Message) *Group {
+	return &Group{Attribute: attribute, Message: message}
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"strings"
+)
+
+// GroupVisitor is used for interpreting proto2 group fields, e.g.
+// `repeated group Result = 1 { ... }`.
+type GroupVisitor struct {
+}
+
+// IsGroup determines if the split syntax declares a group, with or without a label.
+func IsGroup(split []string) bool {
+	return (len(split) >= 4 && split[0] == PrefixGroup) ||
+		(len(split) >= 5 && split[1] == PrefixGroup)
+}
+
+// CanVisit visits if the line declares a group and ends with an open brace '{'
+func (gv *GroupVisitor) CanVisit(in *Line) bool {
+	return in.Token == OpenBrace && IsGroup(in.SplitSyntax())
+}
+
+// Visit reads the group field and the body of its message until the closed brace
+// is evaluated.
+func (gv *GroupVisitor) Visit(scanner Scanner, in *Line, namespace string) interface{} {
+	Log.Debugf("Visiting Group: %v\n", in)
+	split := in.SplitSyntax()
+
+	attribute := NewAttribute(namespace, in.Comment)
+	attribute.Location = in.Location
+	attribute.Comments = in.Comments
+	attribute.Group = true
+	attribute.Annotations = ParseAnnotations(in.Syntax)
+	switch split[0] {
+	case PrefixRepeated:
+		attribute.Repeated = true
+	case PrefixOptional:
+		attribute.Optional = true
+	case PrefixRequired:
+		attribute.Required = true
+	}
+	if split[0] != PrefixGroup {
+		split = split[1:]
+	}
+	// 0 - 3 group, name, equals, ordinal
+	attribute.Kind = append(attribute.Kind, split[1])
+	attribute.Name = strings.ToLower(split[1])
+	attribute.Ordinal = ParseOrdinal(split[3])
+
+	mv := &MessageVisitor{}
+	message := mv.Visit(scanner, &Line{Syntax: Join(Space, "message", split[1]), Token: OpenBrace}, namespace).(*Message)
+	message.Location = in.Location
+	message.Comments = in.Comments
+	return NewGroup(attribute, message)
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupVisitor_CanVisit(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *Line
+		want bool
+	}{
+		{name: "Labeled Group", in: NewLine("repeated group Result = 1 {"), want: true},
+		{name: "Unlabeled Group", in: NewLine("group Result = 1 {"), want: true},
+		{name: "Message", in: NewLine("message Result {"), want: false},
+		{name: "Attribute named group", in: NewLine("string group = 1;"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gv := &GroupVisitor{}
+			assert.Equalf(t, tt.want, gv.CanVisit(tt.in), "CanVisit(%v)", tt.in)
+		})
+	}
+}
+
+func TestGroupVisitor_Visit(t *testing.T) {
+	scanner := NewTestScanner(`required string url = 2;
+optional string title = 3;
+}`)
+	gv := &GroupVisitor{}
+	out := gv.Visit(scanner, NewLine("repeated group Result = 1 { // Search results"), "test.SearchResponse")
+	group, ok := out.(*Group)
+	assert.True(t, ok)
+
+	assert.Equal(t, "result", group.Attribute.Name)
+	assert.Equal(t, []string{"Result"}, group.Attribute.Kind)
+	assert.Equal(t, 1, group.Attribute.Ordinal)
+	assert.True(t, group.Attribute.Repeated)
+	assert.True(t, group.Attribute.Group)
+
+	assert.Equal(t, "Result", group.Message.Name)
+	assert.Equal(t, "test.SearchResponse.Result", group.Message.Qualifier)
+	assert.Len(t, group.Message.Attributes, 2)
+	assert.True(t, group.Message.Attributes[0].Required)
+	assert.True(t, group.Message.Attributes[1].Optional)
+}
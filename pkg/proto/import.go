@@ -18,8 +18,13 @@ package proto
 
 // Import represents an importable file
 type Import struct {
-	Path    string
-	Comment Comment
+	Path     string
+	Comment  Comment
+	Comments *Comments
+	Location Location
+	// Package is the imported package once it is loaded from the include
+	// roots.
+	Package *Package `json:"-"`
 }
 
 // NewImport is the import constructor
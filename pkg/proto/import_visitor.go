@@ -30,5 +30,9 @@ func (iv *ImportVisitor) CanVisit(in *Line) bool {
 func (iv *ImportVisitor) Visit(_ Scanner, in *Line, _ string) interface{} {
 	Log.Debug("Visiting Import")
 	fValues := in.SplitSyntax()
-	return NewImport(RemoveDoubleQuotes(RemoveSemicolon(fValues[1])))
+	// The path follows the optional public or weak modifier
+	out := NewImport(RemoveDoubleQuotes(RemoveSemicolon(fValues[len(fValues)-1])))
+	out.Location = in.Location
+	out.Comments = in.Comments
+	return out
 }
@@ -69,6 +69,14 @@ func TestImportVisitor_Visit(t *testing.T) {
 				Comment: "",
 			},
 		},
+		{name: "Test public import Visitor",
+			args: args{
+				in0: NewTestScanner(""),
+				in:  &Line{Syntax: "import public \"test/location/model.proto\"", Token: ";"},
+				in2: "",
+			},
+			want: &Import{Path: "test/location/model.proto"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
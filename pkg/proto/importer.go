@@ -0,0 +1,140 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Importer locates imported files in include roots, as protoc does with the
+// -I or --proto_path flags, and reads them so their types can be resolved.
+// Imports missing from the roots are read from the Bundled files.
+type Importer struct {
+	Roots   []string
+	Bundled fs.FS
+	// packages are the packages read or loaded, by their absolute path, or
+	// their import path for bundled packages.
+	packages map[string]*Package
+}
+
+// NewImporter is the Importer constructor, reading the BundledFiles after the
+// roots.
+func NewImporter(roots ...string) *Importer {
+	return &Importer{Roots: roots, Bundled: BundledFiles(), packages: make(map[string]*Package)}
+}
+
+// Find returns the path of the imported file in the first include root
+// containing it.
+func (i *Importer) Find(path string) (string, bool) {
+	for _, root := range i.Roots {
+		candidate := filepath.Join(root, filepath.FromSlash(path))
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, true
+		}
+	}
+	return Empty, false
+}
+
+// ImportPath returns the path of a file relative to the first include root
+// containing it, the path it is imported with.
+func (i *Importer) ImportPath(path string) (string, bool) {
+	for _, root := range i.Roots {
+		rel, err := filepath.Rel(root, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return filepath.ToSlash(rel), true
+		}
+	}
+	return Empty, false
+}
+
+// Load reads the files imported by the packages, and the files they import in
+// turn, from the include roots. Imports of packages already read are not read
+// again. The loaded packages are returned with the diagnostics reported while
+// reading them, imports that are not found are reported as errors.
+func (i *Importer) Load(packages ...*Package) ([]*Package, Diagnostics) {
+	for _, p := range packages {
+		i.packages[absolutePath(p.Path)] = p
+	}
+	out := make([]*Package, 0)
+	diagnostics := make(Diagnostics, 0)
+	pending := append(make([]*Package, 0), packages...)
+	for len(pending) > 0 {
+		p := pending[0]
+		pending = pending[1:]
+		for _, imp := range p.Imports {
+			key, imported, err := i.open(imp.Path)
+			if err != nil {
+				diagnostics = append(diagnostics, NewDiagnostic(SeverityError, imp.Location, p.Name, err.Error()))
+				continue
+			}
+			if loaded, ok := i.packages[key]; ok {
+				imp.Package = loaded
+				continue
+			}
+			read, err := i.read(imported)
+			if err != nil {
+				diagnostics = append(diagnostics, NewDiagnostic(SeverityError, imp.Location, p.Name, err.Error()))
+				continue
+			}
+			diagnostics = append(diagnostics, read...)
+			i.packages[key] = imported
+			imp.Package = imported
+			out = append(out, imported)
+			pending = append(pending, imported)
+		}
+	}
+	return out, diagnostics
+}
+
+// open returns the package of an import path, found in the roots or the
+// bundled files, and the key of the package in the packages.
+func (i *Importer) open(path string) (string, *Package, error) {
+	if found, ok := i.Find(path); ok {
+		return absolutePath(found), NewPackage(found), nil
+	}
+	if i.Bundled != nil {
+		if _, err := fs.Stat(i.Bundled, path); err == nil {
+			out := NewPackage(path)
+			out.Bundled = true
+			return path, out, nil
+		}
+	}
+	return Empty, nil, fmt.Errorf("import `%s` not found in the include roots", path)
+}
+
+func (i *Importer) read(p *Package) (Diagnostics, error) {
+	if !p.Bundled {
+		return p.Read(Log.debug)
+	}
+	file, err := i.Bundled.Open(p.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return p.ReadSource(file)
+}
+
+func absolutePath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
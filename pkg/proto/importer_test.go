@@ -0,0 +1,108 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImporter_Find(t *testing.T) {
+	importer := NewImporter("data/test", "data")
+	path, ok := importer.Find("test/location/model.proto")
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join("data", "test", "location", "model.proto"), path)
+	_, ok = importer.Find("google/protobuf/timestamp.proto")
+	assert.False(t, ok)
+	_, ok = importer.Find("test/location")
+	assert.False(t, ok)
+
+	importPath, ok := importer.ImportPath(path)
+	assert.True(t, ok)
+	assert.Equal(t, "location/model.proto", importPath)
+	_, ok = importer.ImportPath("other/model.proto")
+	assert.False(t, ok)
+}
+
+func TestImporter_Load(t *testing.T) {
+	service := NewPackage("data/test/service/service.proto")
+	_, err := service.Read(false)
+	assert.Nil(t, err)
+
+	importer := NewImporter("data")
+	importer.Bundled = nil
+	imported, diagnostics := importer.Load(service)
+	assert.Len(t, imported, 1)
+	assert.Equal(t, "test.location", imported[0].Name)
+	assert.Equal(t, imported[0], service.Imports[0].Package)
+	assert.Nil(t, service.Imports[1].Package)
+	assert.Equal(t, "data/test/service/service.proto:18:1: error: import `google/protobuf/empty.proto` not found in the include roots (test.service)\n"+
+		"data/test/service/service.proto:19:1: error: import `google/api/annotations.proto` not found in the include roots (test.service)\n"+
+		"data/test/location/model.proto:18:1: error: import `google/protobuf/timestamp.proto` not found in the include roots (test.location)",
+		diagnostics.String())
+
+	// The types of the imported files are resolved
+	Link(append(imported, service)...)
+	list := service.Services[0].Methods[0]
+	assert.Equal(t, imported[0].Messages[0], list.ReturnParameters[0].Resolved.Message)
+}
+
+func TestImporter_LoadRead(t *testing.T) {
+	model := NewPackage("data/test/location/model.proto")
+	service := NewPackage("data/test/service/service.proto")
+	for _, p := range []*Package{model, service} {
+		_, err := p.Read(false)
+		assert.Nil(t, err)
+	}
+	// Files already read are not read again
+	importer := NewImporter("data")
+	importer.Bundled = nil
+	imported, _ := importer.Load(model, service)
+	assert.Empty(t, imported)
+	assert.Equal(t, model, service.Imports[0].Package)
+}
+
+func TestImporter_LoadBundled(t *testing.T) {
+	service := NewPackage("data/test/service/service.proto")
+	_, err := service.Read(false)
+	assert.Nil(t, err)
+
+	imported, diagnostics := NewImporter("data").Load(service)
+	assert.Empty(t, diagnostics)
+	paths := make([]string, 0)
+	for _, p := range imported {
+		paths = append(paths, p.Path)
+		assert.Empty(t, p.Diagnostics, p.Path)
+	}
+	assert.Equal(t, []string{
+		filepath.Join("data", "test", "location", "model.proto"),
+		"google/protobuf/empty.proto",
+		"google/api/annotations.proto",
+		"google/protobuf/timestamp.proto",
+		"google/api/http.proto",
+		"google/protobuf/descriptor.proto",
+	}, paths)
+	assert.False(t, imported[0].Bundled)
+	assert.True(t, service.Imports[1].Package.Bundled)
+
+	assert.Empty(t, Link(append(imported, service)...))
+	list := service.Services[0].Methods[0]
+	assert.Equal(t, "google.protobuf.Empty", list.InputParameters[0].Resolved.Name)
+	assert.True(t, IsExternal(list.InputParameters[0].Resolved))
+}
@@ -0,0 +1,253 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// TokenKind classifies the tokens produced by the Lexer.
+type TokenKind int
+
+const (
+	// TokenIdentifier is a name or keyword, e.g. message, int32 or PhysicalLocation
+	TokenIdentifier TokenKind = iota
+	// TokenInteger is a decimal, octal or hexadecimal integer literal
+	TokenInteger
+	// TokenFloat is a floating point literal, e.g. 1.5 or 2e10
+	TokenFloat
+	// TokenString is a single or double-quoted string literal
+	TokenString
+	// TokenPunctuation is a single symbol, e.g. ; { } [ ] ( ) < > = , . : - + /
+	TokenPunctuation
+	// TokenComment is a line (//) or block (/* */) comment
+	TokenComment
+)
+
+// Punctuation lists the symbols allowed outside of literals and comments.
+const Punctuation = ";{}[]()<>=,.:-+/"
+
+// Position is a location in a protobuf source, lines and columns start at 1.
+type Position struct {
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Before determines if the position precedes the other position.
+func (p Position) Before(other Position) bool {
+	return p.Line < other.Line || p.Line == other.Line && p.Column < other.Column
+}
+
+// Token is a lexical element of a protobuf source.
+type Token struct {
+	Kind TokenKind
+	// Text is the token as written, including the quotes of string literals
+	// and the delimiters of comments.
+	Text string
+	// Start is the position of the first character of the token.
+	Start Position
+	// End is the position of the last character of the token.
+	End Position
+	// Spaced is true when the token is preceded by whitespace or a comment.
+	Spaced bool
+}
+
+// Is determines if the token is the given punctuation symbol.
+func (t *Token) Is(punctuation string) bool {
+	return t != nil && t.Kind == TokenPunctuation && t.Text == punctuation
+}
+
+// SyntaxError is an error found while reading a protobuf source.
+type SyntaxError struct {
+	Position Position
+	Message  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Position, e.Message)
+}
+
+// SyntaxErrors returns the syntax errors of an error, including the errors
+// joined by Tokenize and Parse.
+func SyntaxErrors(err error) []*SyntaxError {
+	out := make([]*SyntaxError, 0)
+	switch e := err.(type) {
+	case *SyntaxError:
+		out = append(out, e)
+	case interface{ Unwrap() []error }:
+		for _, joined := range e.Unwrap() {
+			out = append(out, SyntaxErrors(joined)...)
+		}
+	}
+	return out
+}
+
+// Lexer splits a protobuf source into tokens. Errors do not stop the lexer,
+// they are collected in Errors and the offending characters are skipped.
+type Lexer struct {
+	input  []rune
+	offset int
+	line   int
+	column int
+	Errors []error
+}
+
+// NewLexer is the Lexer constructor
+func NewLexer(in string) *Lexer {
+	return &Lexer{input: []rune(in), line: 1, column: 1}
+}
+
+// Tokenize splits the source into tokens, returning every error found.
+func Tokenize(in string) ([]*Token, error) {
+	lexer := NewLexer(in)
+	out := make([]*Token, 0)
+	for t := lexer.Next(); t != nil; t = lexer.Next() {
+		out = append(out, t)
+	}
+	return out, errors.Join(lexer.Errors...)
+}
+
+func (l *Lexer) peek(n int) rune {
+	if l.offset+n < len(l.input) {
+		return l.input[l.offset+n]
+	}
+	return 0
+}
+
+func (l *Lexer) position() Position {
+	return Position{Line: l.line, Column: l.column}
+}
+
+func (l *Lexer) advance() rune {
+	r := l.input[l.offset]
+	l.offset++
+	if r == '\n' {
+		l.line++
+		l.column = 1
+	} else {
+		l.column++
+	}
+	return r
+}
+
+func (l *Lexer) error(p Position, format string, args ...any) {
+	l.Errors = append(l.Errors, &SyntaxError{Position: p, Message: fmt.Sprintf(format, args...)})
+}
+
+// Next returns the next token, or nil at the end of the input.
+func (l *Lexer) Next() *Token {
+	spaced := false
+	for l.offset < len(l.input) {
+		r := l.peek(0)
+		if unicode.IsSpace(r) {
+			spaced = true
+			l.advance()
+			continue
+		}
+		start := l.position()
+		begin := l.offset
+		kind := TokenPunctuation
+		switch {
+		case r == '/' && l.peek(1) == '/':
+			kind = TokenComment
+			for l.offset < len(l.input) && l.peek(0) != '\n' {
+				l.advance()
+			}
+		case r == '/' && l.peek(1) == '*':
+			kind = TokenComment
+			l.advance()
+			l.advance()
+			for l.offset < len(l.input) && !(l.peek(0) == '*' && l.peek(1) == '/') {
+				l.advance()
+			}
+			if l.offset < len(l.input) {
+				l.advance()
+				l.advance()
+			} else {
+				l.error(start, "unterminated comment")
+			}
+		case r == '"' || r == '\'':
+			kind = TokenString
+			l.lexString(start, r)
+		case r == '_' || unicode.IsLetter(r):
+			kind = TokenIdentifier
+			for l.offset < len(l.input) && (l.peek(0) == '_' || unicode.IsLetter(l.peek(0)) || unicode.IsDigit(l.peek(0))) {
+				l.advance()
+			}
+		case unicode.IsDigit(r) || r == '.' && unicode.IsDigit(l.peek(1)):
+			kind = l.lexNumber()
+		case strings.ContainsRune(Punctuation, r):
+			l.advance()
+		default:
+			l.error(start, "unexpected character %q", r)
+			l.advance()
+			spaced = true
+			continue
+		}
+		end := l.position()
+		end.Column--
+		return &Token{Kind: kind, Text: string(l.input[begin:l.offset]), Start: start, End: end, Spaced: spaced}
+	}
+	return nil
+}
+
+// lexString reads a string literal, which can not span lines.
+func (l *Lexer) lexString(start Position, quote rune) {
+	l.advance()
+	for l.offset < len(l.input) {
+		r := l.peek(0)
+		if r == '\n' {
+			break
+		}
+		l.advance()
+		if r == '\\' && l.offset < len(l.input) && l.peek(0) != '\n' {
+			l.advance()
+		} else if r == quote {
+			return
+		}
+	}
+	l.error(start, "unterminated string")
+}
+
+// lexNumber reads an integer or floating point literal, including exponents
+// such as 1e-10 and hexadecimal values such as 0x1F.
+func (l *Lexer) lexNumber() TokenKind {
+	hex := l.peek(0) == '0' && (l.peek(1) == 'x' || l.peek(1) == 'X')
+	kind := TokenInteger
+	for l.offset < len(l.input) {
+		r := l.peek(0)
+		if r == '.' {
+			kind = TokenFloat
+		} else if !hex && (r == 'e' || r == 'E') {
+			kind = TokenFloat
+			if l.peek(1) == '+' || l.peek(1) == '-' {
+				l.advance()
+			}
+		} else if !(r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			break
+		}
+		l.advance()
+	}
+	return kind
+}
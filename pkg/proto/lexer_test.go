@@ -0,0 +1,76 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	tokens, err := Tokenize(`message A { // a comment
+  string s = 1 [default = "a;{}\"//"];
+}`)
+	assert.Nil(t, err)
+	texts := make([]string, 0)
+	for _, token := range tokens {
+		texts = append(texts, token.Text)
+	}
+	assert.Equal(t, []string{"message", "A", "{", "// a comment", "string", "s", "=", "1", "[", "default", "=", `"a;{}\"//"`, "]", ";", "}"}, texts)
+	assert.Equal(t, TokenComment, tokens[3].Kind)
+	assert.Equal(t, TokenString, tokens[11].Kind)
+	assert.Equal(t, Position{Line: 2, Column: 3}, tokens[4].Start)
+	assert.Equal(t, Position{Line: 2, Column: 8}, tokens[4].End)
+	assert.True(t, tokens[5].Spaced)
+	assert.False(t, tokens[9].Spaced)
+}
+
+func TestTokenize_Kinds(t *testing.T) {
+	tests := []struct {
+		in   string
+		want TokenKind
+	}{
+		{in: "int32", want: TokenIdentifier},
+		{in: "_name", want: TokenIdentifier},
+		{in: "42", want: TokenInteger},
+		{in: "0x1F", want: TokenInteger},
+		{in: "1.5", want: TokenFloat},
+		{in: "1e-10", want: TokenFloat},
+		{in: ".5", want: TokenFloat},
+		{in: `'single'`, want: TokenString},
+		{in: "/* block\n comment */", want: TokenComment},
+		{in: ";", want: TokenPunctuation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			tokens, err := Tokenize(tt.in)
+			assert.Nil(t, err)
+			assert.Len(t, tokens, 1)
+			assert.Equal(t, tt.want, tokens[0].Kind)
+			assert.Equal(t, tt.in, tokens[0].Text)
+		})
+	}
+}
+
+func TestTokenize_Errors(t *testing.T) {
+	tokens, err := Tokenize("a @ \"open\nb /* open")
+	assert.Equal(t, "1:3: unexpected character '@'\n1:5: unterminated string\n2:3: unterminated comment", err.Error())
+	assert.Len(t, tokens, 4)
+	assert.Equal(t, `"open`, tokens[1].Text)
+	assert.Equal(t, "b", tokens[2].Text)
+}
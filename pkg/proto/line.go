@@ -23,6 +23,12 @@ type Line struct {
 	Syntax  string
 	Token   string
 	Comment Comment
+	// Comments are the comments attached to the statement by the Parser,
+	// Comment holds all of them.
+	Comments *Comments
+	// Location is the span of the statement in the source, for a block it
+	// extends to the closing brace.
+	Location Location
 }
 
 func NewLine(in string) *Line {
@@ -56,7 +62,78 @@ func NewLine(in string) *Line {
 	return line
 }
 
+// String returns the line as it would be written in a protobuf source.
+func (l *Line) String() string {
+	switch l.Token {
+	case InlineCommentPrefix:
+		return Join(Space, InlineCommentPrefix, string(l.Comment))
+	case MultiLineCommentInitiator:
+		return Join(Space, MultiLineCommentInitiator, string(l.Comment), MultilineCommentTerminator)
+	case Semicolon:
+		return l.Syntax + l.Token
+	}
+	return strings.TrimSpace(Join(Space, l.Syntax, l.Token))
+}
+
 // SplitSyntax breaks the syntax line on Space the character
 func (l *Line) SplitSyntax() []string {
 	return strings.Split(l.Syntax, Space)
 }
+
+// SkipBlock reads the remainder of a block whose declaration is invalid, up to
+// its closing brace, so that its statements are not read into the enclosing
+// element.
+func SkipBlock(scanner Scanner) {
+	depth := 1
+	for depth > 0 && scanner.Scan() {
+		switch scanner.ReadLine().Token {
+		case OpenBrace:
+			depth++
+		case CloseBrace:
+			depth--
+		}
+	}
+}
+
+// ReadStatement reads the remainder of a statement whose value contains braces,
+// such as an aggregate option `option (a) = { b: 1 };`, and joins it into a
+// single line terminated by a semicolon. The line passed in is expected to end
+// with an open brace.
+func ReadStatement(scanner Scanner, in *Line) *Line {
+	parts := []string{in.Syntax, in.Token}
+	location := in.Location
+	depth := 1
+	for scanner.Scan() {
+		line := scanner.ReadLine()
+		if line.Location.IsValid() {
+			location.End = line.Location.End
+		}
+		switch line.Token {
+		case InlineCommentPrefix, MultiLineCommentInitiator:
+			continue
+		case Empty:
+			// Lines without a token are only present in unformatted input
+			if text := strings.TrimSpace(scanner.Text()); !strings.HasPrefix(text, InlineCommentPrefix) {
+				parts = append(parts, text)
+			}
+			continue
+		case OpenBrace:
+			depth++
+		case CloseBrace:
+			depth--
+		}
+		depth += strings.Count(line.Syntax, OpenBrace) - strings.Count(line.Syntax, CloseBrace)
+		if line.Token == Semicolon && depth == 0 {
+			parts = append(parts, line.Syntax)
+			break
+		}
+		parts = append(parts, line.Syntax, line.Token)
+	}
+	syntax := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if len(p) > 0 {
+			syntax = append(syntax, p)
+		}
+	}
+	return &Line{Syntax: strings.Join(syntax, Space), Token: Semicolon, Comment: in.Comment, Location: location}
+}
@@ -72,3 +72,16 @@ func TestNewLine(t *testing.T) {
 		})
 	}
 }
+
+func TestReadStatement(t *testing.T) {
+	scanner := NewTestScanner(`a: 1 nested {
+// Comment
+b: [1, 2] }
+}
+;
+string next = 1;`)
+	out := ReadStatement(scanner, &Line{Syntax: "option (my.option) =", Token: OpenBrace, Comment: "Option"})
+	assert.Equal(t, &Line{Syntax: "option (my.option) = { a: 1 nested { b: [1, 2] } }", Token: Semicolon, Comment: "Option"}, out)
+	assert.True(t, scanner.Scan())
+	assert.Equal(t, "string next = 1;", scanner.Text())
+}
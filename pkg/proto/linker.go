@@ -0,0 +1,265 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SymbolKind classifies the symbols of a SymbolTable.
+type SymbolKind int
+
+const (
+	// SymbolPackage is a package name or one of its prefixes, e.g. google and
+	// google.protobuf.
+	SymbolPackage SymbolKind = iota
+	// SymbolMessage is a message, including the messages of proto2 groups.
+	SymbolMessage
+	// SymbolEnum is an enumeration.
+	SymbolEnum
+)
+
+// Symbol is a named declaration of a SymbolTable, and the resolved reference
+// stored on the model by the linker.
+type Symbol struct {
+	// Name is the fully-qualified name, without a leading period.
+	Name string
+	Kind SymbolKind
+	// The declarations are not encoded, they would form cycles in the debug
+	// output of the packages.
+	Message *Message `json:"-"`
+	Enum    *Enum    `json:"-"`
+	// Package is the package declaring the message or enum.
+	Package *Package `json:"-"`
+}
+
+// IsType determines if the symbol is a message or an enum.
+func (s *Symbol) IsType() bool {
+	return s != nil && (s.Kind == SymbolMessage || s.Kind == SymbolEnum)
+}
+
+// SymbolTable holds the packages, messages and enums of a set of packages by
+// their fully-qualified names.
+type SymbolTable struct {
+	symbols     map[string]*Symbol
+	Diagnostics Diagnostics
+}
+
+// NewSymbolTable is the SymbolTable constructor, adding the declarations of
+// the packages.
+func NewSymbolTable(packages ...*Package) *SymbolTable {
+	out := &SymbolTable{symbols: make(map[string]*Symbol), Diagnostics: make(Diagnostics, 0)}
+	for _, p := range packages {
+		out.Add(p)
+	}
+	return out
+}
+
+// Add adds the declarations of the package, duplicate declarations are
+// reported in Diagnostics.
+func (st *SymbolTable) Add(p *Package) {
+	parts := strings.Split(p.Name, Period)
+	for i := range parts {
+		name := strings.Join(parts[:i+1], Period)
+		if _, ok := st.symbols[name]; !ok && len(name) > 0 {
+			st.symbols[name] = &Symbol{Name: name, Kind: SymbolPackage}
+		}
+	}
+	for _, m := range p.Messages {
+		st.addMessage(p, m)
+	}
+	for _, e := range p.Enums {
+		st.add(p, &Symbol{Name: QualifiedName(e.Qualifier), Kind: SymbolEnum, Enum: e}, e.Location)
+	}
+}
+
+func (st *SymbolTable) addMessage(p *Package, m *Message) {
+	st.add(p, &Symbol{Name: QualifiedName(m.Qualifier), Kind: SymbolMessage, Message: m}, m.Location)
+	for _, nested := range m.Messages {
+		st.addMessage(p, nested)
+	}
+	for _, e := range m.Enums {
+		st.add(p, &Symbol{Name: QualifiedName(e.Qualifier), Kind: SymbolEnum, Enum: e}, e.Location)
+	}
+}
+
+func (st *SymbolTable) add(p *Package, s *Symbol, location Location) {
+	s.Package = p
+	if existing, ok := st.symbols[s.Name]; ok && existing.IsType() {
+		if existing.Message != s.Message || existing.Enum != s.Enum {
+			st.Diagnostics = append(st.Diagnostics, NewDiagnostic(SeverityError, location, s.Name,
+				fmt.Sprintf("%s is already defined in %s", s.Name, existing.Package.Path)))
+		}
+		return
+	}
+	st.symbols[s.Name] = s
+}
+
+// Lookup returns the symbol of a fully-qualified name, with or without a
+// leading period, or nil.
+func (st *SymbolTable) Lookup(name string) *Symbol {
+	return st.symbols[QualifiedName(name)]
+}
+
+// Resolve returns the symbol a type name refers to from the scope, the
+// fully-qualified name of the message, service or package where the name is
+// used. Following the protobuf scoping rules, a name with a leading period is
+// fully-qualified, otherwise its first component is searched from the
+// innermost scope outwards and the rest of the name is resolved from there.
+func (st *SymbolTable) Resolve(scope string, name string) *Symbol {
+	name = strings.TrimSpace(name)
+	if strings.HasPrefix(name, Period) {
+		return st.Lookup(name)
+	}
+	first := name
+	if i := strings.Index(name, Period); i >= 0 {
+		first = name[:i]
+	}
+	scope = QualifiedName(scope)
+	for {
+		if s, ok := st.symbols[qualify(scope, first)]; ok {
+			if first == name {
+				if s.IsType() {
+					return s
+				}
+			} else if s.Kind != SymbolEnum {
+				// The rest of a compound name is resolved from the first
+				// package or message found, not searched further
+				return st.Lookup(qualify(scope, name))
+			}
+		}
+		if len(scope) == 0 {
+			return nil
+		}
+		scope = scope[:max(strings.LastIndex(scope, Period), 0)]
+	}
+}
+
+// RelativeName returns the shortest name resolving to the fully-qualified type
+// from the scope, the name itself if the type is not in the table.
+func (st *SymbolTable) RelativeName(scope string, name string) string {
+	name = QualifiedName(name)
+	s := st.Lookup(name)
+	if s == nil {
+		return name
+	}
+	components := strings.Split(name, Period)
+	for i := len(components) - 1; i > 0; i-- {
+		candidate := strings.Join(components[i:], Period)
+		if st.Resolve(scope, candidate) == s {
+			return candidate
+		}
+	}
+	return name
+}
+
+func qualify(scope string, name string) string {
+	if len(scope) == 0 {
+		return name
+	}
+	return Join(Period, scope, name)
+}
+
+// QualifiedName removes the leading period of a fully-qualified name.
+func QualifiedName(name string) string {
+	return strings.TrimPrefix(strings.TrimSpace(name), Period)
+}
+
+// Link resolves the types of the fields, map values, rpc parameters and
+// extendees of the packages to their declarations in any of the packages.
+// Types that cannot be resolved and duplicate declarations are returned as
+// diagnostics.
+func Link(packages ...*Package) Diagnostics {
+	st := NewSymbolTable(packages...)
+	out := append(make(Diagnostics, 0), st.Diagnostics...)
+	for _, p := range packages {
+		out = append(out, st.Link(p)...)
+	}
+	return out
+}
+
+// Link resolves the type references of the package.
+func (st *SymbolTable) Link(p *Package) Diagnostics {
+	out := make(Diagnostics, 0)
+	for _, m := range p.Messages {
+		out = append(out, st.linkMessage(m)...)
+	}
+	for _, e := range p.Extends {
+		out = append(out, st.linkExtension(e)...)
+	}
+	for _, s := range p.Services {
+		for _, rpc := range s.Methods {
+			for _, parameter := range append(append(make([]*Parameter, 0), rpc.InputParameters...), rpc.ReturnParameters...) {
+				parameter.Resolved = st.Resolve(rpc.Qualifier, parameter.Type)
+				if !parameter.Resolved.IsType() {
+					parameter.Resolved = nil
+					out = append(out, st.unresolved(rpc.Location, Join(Period, rpc.Qualifier, rpc.Name), parameter.Type))
+				}
+			}
+		}
+	}
+	return out
+}
+
+func (st *SymbolTable) linkMessage(m *Message) Diagnostics {
+	out := st.linkAttributes(m.Attributes)
+	for _, nested := range m.Messages {
+		out = append(out, st.linkMessage(nested)...)
+	}
+	for _, e := range m.Extends {
+		out = append(out, st.linkExtension(e)...)
+	}
+	return out
+}
+
+func (st *SymbolTable) linkExtension(e *Extension) Diagnostics {
+	out := st.linkAttributes(e.Attributes)
+	e.Resolved = st.Resolve(e.Qualifier, e.Extendee)
+	if e.Resolved == nil || e.Resolved.Kind != SymbolMessage {
+		e.Resolved = nil
+		out = append(out, st.unresolved(e.Location, e.Qualifier, e.Extendee))
+	}
+	for _, m := range e.Messages {
+		out = append(out, st.linkMessage(m)...)
+	}
+	return out
+}
+
+// linkAttributes resolves the types of the attributes, the key and value types
+// of a map.
+func (st *SymbolTable) linkAttributes(attributes []*Attribute) Diagnostics {
+	out := make(Diagnostics, 0)
+	for _, a := range attributes {
+		a.Resolved = make([]*Symbol, len(a.Kind))
+		for i, kind := range a.Kind {
+			if IsScalarType(kind) {
+				continue
+			}
+			if s := st.Resolve(a.Qualifier, kind); s.IsType() {
+				a.Resolved[i] = s
+			} else {
+				out = append(out, st.unresolved(a.Location, Join(Period, a.Qualifier, a.Name), kind))
+			}
+		}
+	}
+	return out
+}
+
+func (st *SymbolTable) unresolved(location Location, element string, name string) *Diagnostic {
+	return NewDiagnostic(SeverityWarning, location, QualifiedName(element), fmt.Sprintf("unresolved type %s", strings.TrimSpace(name)))
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parseLinked(t *testing.T, sources ...string) []*Package {
+	packages := make([]*Package, 0)
+	for i, source := range sources {
+		p, err := ParseString(string(rune('a'+i))+".proto", source)
+		assert.Nil(t, err)
+		packages = append(packages, p)
+	}
+	return packages
+}
+
+func TestLink(t *testing.T) {
+	packages := parseLinked(t, `syntax = "proto3";
+package test.library;
+import "test/common.proto";
+
+message Book {
+  message Author {
+    string name = 1;
+    Status status = 2;
+  }
+  enum Status {
+    STATUS_UNSPECIFIED = 0;
+  }
+  Author author = 1;
+  repeated .test.common.Money prices = 2;
+  map<string, common.Money> offers = 3;
+  Book.Author editor = 4;
+  oneof cover {
+    Image image = 5;
+  }
+}
+
+message Image {}
+
+service Library {
+  rpc GetBook(Book) returns (stream Book.Author);
+}`, `syntax = "proto3";
+package test.common;
+
+message Money {
+  string currency = 1;
+}`)
+	diagnostics := Link(packages...)
+	assert.Empty(t, diagnostics)
+
+	library, common := packages[0], packages[1]
+	book := library.Messages[0]
+	author := book.Messages[0]
+	money := common.Messages[0]
+	assert.Equal(t, []*Symbol{{Name: "test.library.Book.Author", Kind: SymbolMessage, Message: author, Package: library}}, book.Attributes[0].Resolved)
+	assert.Equal(t, money, book.Attributes[1].Resolved[0].Message)
+	assert.Equal(t, common, book.Attributes[1].Resolved[0].Package)
+	assert.Nil(t, book.Attributes[2].Resolved[0])
+	assert.Equal(t, money, book.Attributes[2].Resolved[1].Message)
+	assert.Equal(t, author, book.Attributes[3].Resolved[0].Message)
+	assert.Equal(t, library.Messages[1], book.Attributes[4].Resolved[0].Message)
+	assert.Equal(t, book.Enums[0], author.Attributes[1].Resolved[0].Enum)
+	assert.Equal(t, []*Symbol{nil}, money.Attributes[0].Resolved)
+
+	rpc := library.Services[0].Methods[0]
+	assert.Equal(t, book, rpc.InputParameters[0].Resolved.Message)
+	assert.Equal(t, author, rpc.ReturnParameters[0].Resolved.Message)
+}
+
+func TestLink_Extensions(t *testing.T) {
+	packages := parseLinked(t, `syntax = "proto2";
+package test;
+
+message Base {
+  extensions 100 to 200;
+}
+
+message Holder {
+  extend Base {
+    optional Holder holder = 100;
+  }
+}
+
+extend .test.Base {
+  optional Missing missing = 101;
+}`)
+	diagnostics := Link(packages...)
+	holder := packages[0].Messages[1]
+	assert.Equal(t, packages[0].Messages[0], holder.Extends[0].Resolved.Message)
+	assert.Equal(t, holder, holder.Extends[0].Attributes[0].Resolved[0].Message)
+	assert.Equal(t, packages[0].Messages[0], packages[0].Extends[0].Resolved.Message)
+	assert.Equal(t, "a.proto:15:3: warning: unresolved type Missing (test.missing)", diagnostics.String())
+}
+
+func TestSymbolTable_Resolve(t *testing.T) {
+	st := NewSymbolTable(parseLinked(t, `package foo.bar;
+message Baz {
+  message Qux {}
+  enum E { A = 0; }
+}
+message Qux {}
+message foo {
+  message bar {}
+}`)...)
+	tests := []struct {
+		name  string
+		scope string
+		in    string
+		want  string
+	}{
+		{name: "Innermost Scope", scope: "foo.bar.Baz", in: "Qux", want: "foo.bar.Baz.Qux"},
+		{name: "Outer Scope", scope: "foo.bar", in: "Qux", want: "foo.bar.Qux"},
+		{name: "Absolute", scope: "foo.bar.Baz", in: ".foo.bar.Qux", want: "foo.bar.Qux"},
+		{name: "Partially Qualified", scope: "foo.bar.Baz", in: "bar.Baz.E", want: "foo.bar.Baz.E"},
+		{name: "Package Is Not A Type", scope: "foo.bar", in: "bar", want: ""},
+		{name: "Message Shadows Package", scope: "foo.bar.foo", in: "bar", want: "foo.bar.foo.bar"},
+		// foo is found as the nested message foo.bar.foo, which has no Baz
+		{name: "First Component Shadowed", scope: "foo.bar.foo", in: "foo.bar.Baz", want: ""},
+		{name: "Enum Is Not A Scope", scope: "foo.bar", in: "Baz.E.A", want: ""},
+		{name: "Unknown", scope: "foo.bar", in: "Unknown", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := st.Resolve(tt.scope, tt.in)
+			if len(tt.want) == 0 {
+				assert.Nil(t, got)
+			} else {
+				assert.Equal(t, tt.want, got.Name)
+			}
+		})
+	}
+	assert.Equal(t, SymbolPackage, st.Lookup("foo").Kind)
+	assert.Equal(t, SymbolMessage, st.Lookup(".foo.bar.Baz").Kind)
+
+	assert.Equal(t, "Qux", st.RelativeName("foo.bar.Baz", ".foo.bar.Baz.Qux"))
+	// Qux is shadowed by the nested message from the scope of Baz
+	assert.Equal(t, "bar.Qux", st.RelativeName("foo.bar.Baz", "foo.bar.Qux"))
+	assert.Equal(t, "Baz", st.RelativeName("foo.bar.foo", "foo.bar.Baz"))
+	assert.Equal(t, "a.Unknown", st.RelativeName("foo.bar", ".a.Unknown"))
+}
+
+func TestLink_Duplicates(t *testing.T) {
+	diagnostics := Link(parseLinked(t, "package a;\nmessage A {}", "package a;\nenum A { B = 0; }")...)
+	assert.Equal(t, "b.proto:2:1: error: a.A is already defined in a.proto (a.A)", diagnostics.String())
+}
@@ -0,0 +1,270 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// The rules of the linter, following the Google protobuf style guide.
+const (
+	LintMessagePascalCase       = "MESSAGE_PASCAL_CASE"
+	LintEnumPascalCase          = "ENUM_PASCAL_CASE"
+	LintServicePascalCase       = "SERVICE_PASCAL_CASE"
+	LintFieldLowerSnakeCase     = "FIELD_LOWER_SNAKE_CASE"
+	LintEnumValueUpperSnakeCase = "ENUM_VALUE_UPPER_SNAKE_CASE"
+	LintEnumValuePrefix         = "ENUM_VALUE_PREFIX"
+	LintEnumZeroValueSuffix     = "ENUM_ZERO_VALUE_SUFFIX"
+	LintRpcRequestStandardName  = "RPC_REQUEST_STANDARD_NAME"
+	LintRpcResponseStandardName = "RPC_RESPONSE_STANDARD_NAME"
+	LintPackageDirectoryMatch   = "PACKAGE_DIRECTORY_MATCH"
+)
+
+// LintRules are the descriptions of the rules of the linter by id.
+var LintRules = map[string]string{
+	LintMessagePascalCase:       "Message names are PascalCase.",
+	LintEnumPascalCase:          "Enum names are PascalCase.",
+	LintServicePascalCase:       "Service names are PascalCase.",
+	LintFieldLowerSnakeCase:     "Field names are lower_snake_case.",
+	LintEnumValueUpperSnakeCase: "Enum value names are UPPER_SNAKE_CASE.",
+	LintEnumValuePrefix:         "Enum value names are prefixed with the UPPER_SNAKE_CASE enum name.",
+	LintEnumZeroValueSuffix:     "Enums have a zero value suffixed with _UNSPECIFIED.",
+	LintRpcRequestStandardName:  "Rpc request types are named <Method>Request.",
+	LintRpcResponseStandardName: "Rpc response types are named <Method>Response.",
+	LintPackageDirectoryMatch:   "Files are in the directory of their package, e.g. foo/bar for foo.bar.",
+}
+
+// LintIgnore is the comment directive suppressing a rule for an element, e.g.
+// `// lint:ignore FIELD_LOWER_SNAKE_CASE` in its leading or trailing comment.
+const LintIgnore = "lint:ignore"
+
+// LintZeroValueSuffix is the suffix of the zero value of an enum.
+const LintZeroValueSuffix = "_UNSPECIFIED"
+
+var (
+	pascalCase     = regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`)
+	lowerSnakeCase = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+	upperSnakeCase = regexp.MustCompile(`^[A-Z][A-Z0-9]*(_[A-Z0-9]+)*$`)
+)
+
+// LintConfig enables or disables the rules of the linter, it is read from a
+// JSON file such as `{"rules": {"ENUM_VALUE_PREFIX": false}}`.
+type LintConfig struct {
+	// Rules enables or disables rules by id, the rules not listed are enabled.
+	Rules map[string]bool `json:"rules"`
+}
+
+// NewLintConfig is the LintConfig constructor, enabling all the rules.
+func NewLintConfig() *LintConfig {
+	return &LintConfig{Rules: make(map[string]bool)}
+}
+
+// ParseLintConfig reads a JSON lint configuration, unknown rules are errors.
+func ParseLintConfig(data []byte) (*LintConfig, error) {
+	out := NewLintConfig()
+	if err := json.Unmarshal(data, out); err != nil {
+		return nil, err
+	}
+	for rule := range out.Rules {
+		if _, ok := LintRules[rule]; !ok {
+			return nil, fmt.Errorf("unknown lint rule %s", rule)
+		}
+	}
+	return out, nil
+}
+
+// ReadLintConfig reads a JSON lint configuration file.
+func ReadLintConfig(path string) (*LintConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseLintConfig(data)
+}
+
+// Enabled determines if the rule is enabled, rules are enabled by default.
+func (lc *LintConfig) Enabled(rule string) bool {
+	enabled, ok := lc.Rules[rule]
+	return !ok || enabled
+}
+
+// EnabledRules returns the ids of the enabled rules, sorted.
+func (lc *LintConfig) EnabledRules() []string {
+	out := make([]string, 0, len(LintRules))
+	for rule := range LintRules {
+		if lc.Enabled(rule) {
+			out = append(out, rule)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Lint checks the packages against the enabled rules of the style guide, the
+// violations are returned as warnings naming the rule. A nil config enables all
+// the rules.
+func Lint(config *LintConfig, packages ...*Package) Diagnostics {
+	if config == nil {
+		config = NewLintConfig()
+	}
+	l := &linter{config: config, out: make(Diagnostics, 0)}
+	for _, p := range packages {
+		l.lintPackage(p)
+	}
+	return l.out
+}
+
+// linter collects the violations of the enabled rules.
+type linter struct {
+	config *LintConfig
+	out    Diagnostics
+}
+
+// report adds a violation of the rule, unless the rule is disabled or ignored
+// by the comments of the element.
+func (l *linter) report(rule string, comments *Comments, location Location, element string, format string, args ...any) {
+	if !l.config.Enabled(rule) || IsLintIgnored(comments, rule) {
+		return
+	}
+	message := fmt.Sprintf(format, args...)
+	l.out = append(l.out, NewDiagnostic(SeverityWarning, location, QualifiedName(element), fmt.Sprintf("%s [%s]", message, rule)))
+}
+
+// IsLintIgnored determines if the leading or trailing comment suppresses the
+// rule with the lint:ignore directive.
+func IsLintIgnored(comments *Comments, rule string) bool {
+	if comments == nil {
+		return false
+	}
+	for _, c := range []Comment{comments.Leading, comments.Trailing} {
+		fields := strings.Fields(strings.ReplaceAll(string(c), CommentNewLine, Space))
+		for i := 0; i+1 < len(fields); i++ {
+			if fields[i] == LintIgnore && fields[i+1] == rule {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (l *linter) lintPackage(p *Package) {
+	if len(p.Name) > 0 {
+		directory := filepath.ToSlash(filepath.Dir(p.Path))
+		want := strings.ReplaceAll(p.Name, Period, "/")
+		if directory != want && !strings.HasSuffix(directory, "/"+want) {
+			l.report(LintPackageDirectoryMatch, p.Comments, p.Location, p.Name,
+				"package `%s` is not in a directory ending with `%s`", p.Name, want)
+		}
+	}
+	for _, m := range p.Messages {
+		l.lintMessage(m)
+	}
+	for _, e := range p.Enums {
+		l.lintEnum(e)
+	}
+	for _, e := range p.Extends {
+		l.lintFields(e.Attributes)
+	}
+	for _, s := range p.Services {
+		l.lintService(s)
+	}
+}
+
+func (l *linter) lintMessage(m *Message) {
+	if !pascalCase.MatchString(m.Name) {
+		l.report(LintMessagePascalCase, m.Comments, m.Location, m.Qualifier,
+			"message name `%s` is not PascalCase", m.Name)
+	}
+	l.lintFields(m.Attributes)
+	for _, e := range m.Extends {
+		l.lintFields(e.Attributes)
+	}
+	for _, nested := range m.Messages {
+		l.lintMessage(nested)
+	}
+	for _, e := range m.Enums {
+		l.lintEnum(e)
+	}
+}
+
+func (l *linter) lintFields(attributes []*Attribute) {
+	for _, a := range attributes {
+		if !lowerSnakeCase.MatchString(a.Name) {
+			l.report(LintFieldLowerSnakeCase, a.Comments, a.Location, Join(Period, a.Qualifier, a.Name),
+				"field name `%s` is not lower_snake_case", a.Name)
+		}
+	}
+}
+
+func (l *linter) lintEnum(e *Enum) {
+	if !pascalCase.MatchString(e.Name) {
+		l.report(LintEnumPascalCase, e.Comments, e.Location, e.Qualifier,
+			"enum name `%s` is not PascalCase", e.Name)
+	}
+	prefix := UpperSnakeCase(e.Name) + "_"
+	zero := false
+	for _, v := range e.Values {
+		element := Join(Period, e.Qualifier, v.Value)
+		if !upperSnakeCase.MatchString(v.Value) {
+			l.report(LintEnumValueUpperSnakeCase, v.Comments, v.Location, element,
+				"enum value name `%s` is not UPPER_SNAKE_CASE", v.Value)
+		}
+		if !strings.HasPrefix(v.Value, prefix) {
+			l.report(LintEnumValuePrefix, v.Comments, v.Location, element,
+				"enum value name `%s` is not prefixed with `%s`", v.Value, prefix)
+		}
+		if v.Ordinal == 0 {
+			zero = true
+			if !strings.HasSuffix(v.Value, LintZeroValueSuffix) {
+				l.report(LintEnumZeroValueSuffix, v.Comments, v.Location, element,
+					"enum zero value name `%s` is not suffixed with `%s`", v.Value, LintZeroValueSuffix)
+			}
+		}
+	}
+	if !zero {
+		l.report(LintEnumZeroValueSuffix, e.Comments, e.Location, e.Qualifier,
+			"enum `%s` has no zero value, e.g. `%s`", e.Name, UpperSnakeCase(e.Name)+LintZeroValueSuffix)
+	}
+}
+
+func (l *linter) lintService(s *Service) {
+	if !pascalCase.MatchString(s.Name) {
+		l.report(LintServicePascalCase, s.Comments, s.Location, Join(Period, s.Qualifier, s.Name),
+			"service name `%s` is not PascalCase", s.Name)
+	}
+	for _, rpc := range s.Methods {
+		element := Join(Period, rpc.Qualifier, rpc.Name)
+		for _, parameter := range rpc.InputParameters {
+			if want := rpc.Name + "Request"; RemoveNameQualification(parameter.Type) != want {
+				l.report(LintRpcRequestStandardName, rpc.Comments, rpc.Location, element,
+					"rpc request type `%s` of `%s` is not named `%s`", parameter.Type, rpc.Name, want)
+			}
+		}
+		for _, parameter := range rpc.ReturnParameters {
+			if want := rpc.Name + "Response"; RemoveNameQualification(parameter.Type) != want {
+				l.report(LintRpcResponseStandardName, rpc.Comments, rpc.Location, element,
+					"rpc response type `%s` of `%s` is not named `%s`", parameter.Type, rpc.Name, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "Valid", in: `syntax = "proto3";
+package test.library;
+message GetBookRequest {
+  string book_name = 1;
+  enum HTTPMethod {
+    HTTP_METHOD_UNSPECIFIED = 0;
+    HTTP_METHOD_GET = 1;
+  }
+}
+message GetBookResponse {}
+service Library {
+  rpc GetBook(GetBookRequest) returns (stream test.library.GetBookResponse);
+}`},
+		{name: "Message Name", in: `package test.library;
+message book_shelf {}`, want: "test/library/a.proto:2:1: warning: message name `book_shelf` is not PascalCase [MESSAGE_PASCAL_CASE] (test.library.book_shelf)"},
+		{name: "Field Name", in: `package test.library;
+message Book {
+  string bookName = 1;
+  oneof o {
+    int32 Page = 2;
+  }
+}`, want: "test/library/a.proto:3:3: warning: field name `bookName` is not lower_snake_case [FIELD_LOWER_SNAKE_CASE] (test.library.Book.bookName)\n" +
+			"test/library/a.proto:5:5: warning: field name `Page` is not lower_snake_case [FIELD_LOWER_SNAKE_CASE] (test.library.Book.Page)"},
+		{name: "Enum", in: `package test.library;
+enum status {
+  ACTIVE = 0;
+  STATUS_retired = 1;
+}`, want: "test/library/a.proto:2:1: warning: enum name `status` is not PascalCase [ENUM_PASCAL_CASE] (test.library.status)\n" +
+			"test/library/a.proto:3:3: warning: enum value name `ACTIVE` is not prefixed with `STATUS_` [ENUM_VALUE_PREFIX] (test.library.status.ACTIVE)\n" +
+			"test/library/a.proto:3:3: warning: enum zero value name `ACTIVE` is not suffixed with `_UNSPECIFIED` [ENUM_ZERO_VALUE_SUFFIX] (test.library.status.ACTIVE)\n" +
+			"test/library/a.proto:4:3: warning: enum value name `STATUS_retired` is not UPPER_SNAKE_CASE [ENUM_VALUE_UPPER_SNAKE_CASE] (test.library.status.STATUS_retired)"},
+		{name: "Enum Without Zero Value", in: `syntax = "proto2";
+package test.library;
+enum Status {
+  STATUS_ACTIVE = 1;
+  STATUS_RETIRED = 2;
+}`, want: "test/library/a.proto:3:1: warning: enum `Status` has no zero value, e.g. `STATUS_UNSPECIFIED` [ENUM_ZERO_VALUE_SUFFIX] (test.library.Status)"},
+		{name: "Service And Rpc", in: `package test.library;
+message Book {}
+service library_service {
+  rpc GetBook(Book) returns (Book);
+}`, want: "test/library/a.proto:3:1: warning: service name `library_service` is not PascalCase [SERVICE_PASCAL_CASE] (test.library.library_service)\n" +
+			"test/library/a.proto:4:3: warning: rpc request type `Book` of `GetBook` is not named `GetBookRequest` [RPC_REQUEST_STANDARD_NAME] (test.library.library_service.GetBook)\n" +
+			"test/library/a.proto:4:3: warning: rpc response type `Book` of `GetBook` is not named `GetBookResponse` [RPC_RESPONSE_STANDARD_NAME] (test.library.library_service.GetBook)"},
+		{name: "Ignored", in: `package test.library;
+// lint:ignore MESSAGE_PASCAL_CASE
+message book {
+  string Title = 1; // lint:ignore FIELD_LOWER_SNAKE_CASE
+  // Kept for compatibility, lint:ignore MESSAGE_PASCAL_CASE
+  string Author = 2;
+}`, want: "test/library/a.proto:6:3: warning: field name `Author` is not lower_snake_case [FIELD_LOWER_SNAKE_CASE] (test.library.book.Author)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParseString("test/library/a.proto", tt.in)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, Lint(nil, p).String())
+		})
+	}
+}
+
+func TestLint_Package(t *testing.T) {
+	p, err := ParseString("library/a.proto", "package test.library;")
+	assert.Nil(t, err)
+	assert.Equal(t, "library/a.proto:1:1: warning: package `test.library` is not in a directory ending with `test/library` [PACKAGE_DIRECTORY_MATCH] (test.library)", Lint(nil, p).String())
+
+	p, err = ParseString("protos/test/library/a.proto", "package test.library;")
+	assert.Nil(t, err)
+	assert.Empty(t, Lint(nil, p))
+}
+
+func TestLintConfig(t *testing.T) {
+	config, err := ParseLintConfig([]byte(`{"rules": {"ENUM_VALUE_PREFIX": false, "ENUM_PASCAL_CASE": true}}`))
+	assert.Nil(t, err)
+	assert.False(t, config.Enabled(LintEnumValuePrefix))
+	assert.True(t, config.Enabled(LintEnumPascalCase))
+	assert.True(t, config.Enabled(LintFieldLowerSnakeCase))
+	assert.Len(t, config.EnabledRules(), len(LintRules)-1)
+	assert.NotContains(t, config.EnabledRules(), LintEnumValuePrefix)
+
+	p, err := ParseString("a.proto", "enum Status {\n  ACTIVE_UNSPECIFIED = 0;\n}")
+	assert.Nil(t, err)
+	assert.Empty(t, Lint(config, p))
+
+	_, err = ParseLintConfig([]byte(`{"rules": {"UNKNOWN": false}}`))
+	assert.EqualError(t, err, "unknown lint rule UNKNOWN")
+	_, err = ParseLintConfig([]byte(`{`))
+	assert.NotNil(t, err)
+	_, err = ReadLintConfig("data/missing.json")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,40 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import "fmt"
+
+// Location is the span of a declaration in a protobuf source, from the first
+// character of the declaration to its terminator or closing brace.
+type Location struct {
+	File  string
+	Start Position
+	End   Position
+}
+
+// IsValid determines if the location was read from a source.
+func (l Location) IsValid() bool {
+	return l.Start.Line > 0
+}
+
+// String formats the location as file:line:column of its start.
+func (l Location) String() string {
+	if l.File == Empty {
+		return l.Start.String()
+	}
+	return fmt.Sprintf("%s:%s", l.File, l.Start)
+}
@@ -0,0 +1,44 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocation_String(t *testing.T) {
+	tests := []struct {
+		name string
+		in   Location
+		want string
+	}{
+		{name: "File", in: Location{File: "model.proto", Start: Position{Line: 42, Column: 3}}, want: "model.proto:42:3"},
+		{name: "No File", in: Location{Start: Position{Line: 1, Column: 1}}, want: "1:1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, tt.in.String(), "String()")
+		})
+	}
+}
+
+func TestLocation_IsValid(t *testing.T) {
+	assert.True(t, Location{Start: Position{Line: 1, Column: 1}}.IsValid())
+	assert.False(t, Location{}.IsValid())
+}
@@ -16,17 +16,31 @@
 
 package proto
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"os"
+)
 
 // Logger is a simplified logger for making the code and outputFlag readable.
 type Logger struct {
 	debug bool
+	// writer receives the messages, the standard output when nil
+	writer io.Writer
+}
+
+func (l Logger) printf(format string, in string) {
+	w := l.writer
+	if w == nil {
+		w = os.Stdout
+	}
+	_, _ = fmt.Fprintf(w, format, in)
 }
 
 // Debug prints messages with a DEBUG prefix and only if debugFlag is enabled.
 func (l Logger) Debug(in string) {
 	if l.debug {
-		fmt.Printf(DebugColor, in)
+		l.printf(DebugColor, in)
 	}
 }
 
@@ -37,7 +51,7 @@ func (l Logger) Debugf(in string, args ...any) {
 
 // Error prints a red error outputFlag
 func (l Logger) Error(in string) {
-	fmt.Printf(ErrorColor, in)
+	l.printf(ErrorColor, in)
 }
 
 // Errorf prints a formatted error
@@ -45,9 +59,19 @@ func (l Logger) Errorf(in string, args ...any) {
 	l.Error(fmt.Sprintf(in, args...))
 }
 
+// Warn prints a yellow warning outputFlag
+func (l Logger) Warn(in string) {
+	l.printf(WarnColor, in)
+}
+
+// Warnf prints a formatted warning
+func (l Logger) Warnf(in string, args ...any) {
+	l.Warn(fmt.Sprintf(in, args...))
+}
+
 // Info prints an information statement to outputFlag in teal.
 func (l Logger) Info(in string) {
-	fmt.Printf(InfoColor, in)
+	l.printf(InfoColor, in)
 }
 
 // Infof prints a formatted info stream
@@ -1,6 +1,12 @@
 package proto
 
-import "testing"
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
 
 func TestLogger_Debug(t *testing.T) {
 	type fields struct {
@@ -183,3 +189,17 @@ func TestLogger_Infof(t *testing.T) {
 		})
 	}
 }
+
+func TestLogger_Warnf(t *testing.T) {
+	l := Logger{}
+	l.Warnf("Test %s\n", "warning")
+}
+
+func TestSetOutput(t *testing.T) {
+	var out bytes.Buffer
+	SetOutput(&out)
+	defer SetOutput(nil)
+	Log.Infof("Reading %s\n", "a")
+	Log.Errorf("failed\n")
+	assert.Equal(t, fmt.Sprintf(InfoColor, "Reading a\n")+fmt.Sprintf(ErrorColor, "failed\n"), out.String())
+}
@@ -60,6 +60,12 @@ func (mt *MarkdownTable) Insert(data ...string) {
 	mt.Data = append(mt.Data, data)
 }
 
+// EscapeTableCell escapes the pipes of a value written in a table cell, which
+// would otherwise end the cell.
+func EscapeTableCell(in string) string {
+	return strings.ReplaceAll(in, Pipe, `\`+Pipe)
+}
+
 func ComputeFormat(length int, value string) string {
 	out := value
 	for i := 0; i < length-len(value); i++ {
@@ -23,6 +23,13 @@ type Message struct {
 	Messages   []*Message
 	Enums      []*Enum
 	Reserved   []*Reserved
+	// Extensions are the proto2 extension ranges of the message.
+	Extensions []*ExtensionRange
+	Oneofs     []*Oneof
+	// Extends are the extend blocks declared in the message.
+	Extends  []*Extension
+	Options  []*Option
+	Features *Features
 }
 
 // NewMessage creates a new message
@@ -33,9 +40,18 @@ func NewMessage() *Message {
 		Messages:   make([]*Message, 0),
 		Enums:      make([]*Enum, 0),
 		Reserved:   make([]*Reserved, 0),
+		Extensions: make([]*ExtensionRange, 0),
+		Oneofs:     make([]*Oneof, 0),
+		Extends:    make([]*Extension, 0),
+		Options:    make([]*Option, 0),
 	}
 }
 
+// IsValid implements the Validatable interface
+func (m *Message) IsValid() bool {
+	return len(m.Name) > 0
+}
+
 func (m *Message) HasAttributes() bool {
 	return len(m.Attributes) > 0
 }
@@ -47,3 +63,15 @@ func (m *Message) HasMessages() bool {
 func (m *Message) HasEnums() bool {
 	return len(m.Enums) > 0
 }
+
+func (m *Message) HasOneofs() bool {
+	return len(m.Oneofs) > 0
+}
+
+func (m *Message) HasExtends() bool {
+	return len(m.Extends) > 0
+}
+
+func (m *Message) HasExtensions() bool {
+	return len(m.Extensions) > 0
+}
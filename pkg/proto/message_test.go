@@ -113,6 +113,10 @@ func TestNewMessage(t *testing.T) {
 			Messages:   make([]*Message, 0),
 			Enums:      make([]*Enum, 0),
 			Reserved:   make([]*Reserved, 0),
+			Extensions: make([]*ExtensionRange, 0),
+			Oneofs:     make([]*Oneof, 0),
+			Extends:    make([]*Extension, 0),
+			Options:    make([]*Option, 0),
 		}},
 	}
 	for _, tt := range tests {
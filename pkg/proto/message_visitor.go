@@ -40,6 +40,8 @@ func (mv *MessageVisitor) Visit(scanner Scanner, in *Line, namespace string) int
 	out.Name = values[1]
 	out.Qualifier = Join(Period, namespace, out.Name)
 	out.Comment = in.Comment
+	out.Location = in.Location
+	out.Comments = in.Comments
 
 	var comment = Comment("")
 
@@ -51,6 +53,7 @@ func (mv *MessageVisitor) Visit(scanner Scanner, in *Line, namespace string) int
 		if strings.HasSuffix(line.Token, CloseBrace) {
 			break
 		}
+		ReportUnrecognized(scanner, RegisteredVisitors, line, Join(Period, namespace, out.Name))
 		for _, visitor := range RegisteredVisitors {
 			if visitor.CanVisit(line) {
 				rt := visitor.Visit(
@@ -72,8 +75,36 @@ func (mv *MessageVisitor) Visit(scanner Scanner, in *Line, namespace string) int
 						out.Attributes = append(out.Attributes, t)
 						comment = comment.Clear()
 					}
+				case *Oneof:
+					t.Comment = comment.AddSpace().Append(t.Comment).TrimSpace()
+					out.Oneofs = append(out.Oneofs, t)
+					out.Attributes = append(out.Attributes, t.Attributes...)
+					out.Messages = append(out.Messages, t.Messages...)
+					comment = comment.Clear()
+				case *Extension:
+					t.Comment = comment.AddSpace().Append(t.Comment).TrimSpace()
+					out.Extends = append(out.Extends, t)
+					out.Messages = append(out.Messages, t.Messages...)
+					comment = comment.Clear()
+				case *Group:
+					t.Attribute.Comment = comment.AddSpace().Append(t.Attribute.Comment).TrimSpace()
+					t.Message.Comment = t.Attribute.Comment
+					out.Attributes = append(out.Attributes, t.Attribute)
+					out.Messages = append(out.Messages, t.Message)
+					comment = comment.Clear()
+				case *Option:
+					t.Comment = comment.AddSpace().Append(t.Comment).TrimSpace()
+					out.Options = append(out.Options, t)
+					if IsFeature(t.Name) {
+						out.Features = SetFeature(out.Features, t.Name, t.Value)
+					}
+					comment = comment.Clear()
 				case *Reserved:
+					t.Comment = comment.AddSpace().Append(t.Comment).TrimSpace()
 					out.Reserved = append(out.Reserved, t)
+					comment = comment.Clear()
+				case []*ExtensionRange:
+					out.Extensions = append(out.Extensions, t...)
 				case Comment:
 					comment = comment.Append(t).AddSpace()
 				}
@@ -118,16 +118,24 @@ func TestMessageVisitor_Visit(t *testing.T) {
 							Namespace: "test.Test.TestEnum",
 							Ordinal:   0,
 							Value:     "T1",
+							Options:   make([]*Option, 0),
 						},
 						{
 							Namespace: "test.Test.TestEnum",
 							Ordinal:   1,
 							Value:     "T2",
+							Options:   make([]*Option, 0),
 						},
 					},
+					Reserved: make([]*Reserved, 0),
+					Options:  make([]*Option, 0),
 				},
 			},
-			Reserved: make([]*Reserved, 0),
+			Reserved:   make([]*Reserved, 0),
+			Extensions: make([]*ExtensionRange, 0),
+			Oneofs:     make([]*Oneof, 0),
+			Extends:    make([]*Extension, 0),
+			Options:    make([]*Option, 0),
 		}},
 	}
 	for _, tt := range tests {
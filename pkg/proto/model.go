@@ -18,9 +18,11 @@ package proto
 
 // NamedValue is super class to capture names and values for typed lines.
 type NamedValue struct {
-	Name    string
-	Value   string
-	Comment Comment
+	Name     string
+	Value    string
+	Comment  Comment
+	Comments *Comments
+	Location Location
 }
 
 func (namedValue *NamedValue) GetAnchor() string {
@@ -32,4 +34,6 @@ type Qualified struct {
 	Qualifier string
 	Name      string
 	Comment   Comment
+	Comments  *Comments
+	Location  Location
 }
@@ -0,0 +1,46 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+// Oneof is a set of mutually exclusive attributes in a message.
+type Oneof struct {
+	*Qualified
+	Attributes []*Attribute
+	// Messages are the nested messages of proto2 groups declared in the oneof.
+	Messages []*Message
+}
+
+// NewOneof is the Oneof constructor
+func NewOneof(namespace string, name string, comment Comment) *Oneof {
+	return &Oneof{
+		Qualified: &Qualified{
+			Qualifier: Join(Period, namespace, name),
+			Name:      name,
+			Comment:   comment,
+		},
+		Attributes: make([]*Attribute, 0),
+		Messages:   make([]*Message, 0),
+	}
+}
+
+// AddAttribute adds member attributes to the oneof.
+func (o *Oneof) AddAttribute(attributes ...*Attribute) {
+	for _, a := range attributes {
+		a.Oneof = o.Name
+	}
+	o.Attributes = append(o.Attributes, attributes...)
+}
@@ -0,0 +1,43 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewOneof(t *testing.T) {
+	assert.Equal(t, &Oneof{
+		Qualified: &Qualified{
+			Qualifier: "test.Payment.method",
+			Name:      "method",
+			Comment:   "Payment method",
+		},
+		Attributes: make([]*Attribute, 0),
+		Messages:   make([]*Message, 0),
+	}, NewOneof("test.Payment", "method", "Payment method"))
+}
+
+func TestOneof_AddAttribute(t *testing.T) {
+	o := NewOneof("test.Payment", "method", "")
+	a := NewAttribute("test.Payment", "")
+	o.AddAttribute(a)
+	assert.Equal(t, []*Attribute{a}, o.Attributes)
+	assert.Equal(t, "method", a.Oneof)
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOneofVisitor_CanVisit(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *Line
+		want bool
+	}{
+		{name: "Oneof", in: NewLine("oneof method {"), want: true},
+		{name: "Message", in: NewLine("message method {"), want: false},
+		{name: "Attribute", in: NewLine("string oneof = 1;"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ov := NewOneofVisitor()
+			assert.Equalf(t, tt.want, ov.CanVisit(tt.in), "CanVisit(%v)", tt.in)
+		})
+	}
+}
+
+func TestOneofVisitor_Visit(t *testing.T) {
+	scanner := NewTestScanner(`// Payment by card
+Card card = 2;
+string account = 3; // Payment by account
+}`)
+	ov := NewOneofVisitor()
+	out, ok := ov.Visit(scanner, NewLine("oneof method { // The method"), "test.Payment").(*Oneof)
+	assert.True(t, ok)
+	assert.Equal(t, "method", out.Name)
+	assert.Equal(t, "test.Payment.method", out.Qualifier)
+	assert.Equal(t, Comment("The method"), out.Comment)
+	assert.Len(t, out.Attributes, 2)
+	assert.Equal(t, "card", out.Attributes[0].Name)
+	assert.Equal(t, Comment("Payment by card"), out.Attributes[0].Comment)
+	assert.Equal(t, "method", out.Attributes[0].Oneof)
+	assert.Equal(t, "account", out.Attributes[1].Name)
+	assert.Equal(t, Comment("Payment by account"), out.Attributes[1].Comment)
+}
+
+func TestMessageVisitor_VisitOneof(t *testing.T) {
+	scanner := NewTestScanner(`string id = 1;
+oneof method {
+Card card = 2;
+string account = 3;
+}
+int64 amount = 4;
+}`)
+	mv := &MessageVisitor{}
+	out := mv.Visit(scanner, NewLine("message Payment {"), "test").(*Message)
+	assert.Len(t, out.Oneofs, 1)
+	assert.Len(t, out.Attributes, 4)
+	assert.Equal(t, "amount", out.Attributes[3].Name)
+	assert.Empty(t, out.Attributes[3].Oneof)
+}
@@ -0,0 +1,343 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// OptionValueKind identifies the shape of an option value.
+type OptionValueKind int
+
+const (
+	// OptionValueScalar is a string or numeric literal, e.g. "abc", 42 or -1.5
+	OptionValueScalar OptionValueKind = iota
+	// OptionValueIdentifier is a bare identifier, e.g. true, SPEED or inf
+	OptionValueIdentifier
+	// OptionValueList is a bracketed list of values, e.g. [1, 2, 3]
+	OptionValueList
+	// OptionValueMessage is an aggregate (text format) message literal, e.g. { a: 1 }
+	OptionValueMessage
+)
+
+// OptionValue is the structured representation of the constant assigned to
+// a file, message, field, enum, enum value, service or rpc option.
+type OptionValue struct {
+	Kind OptionValueKind
+	// Value is the text of a scalar or identifier. Strings are stored unescaped
+	// and without quotes.
+	Value string
+	// Quoted is true when the scalar is a string literal.
+	Quoted bool
+	List   []*OptionValue
+	Fields []*OptionField
+}
+
+// OptionField is a named field of an aggregate option value. Extension and
+// Any type URL names keep their brackets, e.g. [my.ext].
+type OptionField struct {
+	Name  string
+	Value *OptionValue
+}
+
+// NewOptionValue parses the constant of an option. Values that can not be
+// parsed are kept as an unquoted scalar of the original text.
+func NewOptionValue(in string) *OptionValue {
+	out, err := ParseOptionValue(in)
+	if err != nil {
+		Log.Debugf("unable to parse option value `%s`: %v", in, err)
+		return &OptionValue{Kind: OptionValueScalar, Value: strings.TrimSpace(in)}
+	}
+	return out
+}
+
+// ParseOptionValue parses a protobuf constant, including aggregate values
+// written in the protobuf text format.
+func ParseOptionValue(in string) (*OptionValue, error) {
+	p := &optionValueParser{tokens: TokenizeOptionValue(in)}
+	out, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected `%s` after value", p.peek())
+	}
+	return out, nil
+}
+
+// Get returns the value of the first field with the given name, or nil when
+// the value is not a message or the field is not set.
+func (v *OptionValue) Get(name string) *OptionValue {
+	if v == nil {
+		return nil
+	}
+	for _, f := range v.Fields {
+		if f.Name == name {
+			return f.Value
+		}
+	}
+	return nil
+}
+
+// Text returns the value for display, strings are returned without quotes.
+func (v *OptionValue) Text() string {
+	if v == nil {
+		return Empty
+	}
+	if v.Kind == OptionValueScalar && v.Quoted {
+		return v.Value
+	}
+	return v.String()
+}
+
+// String renders the value in the protobuf text format on a single line.
+func (v *OptionValue) String() string {
+	if v == nil {
+		return Empty
+	}
+	switch v.Kind {
+	case OptionValueList:
+		values := make([]string, 0, len(v.List))
+		for _, l := range v.List {
+			values = append(values, l.String())
+		}
+		return OpenBracket + strings.Join(values, Comma+Space) + ClosedBracket
+	case OptionValueMessage:
+		if len(v.Fields) == 0 {
+			return OpenBrace + CloseBrace
+		}
+		fields := make([]string, 0, len(v.Fields))
+		for _, f := range v.Fields {
+			if f.Value.Kind == OptionValueMessage {
+				fields = append(fields, Join(Space, f.Name, f.Value.String()))
+			} else {
+				fields = append(fields, f.Name+": "+f.Value.String())
+			}
+		}
+		return Join(Space, OpenBrace, strings.Join(fields, Space), CloseBrace)
+	default:
+		if v.Quoted {
+			return strconv.Quote(v.Value)
+		}
+		return v.Value
+	}
+}
+
+// TokenizeOptionValue splits a text format value into tokens. String literals
+// are returned with their quotes and escapes intact.
+func TokenizeOptionValue(in string) []string {
+	out := make([]string, 0)
+	runes := []rune(in)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			i++
+		case r == '#':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '"' || r == '\'':
+			start := i
+			i++
+			for i < len(runes) && runes[i] != r {
+				if runes[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+			if i > len(runes) {
+				i = len(runes)
+			}
+			out = append(out, string(runes[start:i]))
+		case strings.ContainsRune("{}<>[]:,;-", r):
+			out = append(out, string(r))
+			i++
+		default:
+			start := i
+			for i < len(runes) && !strings.ContainsRune(" \t\n\r#\"'{}<>[]:,;", runes[i]) {
+				// Allow signed exponents, e.g. 1e-10
+				if runes[i] == '-' && !(i > start && strings.ContainsRune("eE", runes[i-1]) && isNumberStart(runes[start])) {
+					break
+				}
+				i++
+			}
+			out = append(out, string(runes[start:i]))
+		}
+	}
+	return out
+}
+
+func isNumberStart(r rune) bool {
+	return (r >= '0' && r <= '9') || r == '.'
+}
+
+// IsQuoted determines if the token is a string literal.
+func IsQuoted(token string) bool {
+	return strings.HasPrefix(token, DoubleQuote) || strings.HasPrefix(token, SingleQuote)
+}
+
+// UnquoteString removes the quotes of a string literal and resolves its escape
+// sequences. Invalid escapes are kept as written.
+func UnquoteString(in string) string {
+	if len(in) < 2 {
+		return in
+	}
+	body := in[1 : len(in)-1]
+	var out strings.Builder
+	for len(body) > 0 {
+		value, _, tail, err := strconv.UnquoteChar(body, in[0])
+		if err != nil && len(body) > 1 && body[0] == '\\' && (body[1] == '"' || body[1] == '\'') {
+			out.WriteByte(body[1])
+			body = body[2:]
+			continue
+		}
+		if err != nil {
+			out.WriteByte(body[0])
+			body = body[1:]
+			continue
+		}
+		out.WriteRune(value)
+		body = tail
+	}
+	return out.String()
+}
+
+type optionValueParser struct {
+	tokens []string
+	index  int
+}
+
+func (p *optionValueParser) done() bool {
+	return p.index >= len(p.tokens)
+}
+
+func (p *optionValueParser) peek() string {
+	if p.done() {
+		return Empty
+	}
+	return p.tokens[p.index]
+}
+
+func (p *optionValueParser) next() string {
+	out := p.peek()
+	p.index++
+	return out
+}
+
+func (p *optionValueParser) parseValue() (*OptionValue, error) {
+	if p.done() {
+		return nil, fmt.Errorf("missing value")
+	}
+	token := p.next()
+	switch {
+	case token == OpenBrace:
+		return p.parseMessage(CloseBrace)
+	case token == "<":
+		return p.parseMessage(">")
+	case token == OpenBracket:
+		return p.parseList()
+	case token == Hyphen:
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if value.Kind == OptionValueList || value.Kind == OptionValueMessage || value.Quoted {
+			return nil, fmt.Errorf("unexpected `-` before `%s`", value)
+		}
+		return &OptionValue{Kind: OptionValueScalar, Value: Hyphen + value.Value}, nil
+	case IsQuoted(token):
+		// Adjacent string literals are concatenated
+		value := UnquoteString(token)
+		for IsQuoted(p.peek()) {
+			value += UnquoteString(p.next())
+		}
+		return &OptionValue{Kind: OptionValueScalar, Value: value, Quoted: true}, nil
+	case strings.ContainsAny(token, "{}<>[]:,;"):
+		return nil, fmt.Errorf("unexpected `%s`", token)
+	case isNumberStart([]rune(token)[0]):
+		return &OptionValue{Kind: OptionValueScalar, Value: token}, nil
+	default:
+		return &OptionValue{Kind: OptionValueIdentifier, Value: token}, nil
+	}
+}
+
+func (p *optionValueParser) parseList() (*OptionValue, error) {
+	out := &OptionValue{Kind: OptionValueList, List: make([]*OptionValue, 0)}
+	if p.peek() == ClosedBracket {
+		p.next()
+		return out, nil
+	}
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		out.List = append(out.List, value)
+		switch p.next() {
+		case Comma:
+			continue
+		case ClosedBracket:
+			return out, nil
+		default:
+			return nil, fmt.Errorf("unterminated list")
+		}
+	}
+}
+
+func (p *optionValueParser) parseMessage(terminator string) (*OptionValue, error) {
+	out := &OptionValue{Kind: OptionValueMessage, Fields: make([]*OptionField, 0)}
+	for {
+		if p.done() {
+			return nil, fmt.Errorf("missing `%s`", terminator)
+		}
+		name := p.next()
+		if name == terminator {
+			return out, nil
+		}
+		if name == OpenBracket {
+			// Extension or Any type URL, e.g. [my.ext] or [type.googleapis.com/my.Type]
+			for !p.done() && p.peek() != ClosedBracket {
+				name += p.next()
+			}
+			name += p.next()
+		}
+		if strings.HasPrefix(name, OpenBracket) && !strings.HasSuffix(name, ClosedBracket) ||
+			!strings.HasPrefix(name, OpenBracket) && strings.ContainsAny(name, "{}<>[]:,;\"'") {
+			return nil, fmt.Errorf("unexpected `%s`, expected a field name", name)
+		}
+		colon := p.peek() == ":"
+		if colon {
+			p.next()
+		}
+		next := p.peek()
+		if !colon && next != OpenBrace && next != "<" {
+			return nil, fmt.Errorf("missing `:` after field `%s`", name)
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		out.Fields = append(out.Fields, &OptionField{Name: name, Value: value})
+		if p.peek() == Comma || p.peek() == Semicolon {
+			p.next()
+		}
+	}
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOptionValue(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want *OptionValue
+	}{
+		{name: "String", in: `"a \"b\"\n"`, want: &OptionValue{Kind: OptionValueScalar, Value: "a \"b\"\n", Quoted: true}},
+		{name: "Single Quoted", in: `'it\'s'`, want: &OptionValue{Kind: OptionValueScalar, Value: "it's", Quoted: true}},
+		{name: "Concatenated", in: `"a" 'b'`, want: &OptionValue{Kind: OptionValueScalar, Value: "ab", Quoted: true}},
+		{name: "Number", in: "1.5e-3", want: &OptionValue{Kind: OptionValueScalar, Value: "1.5e-3"}},
+		{name: "Negative", in: "-10", want: &OptionValue{Kind: OptionValueScalar, Value: "-10"}},
+		{name: "Negative Infinity", in: "-inf", want: &OptionValue{Kind: OptionValueScalar, Value: "-inf"}},
+		{name: "Identifier", in: "SPEED", want: &OptionValue{Kind: OptionValueIdentifier, Value: "SPEED"}},
+		{name: "List", in: "[1, 2]", want: &OptionValue{Kind: OptionValueList, List: []*OptionValue{
+			{Kind: OptionValueScalar, Value: "1"},
+			{Kind: OptionValueScalar, Value: "2"},
+		}}},
+		{name: "Message", in: `{ a: 1, b: "x"; nested < c: true > [my.ext] { } }`, want: &OptionValue{Kind: OptionValueMessage, Fields: []*OptionField{
+			{Name: "a", Value: &OptionValue{Kind: OptionValueScalar, Value: "1"}},
+			{Name: "b", Value: &OptionValue{Kind: OptionValueScalar, Value: "x", Quoted: true}},
+			{Name: "nested", Value: &OptionValue{Kind: OptionValueMessage, Fields: []*OptionField{
+				{Name: "c", Value: &OptionValue{Kind: OptionValueIdentifier, Value: "true"}},
+			}}},
+			{Name: "[my.ext]", Value: &OptionValue{Kind: OptionValueMessage, Fields: []*OptionField{}}},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseOptionValue(tt.in)
+			assert.Nil(t, err)
+			assert.Equalf(t, tt.want, got, "ParseOptionValue(%v)", tt.in)
+		})
+	}
+}
+
+func TestParseOptionValue_Invalid(t *testing.T) {
+	for _, in := range []string{"", "{ a: 1", "[1, 2", "{ a 1 }", "1 2", "- \"a\"", "}"} {
+		_, err := ParseOptionValue(in)
+		assert.NotNilf(t, err, "ParseOptionValue(%v)", in)
+	}
+}
+
+func TestNewOptionValue(t *testing.T) {
+	assert.Equal(t, &OptionValue{Kind: OptionValueScalar, Value: "{ a: 1"}, NewOptionValue(" { a: 1 "))
+}
+
+func TestOptionValue_String(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		text string
+	}{
+		{in: `'a"b'`, want: `"a\"b"`, text: `a"b`},
+		{in: "[ 1,2 ]", want: "[1, 2]", text: "[1, 2]"},
+		{in: "{}", want: "{}", text: "{}"},
+		{in: `{ get: '/v1' additional_bindings: { post: "/v2" } [type.googleapis.com/a.B] { c: [X, Y] } }`,
+			want: `{ get: "/v1" additional_bindings { post: "/v2" } [type.googleapis.com/a.B] { c: [X, Y] } }`,
+			text: `{ get: "/v1" additional_bindings { post: "/v2" } [type.googleapis.com/a.B] { c: [X, Y] } }`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			v := NewOptionValue(tt.in)
+			assert.Equal(t, tt.want, v.String())
+			assert.Equal(t, tt.text, v.Text())
+		})
+	}
+}
+
+func TestOptionValue_Get(t *testing.T) {
+	v := NewOptionValue(`{ get: "/v1" body: "*" }`)
+	assert.Equal(t, "/v1", v.Get("get").Value)
+	assert.Equal(t, "*", v.Get("body").Value)
+	assert.Nil(t, v.Get("post"))
+	assert.Nil(t, v.Get("get").Get("get"))
+	var missing *OptionValue
+	assert.Nil(t, missing.Get("get"))
+}
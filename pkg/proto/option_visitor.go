@@ -20,28 +20,73 @@ import (
 	"strings"
 )
 
+// Option is a `name = constant` pair applied to a file or to one of its
+// declarations. Value holds the constant for display, while Constant holds
+// the structured value, including aggregate (text format) values.
 type Option struct {
 	*NamedValue
+	Constant *OptionValue
+}
+
+// NewOption is the Option constructor, parsing the constant of the option.
+func NewOption(name string, constant string, comment Comment) *Option {
+	value := NewOptionValue(constant)
+	return &Option{
+		NamedValue: &NamedValue{
+			Name:    name,
+			Value:   value.Text(),
+			Comment: comment,
+		},
+		Constant: value,
+	}
+}
+
+// ParseOption reads an option from its `name = constant` text. Whitespace in
+// the name is removed, e.g. `( my.option ).field` becomes `(my.option).field`.
+func ParseOption(in string, comment Comment) *Option {
+	split := strings.SplitN(in, "=", 2)
+	if len(split) != 2 || len(strings.TrimSpace(split[0])) == 0 {
+		return &Option{
+			NamedValue: &NamedValue{Name: "Invalid"},
+		}
+	}
+	return NewOption(SpaceRemover.ReplaceAllString(split[0], Empty), strings.TrimSpace(split[1]), comment)
+}
+
+// ParseCompactOptions reads the bracketed options of a field or an enum value,
+// e.g. `[deprecated = true, (my.option) = { a: 1 }]`.
+func ParseCompactOptions(in string) []*Option {
+	out := make([]*Option, 0)
+	for _, o := range CompactOptions(in) {
+		if option := ParseOption(o, Empty); option.Name != "Invalid" {
+			out = append(out, option)
+		}
+	}
+	return out
 }
 
 type OptionVisitor struct {
 }
 
+// CanVisit determines if the line is an option statement, aggregate options
+// end in an open brace.
 func (ov *OptionVisitor) CanVisit(in *Line) bool {
-	return strings.HasPrefix(in.Syntax, "option ") && in.Token == Semicolon
+	return strings.HasPrefix(in.Syntax, PrefixOption+Space) && (in.Token == Semicolon || in.Token == OpenBrace)
 }
 
-func (ov *OptionVisitor) Visit(_ Scanner, in *Line, _ string) interface{} {
+// Visit marshals an option, reading aggregate values until the end of the
+// statement. Malformed options are reported and skipped.
+func (ov *OptionVisitor) Visit(scanner Scanner, in *Line, namespace string) interface{} {
 	Log.Debug("Visiting Option")
-	fValues := in.SplitSyntax()
-	if len(fValues) == 4 {
-		return &Option{&NamedValue{
-			Name:    fValues[1],
-			Value:   strings.ReplaceAll(fValues[3], `"`, ""),
-			Comment: in.Comment[:],
-		}}
+	if in.Token == OpenBrace {
+		in = ReadStatement(scanner, in)
 	}
-	return &Option{
-		NamedValue: &NamedValue{Name: "Invalid"},
+	out := ParseOption(in.Syntax[len(PrefixOption):], in.Comment)
+	if out.Name == "Invalid" {
+		Report(scanner, SeverityError, in, namespace, "invalid option `%s`", in.Syntax)
+		return nil
 	}
+	out.Location = in.Location
+	out.Comments = in.Comments
+	return out
 }
@@ -34,6 +34,9 @@ func TestOptionVisitor_CanVisit(t *testing.T) {
 		{name: "Can Visit",
 			args: args{in: &Line{Syntax: "option java_package = \"com.google.test\"", Token: ";"}},
 			want: true},
+		{name: "Can Visit Aggregate",
+			args: args{in: &Line{Syntax: "option (my.option) =", Token: "{"}},
+			want: true},
 		{name: "Can't Visit",
 			args: args{in: &Line{Syntax: "This is a comment", Token: "//"}},
 			want: false},
@@ -64,7 +67,15 @@ func TestOptionVisitor_Visit(t *testing.T) {
 			want: &Option{NamedValue: &NamedValue{
 				Name:  "java_package",
 				Value: "gcp.proto.test.location",
-			}}},
+			}, Constant: &OptionValue{Kind: OptionValueScalar, Value: "gcp.proto.test.location", Quoted: true}}},
+		{name: "Visit Custom", args: args{in0: testScanner, in: &Line{Syntax: "option (my.option).enabled = true", Token: ";", Comment: "Enabled"}, in2: "test"},
+			want: &Option{NamedValue: &NamedValue{
+				Name:    "(my.option).enabled",
+				Value:   "true",
+				Comment: "Enabled",
+			}, Constant: &OptionValue{Kind: OptionValueIdentifier, Value: "true"}}},
+		{name: "Visit Invalid", args: args{in0: testScanner, in: &Line{Syntax: "option java_package", Token: ";"}, in2: "test"},
+			want: nil},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -72,4 +83,85 @@ func TestOptionVisitor_Visit(t *testing.T) {
 			assert.Equalf(t, tt.want, ov.Visit(tt.args.in0, tt.args.in, tt.args.in2), "Visit(%v, %v, %v)", tt.args.in0, tt.args.in, tt.args.in2)
 		})
 	}
+	assert.Len(t, testScanner.Diagnostics(), 1)
+	assert.Equal(t, "0:0: error: invalid option `option java_package` (test)", testScanner.Diagnostics()[0].String())
+}
+
+func TestOptionVisitor_VisitAggregate(t *testing.T) {
+	testScanner := NewTestScanner(`
+    a: 1 b: "x"
+    // Nested values
+    nested {
+      c: true }
+  }
+  ;
+message Next {`)
+	ov := &OptionVisitor{}
+	out := ov.Visit(testScanner, &Line{Syntax: "option (my.opt) =", Token: OpenBrace, Comment: "Custom"}, "test").(*Option)
+	assert.Equal(t, "(my.opt)", out.Name)
+	assert.Equal(t, Comment("Custom"), out.Comment)
+	assert.Equal(t, `{ a: 1 b: "x" nested { c: true } }`, out.Value)
+	assert.Equal(t, OptionValueMessage, out.Constant.Kind)
+	assert.Equal(t, "true", out.Constant.Get("nested").Get("c").Value)
+	assert.True(t, testScanner.Scan())
+	assert.Equal(t, "message Next {", testScanner.Text())
+}
+
+func TestPackage_ReadOptions(t *testing.T) {
+	p := NewPackage("data/test/options/options.proto")
+	diagnostics, err := p.Read(false)
+	assert.Nil(t, err)
+	assert.Empty(t, diagnostics)
+	assert.Len(t, p.Options, 3)
+	assert.Equal(t, "(test.file_info)", p.Options[0].Name)
+	assert.Equal(t, Comment("Custom file level options"), p.Options[0].Comment)
+	assert.Equal(t, "platform", p.Options[0].Constant.Get("owner").Value)
+	assert.Equal(t, OptionValueList, p.Options[0].Constant.Get("tags").Kind)
+	assert.Equal(t, "team@example.com", p.Options[0].Constant.Get("contact").Get("email").Value)
+	assert.Equal(t, "gcp.proto.test.options", p.Options[1].Value)
+	assert.Equal(t, "example.com/test/options;options", p.Options[2].Value)
+	assert.Equal(t, Comment("Package path and name"), p.Options[2].Comment)
+
+	request := p.Messages[0]
+	assert.Len(t, request.Attributes, 2)
+	assert.Equal(t, "64", request.Attributes[0].Annotations[0].Constant.Get("max_len").Value)
+	assert.Equal(t, "deprecated", request.Attributes[1].Annotations[0].Name)
+	assert.Equal(t, "-1", request.Attributes[1].Annotations[1].Constant.Get("gte").Value)
+}
+
+func TestParseCompactOptions(t *testing.T) {
+	assert.Equal(t, []*Option{
+		NewOption("deprecated", "true", ""),
+		NewOption("(my.option)", `{ a: 1, b: "c" }`, ""),
+	}, ParseCompactOptions(`A = 1 [deprecated = true, ( my.option ) = { a: 1, b: "c" }]`))
+	assert.Equal(t, []*Option{}, ParseCompactOptions("A = 1"))
+}
+
+func TestPackage_ReadDeclarationOptions(t *testing.T) {
+	p := NewPackage("data/test/options/options.proto")
+	diagnostics, err := p.Read(false)
+	assert.Nil(t, err)
+	assert.Empty(t, diagnostics)
+
+	book := p.Messages[1]
+	assert.Len(t, book.Options, 2)
+	assert.Equal(t, "deprecated", book.Options[0].Name)
+	assert.Equal(t, Comment("Books are replaced by volumes"), book.Options[0].Comment)
+	assert.Equal(t, "library/Book", book.Options[1].Constant.Get("type").Value)
+	assert.Len(t, book.Attributes, 2)
+
+	format := book.Enums[0]
+	assert.Len(t, format.Options, 1)
+	assert.Equal(t, "allow_alias", format.Options[0].Name)
+	assert.Len(t, format.Values, 4)
+	assert.Equal(t, "deprecated", format.Values[2].Options[0].Name)
+	assert.Equal(t, Comment("Kept for older clients"), format.Values[2].Comment)
+	assert.Equal(t, "E-Book", format.Values[3].Options[0].Constant.Get("text").Value)
+
+	library := p.Services[0]
+	assert.Len(t, library.Options, 1)
+	assert.Equal(t, "(google.api.default_host)", library.Options[0].Name)
+	assert.Equal(t, "library.example.com", library.Options[0].Value)
+	assert.Equal(t, Comment("The default host of the service"), library.Options[0].Comment)
+	assert.Len(t, library.Methods, 2)
 }
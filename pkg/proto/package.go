@@ -18,19 +18,32 @@ package proto
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strings"
 )
 
 // Package is the top level structure of any protobuf
 type Package struct {
 	Path     string
 	Name     string
+	Syntax   string
+	Edition  string
 	Comment  Comment
+	Comments *Comments
+	// Location is the location of the package statement.
+	Location Location
 	Options  []*Option
 	Imports  []*Import
 	Messages []*Message
 	Enums    []*Enum
 	Services []*Service
+	Extends  []*Extension
+	Features *Features
+	// Diagnostics are the problems reported while reading the package.
+	Diagnostics Diagnostics
+	// Bundled is set for the packages read from the BundledFiles.
+	Bundled bool
 }
 
 func NewPackage(path string) *Package {
@@ -40,33 +53,82 @@ func NewPackage(path string) *Package {
 		Messages: make([]*Message, 0),
 		Enums:    make([]*Enum, 0),
 		Services: make([]*Service, 0),
+		Extends:  make([]*Extension, 0),
 	}
 	return pkg
 }
 
-func (p *Package) Read(debug bool) error {
-	isDebug = debug
+// ParseReader reads a protobuf source into a package without accessing the
+// filesystem, name is the path of the package used in its locations and
+// output. The diagnostics are set on the package, the error is set when the
+// reader fails.
+func ParseReader(name string, r io.Reader) (*Package, error) {
+	pkg := NewPackage(name)
+	_, err := pkg.ReadSource(r)
+	return pkg, err
+}
+
+// ParseString reads a protobuf source held in memory into a package, see
+// ParseReader.
+func ParseString(name string, in string) (*Package, error) {
+	return ParseReader(name, strings.NewReader(in))
+}
 
+// Read parses the protobuf file of the package, returning the diagnostics
+// reported while reading it. The error is set when the file cannot be read,
+// debug logs the statements read to the debug output of the Log.
+func (p *Package) Read(debug bool) (Diagnostics, error) {
 	readFile, err := os.Open(p.Path)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer readFile.Close()
+	return p.readSource(readFile, debug)
+}
+
+// ReadSource parses the protobuf source read from r into the package, the
+// diagnostics reported while reading it are returned and set on the package.
+// The error is set when the reader fails.
+func (p *Package) ReadSource(r io.Reader) (Diagnostics, error) {
+	return p.readSource(r, Log.debug)
+}
+
+func (p *Package) readSource(r io.Reader, debug bool) (Diagnostics, error) {
+	scanner := NewProtobufReaderScanner(p.Path, r)
+	if err := scanner.Err(); err != nil {
+		return nil, err
 	}
-	scanner := NewProtobufFileScanner(readFile)
 
 	var comment = Comment("")
+	// The comments of the syntax statement document the package
+	var syntaxComments *Comments
 
 	for scanner.Scan() {
 		line := scanner.ReadLine()
 
-		Log.Debugf("Current Line: `%s`\n", line)
+		if debug {
+			Log.Debugf("Current Line: `%s`\n", line)
+		}
 
+		ReportUnrecognized(scanner, RegisteredVisitors, line, p.Name)
 		for _, visitor := range RegisteredVisitors {
 			if visitor.CanVisit(line) {
 				rt := visitor.Visit(scanner, line, p.Name)
 				switch t := rt.(type) {
+				case *Syntax:
+					if t.IsEdition() {
+						p.Edition = t.Value
+					} else {
+						p.Syntax = t.Value
+					}
+					comment = comment.AddSpace().Append(line.Comment)
+					syntaxComments = line.Comments
 				case *Option:
 					t.Comment = comment.AddSpace().Append(line.Comment).TrimSpace()
 					p.Options = append(p.Options, t)
+					if IsFeature(t.Name) {
+						p.Features = SetFeature(p.Features, t.Name, t.Value)
+					}
 					comment = comment.Clear()
 				case *Import:
 					t.Comment = comment.AddSpace().Append(line.Comment).TrimSpace()
@@ -80,6 +142,11 @@ func (p *Package) Read(debug bool) error {
 					t.Comment = comment.AddSpace().Append(line.Comment).TrimSpace()
 					p.Enums = append(p.Enums, t)
 					comment = comment.Clear()
+				case *Extension:
+					t.Comment = comment.AddSpace().Append(line.Comment).TrimSpace()
+					p.Extends = append(p.Extends, t)
+					p.Messages = append(p.Messages, t.Messages...)
+					comment = comment.Clear()
 				case *Service:
 					t.Comment = comment.AddSpace().Append(line.Comment).TrimSpace()
 					p.Services = append(p.Services, t)
@@ -87,7 +154,9 @@ func (p *Package) Read(debug bool) error {
 				case *Package:
 					t.Comment = comment.AddSpace().Append(line.Comment).TrimSpace()
 					p.Name = t.Name
-					p.Comment = comment.TrimSpace()
+					p.Location = line.Location
+					p.Comment = t.Comment
+					p.Comments = syntaxComments.Merge(line.Comments)
 					comment = comment.Clear()
 				case Comment:
 					comment = comment.AddSpace().Append(t)
@@ -97,13 +166,15 @@ func (p *Package) Read(debug bool) error {
 			}
 		}
 	}
-	return nil
+	ResolveFeatures(p)
+	p.Diagnostics = scanner.(Reporter).Diagnostics()
+	return p.Diagnostics, nil
 }
 
 func (p *Package) ToMarkdownWithDiagram() string {
 	out := fmt.Sprintf("# %s\n\n%s\n# Diagrams\n", p.Name, p.Comment)
 	out += "```mermaid\nclassDiagram\n"
-	out += PackageToMermaid(p)
+	out += PackageToMermaid(p, &WriterConfig{})
 	out += "\n```"
 	return out
 }
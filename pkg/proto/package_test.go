@@ -17,8 +17,12 @@
 package proto
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"os"
 	"testing"
+	"testing/iotest"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -41,6 +45,7 @@ func TestNewPackage(t *testing.T) {
 			Messages: make([]*Message, 0),
 			Enums:    make([]*Enum, 0),
 			Services: make([]*Service, 0),
+			Extends:  make([]*Extension, 0),
 		}},
 	}
 	for _, tt := range tests {
@@ -70,7 +75,8 @@ func TestPackage_Read(t *testing.T) {
 		args    args
 		wantErr assert.ErrorAssertionFunc
 	}{
-		// TODO: Add test cases.
+		{name: "Read", fields: fields{Path: "data/test/service/service.proto"}, wantErr: assert.NoError},
+		{name: "Missing File", fields: fields{Path: "data/test/missing.proto"}, wantErr: assert.Error},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -84,11 +90,147 @@ func TestPackage_Read(t *testing.T) {
 				Enums:    tt.fields.Enums,
 				Services: tt.fields.Services,
 			}
-			tt.wantErr(t, p.Read(tt.args.debug), fmt.Sprintf("Read(%v)", tt.args.debug))
+			_, err := p.Read(tt.args.debug)
+			tt.wantErr(t, err, fmt.Sprintf("Read(%v)", tt.args.debug))
 		})
 	}
 }
 
+func TestPackage_ReadDebug(t *testing.T) {
+	// The debug argument only selects the lines of the file logged, the Log
+	// is left as configured
+	var out bytes.Buffer
+	SetOutput(&out)
+	defer SetOutput(nil)
+	SetDebug(true)
+	defer SetDebug(false)
+	_, err := NewPackage("data/test/location/model.proto").Read(false)
+	assert.Nil(t, err)
+	assert.NotContains(t, out.String(), "Current Line: `package test.location;`")
+	_, err = NewPackage("data/test/location/model.proto").Read(true)
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), "Current Line: `package test.location;`")
+	assert.True(t, Log.debug)
+}
+
+func TestPackage_ReadLocations(t *testing.T) {
+	path := "data/test/options/options.proto"
+	p := NewPackage(path)
+	diagnostics, err := p.Read(false)
+	assert.Nil(t, err)
+	assert.Empty(t, diagnostics)
+
+	at := func(startLine, startColumn, endLine, endColumn int) Location {
+		return Location{
+			File:  path,
+			Start: Position{Line: startLine, Column: startColumn},
+			End:   Position{Line: endLine, Column: endColumn},
+		}
+	}
+	assert.Equal(t, at(17, 1, 17, 38), p.Imports[0].Location)
+	assert.Equal(t, at(22, 1, 26, 2), p.Options[0].Location)
+	assert.Equal(t, at(31, 1, 39, 1), p.Messages[0].Location)
+	assert.Equal(t, at(33, 3, 36, 5), p.Messages[0].Attributes[0].Location)
+	assert.Equal(t, at(44, 3, 44, 27), p.Messages[1].Options[0].Location)
+	assert.Equal(t, at(53, 3, 62, 3), p.Messages[1].Enums[0].Location)
+	assert.Equal(t, at(58, 5, 58, 37), p.Messages[1].Enums[0].Values[2].Location)
+	assert.Equal(t, at(58, 5, 58, 37), p.Messages[1].Enums[0].Values[2].Options[0].Location)
+	assert.Equal(t, at(66, 1, 83, 1), p.Services[0].Location)
+	assert.Equal(t, at(71, 3, 80, 3), p.Services[0].Methods[0].Location)
+	assert.Equal(t, at(73, 5, 78, 6), p.Services[0].Methods[0].Options[0].Location)
+	assert.Equal(t, at(82, 3, 82, 48), p.Services[0].Methods[1].Location)
+	assert.Equal(t, "data/test/options/options.proto:66:1", p.Services[0].Location.String())
+}
+
+func TestPackage_ReadDiagnostics(t *testing.T) {
+	p := NewPackage("data/test/diagnostics/invalid.proto")
+	diagnostics, err := p.Read(false)
+	assert.Nil(t, err)
+	messages := make([]string, 0)
+	for _, d := range diagnostics {
+		messages = append(messages, d.String())
+	}
+	assert.Equal(t, []string{
+		"data/test/diagnostics/invalid.proto:27:1: error: missing `;`",
+		"data/test/diagnostics/invalid.proto:17:1: error: invalid option `option = \"missing name\"` (test.diagnostics)",
+		"data/test/diagnostics/invalid.proto:21:3: error: invalid field `string name = one` (test.diagnostics.Broken)",
+		"data/test/diagnostics/invalid.proto:22:3: error: invalid field `repeated string` (test.diagnostics.Broken)",
+		"data/test/diagnostics/invalid.proto:23:3: error: reserved statement without ranges or names (test.diagnostics.Broken)",
+		"data/test/diagnostics/invalid.proto:25:3: warning: unrecognized statement `weird;` (test.diagnostics.Broken)",
+		"data/test/diagnostics/invalid.proto:30:3: error: invalid number `zero` of enum value STATE_UNSPECIFIED (test.diagnostics.State)",
+	}, messages)
+	assert.Equal(t, "6 errors, 1 warning", diagnostics.Summary())
+
+	// Valid declarations are still read
+	assert.Empty(t, p.Options)
+	assert.Len(t, p.Messages[0].Attributes, 2)
+	assert.Len(t, p.Enums[0].Values, 2)
+	assert.Equal(t, 1, p.Enums[0].Values[1].Ordinal)
+}
+
+func TestParseString(t *testing.T) {
+	p, err := ParseString("memory/library.proto", `syntax = "proto3";
+package library;
+
+// A book
+message Book {
+  string name = 1;
+  int32 pages = two;
+}`)
+	assert.Nil(t, err)
+	assert.Equal(t, "memory/library.proto", p.Path)
+	assert.Equal(t, "library", p.Name)
+	assert.Len(t, p.Messages, 1)
+	assert.Equal(t, Comment("A book"), p.Messages[0].Comment)
+	assert.Len(t, p.Messages[0].Attributes, 1)
+	assert.Equal(t, "memory/library.proto:5:1", p.Messages[0].Location.String())
+	assert.Equal(t, "memory/library.proto:7:3: error: invalid field `int32 pages = two` (library.Book)", p.Diagnostics.String())
+}
+
+func TestParseString_Comments(t *testing.T) {
+	p, err := ParseString("library.proto", `// Copyright
+
+// The library API
+syntax = "proto3";
+// Library types
+package library;
+
+// A book
+message Book {
+  string name = 1; // The name
+
+  // The ISBN
+  string isbn = 2;
+  // Not documenting isbn
+
+  // Unused
+}`)
+	assert.Nil(t, err)
+	assert.Equal(t, Comment("Copyright The library API Library types"), p.Comment)
+	assert.Equal(t, &Comments{Leading: "The library API Library types", Detached: []Comment{"Copyright"}}, p.Comments)
+	book := p.Messages[0]
+	assert.Equal(t, Comment("A book"), book.Comment)
+	assert.Equal(t, Comment("The name"), book.Attributes[0].Comment)
+	assert.Equal(t, &Comments{Leading: "The ISBN", Trailing: "Not documenting isbn", Detached: []Comment{}}, book.Attributes[1].Comments)
+
+	wc := &WriterConfig{comments: CommentsLeading}
+	assert.Equal(t, Comment("The ISBN"), wc.Comment(book.Attributes[1].Comment, book.Attributes[1].Comments))
+	assert.Equal(t, Comment("The library API Library types"), wc.Comment(p.Comment, p.Comments))
+}
+
+func TestParseReader(t *testing.T) {
+	file, err := os.Open("data/test/service/service.proto")
+	assert.Nil(t, err)
+	defer file.Close()
+	p, err := ParseReader("service.proto", file)
+	assert.Nil(t, err)
+	assert.Empty(t, p.Diagnostics)
+	assert.Len(t, p.Services, 1)
+
+	_, err = ParseReader("broken.proto", iotest.ErrReader(errors.New("broken")))
+	assert.EqualError(t, err, "broken")
+}
+
 func TestPackage_ToMarkdownWithDiagram(t *testing.T) {
 	type fields struct {
 		Path     string
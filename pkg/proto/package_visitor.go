@@ -20,8 +20,6 @@ import (
 	"strings"
 )
 
-var isDebug bool
-
 type PackageVisitor struct {
 }
 
@@ -29,8 +27,12 @@ func (pv *PackageVisitor) CanVisit(in *Line) bool {
 	return strings.HasPrefix(in.Syntax, "package") && in.Token == Semicolon
 }
 
-func (pv *PackageVisitor) Visit(_ Scanner, in *Line, _ string) interface{} {
+func (pv *PackageVisitor) Visit(scanner Scanner, in *Line, _ string) interface{} {
 	fValues := in.SplitSyntax()
+	if len(fValues) != 2 {
+		Report(scanner, SeverityError, in, Empty, "invalid package `%s`", in.Syntax)
+		return nil
+	}
 	return &Package{
 		Path:     "",
 		Name:     fValues[1],
@@ -40,5 +42,6 @@ func (pv *PackageVisitor) Visit(_ Scanner, in *Line, _ string) interface{} {
 		Messages: make([]*Message, 0),
 		Enums:    make([]*Enum, 0),
 		Services: make([]*Service, 0),
+		Extends:  make([]*Extension, 0),
 	}
 }
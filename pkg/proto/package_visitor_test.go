@@ -76,6 +76,7 @@ func TestPackageVisitor_Visit(t *testing.T) {
 			Messages: make([]*Message, 0),
 			Enums:    make([]*Enum, 0),
 			Services: make([]*Service, 0),
+			Extends:  make([]*Extension, 0),
 		}},
 	}
 	for _, tt := range tests {
@@ -0,0 +1,462 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"errors"
+	"strings"
+)
+
+// Statement is a node of the protobuf syntax tree. A statement is either a
+// comment, a declaration terminated by a semicolon, or a block declaration,
+// e.g. a message, whose Body holds the nested statements.
+type Statement struct {
+	// Comment is set when the statement is a comment
+	Comment *Token
+	// Comments are the comments attached to a declaration
+	Comments *Comments
+	// Tokens are the tokens of a declaration, excluding the terminator
+	Tokens []*Token
+	// Terminator is the semicolon or the open brace ending the declaration
+	Terminator *Token
+	Body       []*Statement
+	// Close is the closing brace of a block
+	Close *Token
+}
+
+// IsComment determines if the statement is a comment.
+func (s *Statement) IsComment() bool {
+	return s.Comment != nil
+}
+
+// IsBlock determines if the statement is a block declaration.
+func (s *Statement) IsBlock() bool {
+	return s.Terminator.Is(OpenBrace)
+}
+
+// Syntax returns the text of the declaration with whitespace between tokens
+// reduced to a single space, string literals are kept as written. The spacing
+// read by the visitors is canonical whatever the source spacing: `=` is
+// surrounded by spaces, `,` is followed by a space and map types are written
+// as `map<K, V>`.
+func (s *Statement) Syntax() string {
+	var out strings.Builder
+	mapType, closed := false, false
+	for i, t := range s.Tokens {
+		opens := i > 0 && opensMapType(s.Tokens[i-1], t)
+		if i > 0 {
+			previous := s.Tokens[i-1]
+			switch {
+			case t.Is("="), previous.Is("="), previous.Is(Comma), closed:
+				out.WriteString(Space)
+			case t.Is(Comma), mapType, opens:
+			case t.Spaced:
+				out.WriteString(Space)
+			}
+		}
+		closed = mapType && t.Is(CloseMap)
+		mapType = opens || (mapType && !closed)
+		out.WriteString(t.Text)
+	}
+	return out.String()
+}
+
+// opensMapType determines if the token is the `<` following the map keyword.
+func opensMapType(previous *Token, t *Token) bool {
+	return t.Is("<") && previous.Kind == TokenIdentifier && previous.Text == PrefixMap
+}
+
+// Start returns the position of the first character of the statement.
+func (s *Statement) Start() Position {
+	if s.IsComment() {
+		return s.Comment.Start
+	}
+	if len(s.Tokens) > 0 {
+		return s.Tokens[0].Start
+	}
+	if s.Terminator != nil {
+		return s.Terminator.Start
+	}
+	return Position{}
+}
+
+// End returns the position of the last character of the statement, that is
+// the closing brace of a block. The end of an unclosed block is the end of its
+// last nested statement.
+func (s *Statement) End() Position {
+	switch {
+	case s.IsComment():
+		return s.Comment.End
+	case s.Close != nil:
+		return s.Close.End
+	case s.IsBlock() && len(s.Body) > 0:
+		return s.Body[len(s.Body)-1].End()
+	case s.Terminator != nil:
+		return s.Terminator.End
+	case len(s.Tokens) > 0:
+		return s.Tokens[len(s.Tokens)-1].End
+	}
+	return Position{}
+}
+
+// Location returns the span of the statement in the given file.
+func (s *Statement) Location(file string) Location {
+	return Location{File: file, Start: s.Start(), End: s.End()}
+}
+
+// Lines flattens the statement into the lines read by the visitors, each line
+// carrying its location in the given file and its attached comments. Comment
+// statements are attached to the declarations and have no lines.
+func (s *Statement) Lines(file string) []*Line {
+	if s.IsComment() {
+		return make([]*Line, 0)
+	}
+	comment := s.Comments.Render(CommentsAll)
+	if !s.IsBlock() {
+		return []*Line{{Syntax: s.Syntax(), Token: Semicolon, Comment: comment, Comments: s.Comments, Location: s.Location(file)}}
+	}
+	out := []*Line{{Syntax: s.Syntax(), Token: OpenBrace, Comment: comment, Comments: s.Comments, Location: s.Location(file)}}
+	for _, b := range s.Body {
+		out = append(out, b.Lines(file)...)
+	}
+	closing := &Line{Token: CloseBrace}
+	if s.Close != nil {
+		closing.Location = Location{File: file, Start: s.Close.Start, End: s.Close.End}
+	}
+	return append(out, closing)
+}
+
+// NewCommentLine creates the line of a comment token. Line breaks of block
+// comments are kept as CommentNewLine.
+func NewCommentLine(t *Token) *Line {
+	if strings.HasPrefix(t.Text, InlineCommentPrefix) {
+		return &Line{Token: InlineCommentPrefix, Comment: Comment(t.Text[len(InlineCommentPrefix):]).TrimSpace()}
+	}
+	body := strings.TrimSuffix(t.Text[len(MultiLineCommentInitiator):], MultilineCommentTerminator)
+	return &Line{Token: MultiLineCommentInitiator, Comment: Comment(strings.ReplaceAll(body, EndL, CommentNewLine)).TrimSpace()}
+}
+
+// Parse reads a protobuf source into its statement tree. The statements that
+// could be read are returned along with any error found.
+func Parse(in string) ([]*Statement, error) {
+	tokens, err := Tokenize(in)
+	parser := NewParser(tokens)
+	out := parser.ParseFile()
+	return out, errors.Join(err, errors.Join(parser.Errors...))
+}
+
+// Parser is a recursive-descent parser building the statement tree of a
+// protobuf source from its tokens. Errors do not stop the parser, they are
+// collected in Errors.
+type Parser struct {
+	tokens   []*Token
+	index    int
+	comments map[*Token]*Comments
+	Errors   []error
+}
+
+// NewParser is the Parser constructor, attaching the comment tokens to the
+// declaration tokens.
+func NewParser(tokens []*Token) *Parser {
+	return &Parser{tokens: tokens, comments: AttachComments(tokens)}
+}
+
+func (p *Parser) done() bool {
+	return p.index >= len(p.tokens)
+}
+
+func (p *Parser) peek() *Token {
+	if p.done() {
+		return nil
+	}
+	return p.tokens[p.index]
+}
+
+func (p *Parser) next() *Token {
+	out := p.peek()
+	p.index++
+	return out
+}
+
+func (p *Parser) error(position Position, message string) {
+	p.Errors = append(p.Errors, &SyntaxError{Position: position, Message: message})
+}
+
+// ParseFile reads the top level statements.
+func (p *Parser) ParseFile() []*Statement {
+	out := make([]*Statement, 0)
+	for !p.done() {
+		if p.peek().Is(CloseBrace) {
+			p.error(p.next().Start, "unexpected `}`")
+			continue
+		}
+		out = append(out, p.parseStatement()...)
+	}
+	return out
+}
+
+// parseBlock reads the statements of a block until its closing brace.
+func (p *Parser) parseBlock(block *Statement) {
+	block.Body = make([]*Statement, 0)
+	for !p.done() {
+		if p.peek().Is(CloseBrace) {
+			block.Close = p.next()
+			return
+		}
+		block.Body = append(block.Body, p.parseStatement()...)
+	}
+	p.error(block.Terminator.Start, "missing `}`")
+}
+
+// parseStatement reads a comment or a declaration. Comments found within the
+// declaration are returned before it.
+func (p *Parser) parseStatement() []*Statement {
+	out := make([]*Statement, 0)
+	if p.peek().Kind == TokenComment {
+		return append(out, &Statement{Comment: p.next()})
+	}
+	statement := &Statement{Tokens: make([]*Token, 0)}
+	for !p.done() {
+		t := p.peek()
+		switch {
+		case t.Kind == TokenComment:
+			out = append(out, &Statement{Comment: p.next()})
+		case t.Is(Semicolon):
+			statement.Terminator = p.next()
+			if len(statement.Tokens) == 0 {
+				// Empty statement
+				return out
+			}
+			return append(out, p.attach(statement))
+		case t.Is(OpenBrace) && isValueStart(statement.Tokens):
+			p.parseAggregate(statement)
+		case t.Is(OpenBrace):
+			statement.Terminator = p.next()
+			p.attach(statement)
+			p.parseBlock(statement)
+			return append(out, statement)
+		case t.Is(CloseBrace):
+			p.error(t.Start, "missing `;`")
+			return append(out, p.attach(statement))
+		default:
+			statement.Tokens = append(statement.Tokens, p.next())
+		}
+	}
+	p.error(statement.Tokens[len(statement.Tokens)-1].End, "missing `;`")
+	return append(out, p.attach(statement))
+}
+
+// attach sets the comments of the statement, the leading and detached comments
+// of its first token and the trailing comment of its terminator, which is the
+// open brace of a block.
+func (p *Parser) attach(statement *Statement) *Statement {
+	first, last := statement.Terminator, statement.Terminator
+	if len(statement.Tokens) > 0 {
+		first = statement.Tokens[0]
+	}
+	if last == nil {
+		last = statement.Tokens[len(statement.Tokens)-1]
+	}
+	statement.Comments = &Comments{Detached: make([]Comment, 0)}
+	if first := p.comments[first]; first != nil {
+		statement.Comments.Leading = first.Leading
+		statement.Comments.Detached = first.Detached
+	}
+	if terminator := p.comments[last]; terminator != nil {
+		statement.Comments.Trailing = terminator.Trailing
+	}
+	return statement
+}
+
+// parseAggregate reads an aggregate (text format) value into the tokens of the
+// statement, e.g. `{ get: "/v1" }` in `option (google.api.http) = { get: "/v1" };`.
+func (p *Parser) parseAggregate(statement *Statement) {
+	open := p.peek()
+	depth := 0
+	for !p.done() {
+		t := p.next()
+		if t.Kind == TokenComment {
+			continue
+		}
+		statement.Tokens = append(statement.Tokens, t)
+		if t.Is(OpenBrace) {
+			depth++
+		} else if t.Is(CloseBrace) {
+			depth--
+			if depth == 0 {
+				return
+			}
+		}
+	}
+	p.error(open.Start, "missing `}`")
+}
+
+// isValueStart determines if an open brace following the tokens starts an
+// aggregate value rather than a block, i.e. it follows `=` or `:`, or is
+// within the brackets of field options.
+func isValueStart(tokens []*Token) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	last := tokens[len(tokens)-1]
+	depth := 0
+	for _, t := range tokens {
+		if t.Is(OpenBracket) {
+			depth++
+		} else if t.Is(ClosedBracket) {
+			depth--
+		}
+	}
+	return last.Is("=") || last.Is(":") || depth > 0
+}
+
+// AttachComments attaches the comment tokens to the declaration tokens around
+// them following the rules of protoc's tokenizer:
+//   - a comment starting on the line of the previous token, or a block of
+//     comments on the following lines ended by a blank line or the end of the
+//     scope, trails the previous token.
+//   - the block of comments directly preceding a token leads it.
+//   - the other comments, separated by blank lines, are detached and kept with
+//     the next token.
+//
+// Consecutive line comments form a single block, lines are joined by a space.
+func AttachComments(tokens []*Token) map[*Token]*Comments {
+	out := make(map[*Token]*Comments)
+	comments := func(t *Token) *Comments {
+		if out[t] == nil {
+			out[t] = &Comments{Detached: make([]Comment, 0)}
+		}
+		return out[t]
+	}
+	var previous *Token
+	for i := 0; i <= len(tokens); i++ {
+		start := i
+		for i < len(tokens) && tokens[i].Kind == TokenComment {
+			i++
+		}
+		var next *Token
+		if i < len(tokens) {
+			next = tokens[i]
+		}
+		if start < i {
+			c := &commentCollector{attach: previous != nil, detached: make([]Comment, 0)}
+			c.collect(previous, tokens[start:i], next)
+			if previous != nil && len(c.trailing) > 0 {
+				comments(previous).Trailing = c.trailing
+			}
+			if next != nil && (len(c.detached) > 0 || c.pending) {
+				comments(next).Detached = c.detached
+				comments(next).Leading = c.buffer
+			}
+		}
+		previous = next
+	}
+	return out
+}
+
+// commentCollector groups the comments found between two tokens into the
+// trailing comment of the previous token, and the detached and leading
+// comments of the next token.
+type commentCollector struct {
+	// attach is true while a comment can trail the previous token
+	attach   bool
+	buffer   Comment
+	pending  bool
+	line     bool
+	trailing Comment
+	detached []Comment
+}
+
+// add appends the comment to the buffer, a block comment is never joined with
+// other comments.
+func (c *commentCollector) add(t *Token) {
+	isLine := strings.HasPrefix(t.Text, InlineCommentPrefix)
+	if !isLine || !c.line {
+		c.flush()
+	}
+	c.buffer = c.buffer.Append(NewCommentLine(t).Comment).TrimSpace()
+	c.pending = true
+	c.line = isLine
+}
+
+// flush moves the buffer to the trailing comment if it can still be attached
+// to the previous token, or to the detached comments.
+func (c *commentCollector) flush() {
+	if !c.pending {
+		return
+	}
+	if c.attach {
+		c.trailing = c.buffer
+		c.attach = false
+	} else {
+		c.detached = append(c.detached, c.buffer)
+	}
+	c.buffer = Empty
+	c.pending = false
+	c.line = false
+}
+
+// collect reads the comments between the previous and the next token, either
+// of which is nil at the start or end of the source.
+func (c *commentCollector) collect(previous *Token, comments []*Token, next *Token) {
+	end, trailingEnd := 0, -1
+	if previous != nil {
+		end = previous.End.Line
+	}
+	if previous != nil && comments[0].Start.Line == previous.End.Line {
+		first := comments[0]
+		if !strings.HasPrefix(first.Text, InlineCommentPrefix) && followedOnLine(first, comments[1:], next) {
+			// A block comment between two tokens of a line is not attached
+			return
+		}
+		c.add(first)
+		c.flush()
+		comments = comments[1:]
+		end, trailingEnd = first.End.Line, first.End.Line
+	}
+	for _, t := range comments {
+		if t.Start.Line > end+1 {
+			// Blank line
+			c.flush()
+			c.attach = false
+		}
+		c.add(t)
+		end = t.End.Line
+	}
+	if next != nil && next.Start.Line > end+1 {
+		c.flush()
+		c.attach = false
+	}
+	if next == nil || next.Is(CloseBrace) || next.Is(ClosedBracket) || next.Is(")") {
+		// The end of a scope has no leading comments
+		c.flush()
+	}
+	if next != nil && previous != nil && (next.Start.Line == previous.End.Line || next.Start.Line == trailingEnd) {
+		// The comment is between tokens of a line
+		c.attach = false
+		c.flush()
+	}
+}
+
+// followedOnLine determines if a comment is followed by another comment or a
+// token on its last line.
+func followedOnLine(t *Token, comments []*Token, next *Token) bool {
+	if len(comments) > 0 {
+		return comments[0].Start.Line == t.End.Line
+	}
+	return next != nil && next.Start.Line == t.End.Line
+}
@@ -0,0 +1,205 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	statements, err := Parse(`// Leading
+message A { // Trailing
+  /* Block
+     comment */
+  string s = 1 [(a.b) = { c: "{;}" }];
+  reserved 2;;
+} // Closed
+service S {
+  rpc Get(A) returns (A) {
+    option (google.api.http) = { get: "/v1/{name=a/*}" };
+  }
+}`)
+	assert.Nil(t, err)
+	assert.Len(t, statements, 4)
+	assert.True(t, statements[0].IsComment())
+	assert.Equal(t, "// Closed", statements[2].Comment.Text)
+	message := statements[1]
+	assert.True(t, message.IsBlock())
+	assert.Equal(t, "message A", message.Syntax())
+	assert.Equal(t, &Comments{Leading: "Leading", Trailing: "Trailing", Detached: []Comment{}}, message.Comments)
+	assert.Len(t, message.Body, 4)
+	assert.Equal(t, "// Trailing", message.Body[0].Comment.Text)
+	assert.Equal(t, `string s = 1 [(a.b) = { c: "{;}" }]`, message.Body[2].Syntax())
+
+	lines := make([]*Line, 0)
+	for _, s := range statements {
+		lines = append(lines, s.Lines("a.proto")...)
+	}
+	assert.Equal(t, Location{File: "a.proto", Start: Position{Line: 2, Column: 1}, End: Position{Line: 7, Column: 1}}, lines[0].Location)
+	assert.Equal(t, Location{File: "a.proto", Start: Position{Line: 5, Column: 3}, End: Position{Line: 5, Column: 38}}, lines[1].Location)
+	assert.Equal(t, Location{File: "a.proto", Start: Position{Line: 7, Column: 1}, End: Position{Line: 7, Column: 1}}, lines[3].Location)
+	for _, l := range lines {
+		assert.True(t, l.Location.IsValid(), "%v", l)
+		l.Location = Location{}
+	}
+	assert.Equal(t, []*Line{
+		{Syntax: "message A", Token: OpenBrace, Comment: "Leading Trailing", Comments: &Comments{Leading: "Leading", Trailing: "Trailing", Detached: []Comment{}}},
+		{Syntax: `string s = 1 [(a.b) = { c: "{;}" }]`, Token: Semicolon, Comment: "Block:~: comment", Comments: &Comments{Leading: "Block:~: comment", Detached: []Comment{}}},
+		{Syntax: "reserved 2", Token: Semicolon, Comments: &Comments{Detached: []Comment{}}},
+		{Token: CloseBrace},
+		{Syntax: "service S", Token: OpenBrace, Comments: &Comments{Detached: []Comment{}}},
+		{Syntax: "rpc Get(A) returns (A)", Token: OpenBrace, Comments: &Comments{Detached: []Comment{}}},
+		{Syntax: `option (google.api.http) = { get: "/v1/{name=a/*}" }`, Token: Semicolon, Comments: &Comments{Detached: []Comment{}}},
+		{Token: CloseBrace},
+		{Token: CloseBrace},
+	}, lines)
+}
+
+func TestAttachComments(t *testing.T) {
+	// The example of the SourceCodeInfo documentation in descriptor.proto
+	statements, err := Parse(`message A {
+  int32 foo = 1;  // Comment attached to foo.
+  // Comment attached to bar.
+  int32 bar = 2;
+
+  string baz = 3;
+  // Comment attached to baz.
+  // Another line attached to baz.
+
+  // Comment attached to moo.
+  //
+  // Another line attached to moo.
+  double moo = 4;
+
+  // Detached comment for corge.
+
+  // Detached comment for corge paragraph 2.
+
+  string corge = 5;
+  /* Block comment attached
+     to corge. */
+  /* Block comment attached to grault. */
+  int32 grault = 6;
+
+  // ignored detached comments.
+}`)
+	assert.Nil(t, err)
+	got := make(map[string]*Comments)
+	for _, s := range statements[0].Body {
+		if !s.IsComment() {
+			got[s.Tokens[1].Text] = s.Comments
+		}
+	}
+	assert.Equal(t, map[string]*Comments{
+		"foo":    {Trailing: "Comment attached to foo.", Detached: []Comment{}},
+		"bar":    {Leading: "Comment attached to bar.", Detached: []Comment{}},
+		"baz":    {Trailing: "Comment attached to baz. Another line attached to baz.", Detached: []Comment{}},
+		"moo":    {Leading: "Comment attached to moo. Another line attached to moo.", Detached: []Comment{}},
+		"corge":  {Trailing: "Block comment attached:~: to corge.", Detached: []Comment{"Detached comment for corge.", "Detached comment for corge paragraph 2."}},
+		"grault": {Leading: "Block comment attached to grault.", Detached: []Comment{}},
+	}, got)
+}
+
+func TestAttachComments_SameLine(t *testing.T) {
+	tokens, err := Tokenize("int32 a = 1; /* between */ int32 b = 2;\n// First\n\nint32 c = 3;")
+	assert.Nil(t, err)
+	comments := AttachComments(tokens)
+	assert.Nil(t, comments[tokens[4]])
+	assert.Nil(t, comments[tokens[6]])
+	assert.Equal(t, &Comments{Trailing: "First", Detached: []Comment{}}, comments[tokens[10]])
+	assert.Nil(t, comments[tokens[12]])
+}
+
+func TestStatement_Location(t *testing.T) {
+	statements, err := Parse("/* A */\nmessage A {\n  int32 a = 1;")
+	assert.NotNil(t, err)
+	assert.Equal(t, Position{Line: 1, Column: 1}, statements[0].Start())
+	assert.Equal(t, Position{Line: 1, Column: 7}, statements[0].End())
+	assert.Equal(t, Location{File: "a.proto", Start: Position{Line: 2, Column: 1}, End: Position{Line: 3, Column: 14}}, statements[1].Location("a.proto"))
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "Missing Semicolon", in: "message A { string a = 1 }", want: "1:26: missing `;`"},
+		{name: "Missing Semicolon At End", in: "syntax = \"proto3\"", want: "1:17: missing `;`"},
+		{name: "Missing Close Brace", in: "message A {", want: "1:11: missing `}`"},
+		{name: "Unexpected Close Brace", in: "}", want: "1:1: unexpected `}`"},
+		{name: "Missing Aggregate Close Brace", in: "option a = { b: 1", want: "1:12: missing `}`\n1:17: missing `;`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.in)
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
+
+func TestStatement_SyntaxCompact(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "int32 x=1;", want: "int32 x = 1"},
+		{in: "map<string,int32> m = 3;", want: "map<string, int32> m = 3"},
+		{in: "map < string , int32 >m=3;", want: "map<string, int32> m = 3"},
+		{in: "repeated string tags=4 [deprecated=true,json_name=\"t\"];", want: `repeated string tags = 4 [deprecated = true, json_name = "t"]`},
+		{in: "E_UNSPECIFIED=0;", want: "E_UNSPECIFIED = 0"},
+		{in: "reserved 2,15,9 to 11;", want: "reserved 2, 15, 9 to 11"},
+		{in: `option (a.b)={c:"x=1"};`, want: `option (a.b) = {c:"x=1"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			statements, err := Parse(tt.in)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, statements[0].Syntax())
+		})
+	}
+}
+
+func TestParseString_Compact(t *testing.T) {
+	p, err := ParseString("compact.proto", `syntax="proto3";
+package test;
+enum E {
+  E_UNSPECIFIED=0;
+  E_ONE=1;
+}
+message M {
+  int32 x=1;
+  E e=2;
+  map<string,int32> m = 3;
+  repeated string tags=4;
+}
+`)
+	assert.Nil(t, err)
+	assert.Empty(t, p.Diagnostics)
+	assert.Len(t, p.Enums[0].Values, 2)
+	assert.Equal(t, "E_UNSPECIFIED", p.Enums[0].Values[0].Value)
+	m := p.Messages[0]
+	assert.Len(t, m.Attributes, 4)
+	assert.Equal(t, "x", m.Attributes[0].Name)
+	assert.Equal(t, 1, m.Attributes[0].Ordinal)
+	assert.True(t, m.Attributes[2].Map)
+	assert.Equal(t, []string{"string", " int32"}, m.Attributes[2].Kind)
+	assert.True(t, m.Attributes[3].Repeated)
+	assert.Equal(t, 4, m.Attributes[3].Ordinal)
+}
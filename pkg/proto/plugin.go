@@ -0,0 +1,151 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	protobuf "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// ParsePluginParameter reads the writer configuration from the parameter of a
+// plugin request, comma separated options such as `pure_md,visualize=false`.
+// A boolean option without a value is enabled.
+func ParsePluginParameter(parameter string) (*WriterConfig, error) {
+	out := &WriterConfig{visualize: true}
+	for _, option := range strings.Split(parameter, Comma) {
+		option = strings.TrimSpace(option)
+		if len(option) == 0 {
+			continue
+		}
+		name, value, hasValue := strings.Cut(option, "=")
+		var err error
+		switch name {
+		case "pure_md":
+			out.pureMarkdown, err = parsePluginBool(value, hasValue)
+		case "visualize":
+			out.visualize, err = parsePluginBool(value, hasValue)
+		case "comments":
+			out.comments, err = ParseCommentStyle(value)
+		case "external":
+			out.external, err = ParseExternalStyle(value)
+		case "diagram":
+			out.diagram, err = ParseDiagramStyle(value)
+		default:
+			err = fmt.Errorf("unknown option %q, expected pure_md, visualize, comments, external or diagram", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameter %q: %w", option, err)
+		}
+	}
+	return out, nil
+}
+
+func parsePluginBool(value string, hasValue bool) (bool, error) {
+	if !hasValue {
+		return true, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// Generate writes the markdown of the files to generate of a plugin request,
+// each file is written as `<file name>.md`.
+func Generate(request *pluginpb.CodeGeneratorRequest) *pluginpb.CodeGeneratorResponse {
+	out := &pluginpb.CodeGeneratorResponse{
+		SupportedFeatures: protobuf.Uint64(uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL |
+			pluginpb.CodeGeneratorResponse_FEATURE_SUPPORTS_EDITIONS)),
+		MinimumEdition: protobuf.Int32(int32(descriptorpb.Edition_EDITION_PROTO2)),
+		MaximumEdition: protobuf.Int32(int32(descriptorpb.Edition_EDITION_2023)),
+	}
+	config, err := ParsePluginParameter(request.GetParameter())
+	if err != nil {
+		out.Error = protobuf.String(err.Error())
+		return out
+	}
+
+	files := mergeSourceFiles(request.GetProtoFile(), request.GetSourceFileDescriptors())
+	packages := DescriptorSetToPackages(&descriptorpb.FileDescriptorSet{File: files})
+	MarkBundled(packages...)
+	if diagnostics := Link(packages...); diagnostics.HasErrors() {
+		out.Error = protobuf.String(diagnostics.String())
+		return out
+	}
+
+	byName := make(map[string]*Package)
+	for _, p := range packages {
+		byName[p.Path] = p
+	}
+	config.outputs = make(map[*Package]string)
+	for _, name := range request.GetFileToGenerate() {
+		if p, ok := byName[name]; ok {
+			config.outputs[p] = name + ".md"
+		}
+	}
+	for _, name := range request.GetFileToGenerate() {
+		p, ok := byName[name]
+		if !ok {
+			out.Error = protobuf.String(fmt.Sprintf("file to generate %s is not in the request", name))
+			return out
+		}
+		out.File = append(out.File, &pluginpb.CodeGeneratorResponse_File{
+			Name:    protobuf.String(config.outputs[p]),
+			Content: protobuf.String(PackageToMarkDown(p, config)),
+		})
+	}
+	return out
+}
+
+// mergeSourceFiles replaces the files to generate with their source file
+// descriptors, which retain the source-only options.
+func mergeSourceFiles(files []*descriptorpb.FileDescriptorProto, sources []*descriptorpb.FileDescriptorProto) []*descriptorpb.FileDescriptorProto {
+	byName := make(map[string]*descriptorpb.FileDescriptorProto)
+	for _, f := range sources {
+		byName[f.GetName()] = f
+	}
+	out := make([]*descriptorpb.FileDescriptorProto, 0, len(files))
+	for _, f := range files {
+		if source, ok := byName[f.GetName()]; ok {
+			f = source
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// ExecutePlugin runs the tool as a protoc plugin, reading a CodeGeneratorRequest
+// from in and writing the CodeGeneratorResponse to out.
+func ExecutePlugin(in io.Reader, out io.Writer) error {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	request := &pluginpb.CodeGeneratorRequest{}
+	if err := protobuf.Unmarshal(data, request); err != nil {
+		return fmt.Errorf("invalid code generator request: %w", err)
+	}
+	data, err = protobuf.Marshal(Generate(request))
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	return err
+}
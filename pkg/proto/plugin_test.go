@@ -0,0 +1,92 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	protobuf "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestParsePluginParameter(t *testing.T) {
+	tests := []struct {
+		name      string
+		parameter string
+		want      *WriterConfig
+		wantErr   string
+	}{
+		{name: "Default", parameter: "", want: &WriterConfig{visualize: true}},
+		{name: "Pure Markdown", parameter: "pure_md,visualize=false", want: &WriterConfig{pureMarkdown: true}},
+		{name: "Styles", parameter: "comments=leading, external=alias", want: &WriterConfig{visualize: true, comments: CommentsLeading, external: ExternalAliased}},
+		{name: "Diagram", parameter: "diagram=plantuml", want: &WriterConfig{visualize: true, diagram: DiagramPlantUML}},
+		{name: "Invalid Bool", parameter: "visualize=maybe", wantErr: `invalid parameter "visualize=maybe": strconv.ParseBool: parsing "maybe": invalid syntax`},
+		{name: "Unknown", parameter: "paths=source_relative", wantErr: `invalid parameter "paths=source_relative": unknown option "paths", expected pure_md, visualize, comments, external or diagram`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePluginParameter(tt.parameter)
+			if len(tt.wantErr) > 0 {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	request := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{"library.proto"},
+		Parameter:      protobuf.String("visualize=false"),
+		ProtoFile:      readTestDescriptorSet(t).File,
+	}
+	response := Generate(request)
+	assert.Nil(t, response.Error)
+	assert.Len(t, response.File, 1)
+	assert.Equal(t, "library.proto.md", response.File[0].GetName())
+	packages := DescriptorSetToPackages(readTestDescriptorSet(t))
+	Link(packages...)
+	assert.Equal(t, PackageToMarkDown(packages[0], &WriterConfig{}), response.File[0].GetContent())
+	assert.NotContains(t, response.File[0].GetContent(), "mermaid")
+
+	request.Parameter = protobuf.String("unknown")
+	assert.Equal(t, `invalid parameter "unknown": unknown option "unknown", expected pure_md, visualize, comments, external or diagram`, Generate(request).GetError())
+
+	request.Parameter = nil
+	request.FileToGenerate = []string{"missing.proto"}
+	assert.Equal(t, "file to generate missing.proto is not in the request", Generate(request).GetError())
+}
+
+func TestExecutePlugin(t *testing.T) {
+	data, err := protobuf.Marshal(&pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{"library.proto"},
+		ProtoFile:      readTestDescriptorSet(t).File,
+	})
+	assert.Nil(t, err)
+	out := &bytes.Buffer{}
+	assert.Nil(t, ExecutePlugin(bytes.NewReader(data), out))
+	response := &pluginpb.CodeGeneratorResponse{}
+	assert.Nil(t, protobuf.Unmarshal(out.Bytes(), response))
+	assert.Len(t, response.File, 1)
+	assert.Contains(t, response.File[0].GetContent(), "```mermaid")
+
+	assert.NotNil(t, ExecutePlugin(bytes.NewReader([]byte("not a request")), out))
+}
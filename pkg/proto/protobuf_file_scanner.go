@@ -18,9 +18,9 @@ package proto
 
 import (
 	"bufio"
+	"io"
 	"os"
 	"regexp"
-	"strings"
 )
 
 var SpaceRemover *regexp.Regexp
@@ -31,50 +31,101 @@ func init() {
 
 // NewProtobufFileScanner is the constructor for ProtobufFileScanner
 func NewProtobufFileScanner(file *os.File) Scanner {
-	contents := strings.Join(ReadFileToArray(file), "\n")
-	scanner := bufio.NewScanner(strings.NewReader(contents))
-	scanner.Split(bufio.ScanLines)
-	return &ProtobufFileScanner{scanner: scanner}
+	return NewProtobufReaderScanner(file.Name(), file)
 }
 
-// ProtobufFileScanner is a specialized scanner for reading protobuf 3 files.
+// NewProtobufReaderScanner reads the protobuf source from the reader and
+// creates a scanner for it, name is the path recorded in the location of the
+// lines. Reading errors are returned by Err.
+func NewProtobufReaderScanner(name string, r io.Reader) Scanner {
+	contents, err := io.ReadAll(r)
+	if err != nil {
+		return &ProtobufFileScanner{lines: make([]*Line, 0), current: -1, err: err, diagnostics: make(Diagnostics, 0)}
+	}
+	return NewProtobufScanner(name, string(contents))
+}
+
+// NewProtobufScanner parses the protobuf source and creates a scanner reading
+// its statements line by line, file is the path recorded in the location of
+// the lines. Syntax errors are reported as diagnostics.
+func NewProtobufScanner(file string, in string) Scanner {
+	statements, err := Parse(in)
+	lines := make([]*Line, 0)
+	for _, s := range statements {
+		lines = append(lines, s.Lines(file)...)
+	}
+	if Log.debug {
+		for i, l := range lines {
+			Log.Debugf("%d. %s", i, l)
+		}
+	}
+	out := &ProtobufFileScanner{lines: lines, current: -1, diagnostics: make(Diagnostics, 0)}
+	for _, e := range SyntaxErrors(err) {
+		out.Report(NewDiagnostic(SeverityError, Location{File: file, Start: e.Position, End: e.Position}, Empty, e.Message))
+	}
+	return out
+}
+
+// ProtobufFileScanner is a specialized scanner for reading protobuf files, it
+// reads the lines of the statement tree built by the Parser and collects the
+// diagnostics reported by the visitors.
 type ProtobufFileScanner struct {
-	scanner *bufio.Scanner
+	lines       []*Line
+	current     int
+	err         error
+	diagnostics Diagnostics
+}
+
+// Scan advances to the next line
+func (sw *ProtobufFileScanner) Scan() bool {
+	if sw.current < len(sw.lines) {
+		sw.current++
+	}
+	return sw.current < len(sw.lines)
+}
+
+// Text returns the current line as it would be written in the source.
+func (sw *ProtobufFileScanner) Text() string {
+	if sw.current < 0 || sw.current >= len(sw.lines) {
+		return Empty
+	}
+	return sw.lines[sw.current].String()
 }
 
-// Scan is a delegate method to the underline scanner
-func (sw ProtobufFileScanner) Scan() bool {
-	return sw.scanner.Scan()
+// Split has no effect, the source is split into statements by the Parser.
+func (sw *ProtobufFileScanner) Split(_ bufio.SplitFunc) {
 }
 
-// Text is a specialization of the Text function, ensuring the line read
-// is ready for processing.
-func (sw ProtobufFileScanner) Text() string {
-	return FormatLine(sw.scanner.Text())
+// Buffer has no effect, the source is read before scanning.
+func (sw *ProtobufFileScanner) Buffer(_ []byte, _ int) {
 }
 
-// Split is a delegate method to the underline scanner
-func (sw ProtobufFileScanner) Split(splitFunction bufio.SplitFunc) {
-	sw.scanner.Split(splitFunction)
+// Err returns the error found while reading the source, syntax errors are
+// reported as diagnostics.
+func (sw *ProtobufFileScanner) Err() error {
+	return sw.err
 }
 
-// Buffer is a delegate method to the underline scanner
-func (sw ProtobufFileScanner) Buffer(buf []byte, max int) {
-	sw.scanner.Buffer(buf, max)
+// Bytes returns the current line as it would be written in the source.
+func (sw *ProtobufFileScanner) Bytes() []byte {
+	return []byte(sw.Text())
 }
 
-// Err is a delegate method to the underline scanner
-func (sw ProtobufFileScanner) Err() error {
-	return sw.scanner.Err()
+// ReadLine returns the current line for the visitors.
+func (sw *ProtobufFileScanner) ReadLine() *Line {
+	if sw.current < 0 || sw.current >= len(sw.lines) {
+		return &Line{}
+	}
+	return sw.lines[sw.current]
 }
 
-// Bytes is a delegate method to the underline scanner
-func (sw ProtobufFileScanner) Bytes() []byte {
-	return sw.scanner.Bytes()
+// Report implements the Reporter interface.
+func (sw *ProtobufFileScanner) Report(d *Diagnostic) {
+	sw.diagnostics = append(sw.diagnostics, d)
 }
 
-// ReadLine is an addition to the buffered reader responsible for interpreting
-// the line of the protobuf for the AST.
-func (sw ProtobufFileScanner) ReadLine() *Line {
-	return NewLine(sw.Text())
+// Diagnostics returns the syntax errors and the diagnostics reported by the
+// visitors.
+func (sw *ProtobufFileScanner) Diagnostics() Diagnostics {
+	return sw.diagnostics
 }
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProtobufFileScanner(t *testing.T) {
+	file, err := os.Open("data/input_test_file.txt")
+	assert.Nil(t, err)
+	defer file.Close()
+
+	scanner := NewProtobufFileScanner(file)
+	texts := make([]string, 0)
+	for scanner.Scan() {
+		texts = append(texts, scanner.Text())
+	}
+	// Comments are attached to declarations, the file has none
+	assert.Empty(t, texts)
+	assert.Nil(t, scanner.Err())
+	assert.False(t, scanner.Scan())
+}
+
+func TestNewProtobufScanner(t *testing.T) {
+	scanner := NewProtobufScanner("a.proto", `option go_package = "a/b;b"; // Go
+message A {
+  string s = 1;
+}`)
+	assert.True(t, scanner.Scan())
+	assert.Equal(t, &Line{Syntax: `option go_package = "a/b;b"`, Token: Semicolon, Comment: "Go", Comments: &Comments{
+		Trailing: "Go", Detached: []Comment{},
+	}, Location: Location{
+		File: "a.proto", Start: Position{Line: 1, Column: 1}, End: Position{Line: 1, Column: 28},
+	}}, scanner.ReadLine())
+	assert.Equal(t, `option go_package = "a/b;b";`, scanner.Text())
+	assert.Equal(t, []byte(`option go_package = "a/b;b";`), scanner.Bytes())
+	assert.True(t, scanner.Scan())
+	assert.Equal(t, "message A {", scanner.Text())
+	assert.Equal(t, "a.proto:2:1", scanner.ReadLine().Location.String())
+	assert.Equal(t, Position{Line: 4, Column: 1}, scanner.ReadLine().Location.End)
+	assert.True(t, scanner.Scan())
+	assert.True(t, scanner.Scan())
+	assert.Equal(t, "}", scanner.Text())
+	assert.False(t, scanner.Scan())
+	assert.Nil(t, scanner.Err())
+
+	assert.Empty(t, scanner.(Reporter).Diagnostics())
+
+	scanner = NewProtobufScanner("a.proto", "message A {")
+	assert.Nil(t, scanner.Err())
+	assert.Equal(t, "a.proto:1:11: error: missing `}`", scanner.(Reporter).Diagnostics().String())
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"strconv"
+	"strings"
+)
+
+// MaxEnumValue is the largest enum value, and the value represented by the
+// `max` keyword in enum reserved ranges.
+const MaxEnumValue = 2147483647
+
+// Range is an inclusive range of field numbers or enum values.
+type Range struct {
+	Start int32
+	End   int32
+	// Max is the value of the `max` keyword for the kind of range, MaxFieldNumber
+	// for field numbers and MaxEnumValue for enum values, zero when unknown.
+	Max int32
+}
+
+// NewRange is the Range constructor
+func NewRange(start int32, end int32) *Range {
+	return &Range{Start: start, End: end}
+}
+
+// Contains determines if the value is within the range.
+func (r *Range) Contains(value int) bool {
+	return value >= int(r.Start) && value <= int(r.End)
+}
+
+// String formats the range as it would be declared in the protobuf source.
+func (r *Range) String() string {
+	start := strconv.Itoa(int(r.Start))
+	if r.Max != 0 && r.End == r.Max {
+		return Join(Space, start, KeywordTo, KeywordMax)
+	}
+	if r.Start == r.End {
+		return start
+	}
+	return Join(Space, start, KeywordTo, strconv.Itoa(int(r.End)))
+}
+
+// ParseRange reads a range such as `5`, `8 to 20` or `100 to max`, where max is
+// substituted with the given value.
+func ParseRange(in string, max int32) (*Range, bool) {
+	split := strings.Fields(in)
+	if len(split) == 1 {
+		s := int32(ParseOrdinal(split[0]))
+		return &Range{Start: s, End: s, Max: max}, true
+	} else if len(split) == 3 && split[1] == KeywordTo {
+		s := int32(ParseOrdinal(split[0]))
+		e := max
+		if split[2] != KeywordMax {
+			e = int32(ParseOrdinal(split[2]))
+		}
+		return &Range{Start: s, End: e, Max: max}, true
+	}
+	return nil, false
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRange_String(t *testing.T) {
+	assert.Equal(t, "5", NewRange(5, 5).String())
+	assert.Equal(t, "8 to 20", NewRange(8, 20).String())
+	assert.Equal(t, "100 to max", (&Range{Start: 100, End: MaxFieldNumber, Max: MaxFieldNumber}).String())
+	assert.Equal(t, "100 to max", (&Range{Start: 100, End: MaxEnumValue, Max: MaxEnumValue}).String())
+	// The max keyword depends on the kind of range
+	assert.Equal(t, "100 to 536870911", (&Range{Start: 100, End: MaxFieldNumber, Max: MaxEnumValue}).String())
+	assert.Equal(t, "100 to 2147483647", NewRange(100, MaxEnumValue).String())
+}
+
+func TestRange_Contains(t *testing.T) {
+	assert.True(t, NewRange(8, 20).Contains(8))
+	assert.True(t, NewRange(8, 20).Contains(20))
+	assert.False(t, NewRange(8, 20).Contains(21))
+}
+
+func TestParseRange(t *testing.T) {
+	r, ok := ParseRange("9 to 11", MaxFieldNumber)
+	assert.True(t, ok)
+	assert.Equal(t, &Range{Start: 9, End: 11, Max: MaxFieldNumber}, r)
+	r, ok = ParseRange(" 4 to max ", MaxEnumValue)
+	assert.True(t, ok)
+	assert.Equal(t, &Range{Start: 4, End: MaxEnumValue, Max: MaxEnumValue}, r)
+	_, ok = ParseRange("4 until 5", MaxFieldNumber)
+	assert.False(t, ok)
+}
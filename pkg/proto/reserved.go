@@ -16,11 +16,65 @@
 
 package proto
 
+import (
+	"fmt"
+	"strings"
+)
+
+// Reserved is a reserved statement in a message or enum, it holds the field
+// number (or enum value) ranges and the names that may not be used.
 type Reserved struct {
-	Start int32
-	End   int32
+	Ranges   []*Range
+	Names    []string
+	Comment  Comment
+	Comments *Comments
+	Location Location
 }
 
+// NewReserved creates a Reserved statement for a single range.
 func NewReserved(start int32, end int32) *Reserved {
-	return &Reserved{Start: start, End: end}
+	return &Reserved{Ranges: []*Range{NewRange(start, end)}, Names: make([]string, 0)}
+}
+
+// NewReservedNames creates a Reserved statement for a list of names.
+func NewReservedNames(names ...string) *Reserved {
+	return &Reserved{Ranges: make([]*Range, 0), Names: names}
+}
+
+// IsValid implements the Validatable interface
+func (r *Reserved) IsValid() bool {
+	return len(r.Ranges) > 0 || len(r.Names) > 0
+}
+
+// ContainsNumber determines if the number is within one of the reserved ranges.
+func (r *Reserved) ContainsNumber(number int) bool {
+	for _, rng := range r.Ranges {
+		if rng.Contains(number) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsName determines if the name is reserved.
+func (r *Reserved) ContainsName(name string) bool {
+	for _, n := range r.Names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// String formats the reserved ranges and quoted names as they would be declared
+// in the protobuf source.
+func (r *Reserved) String() string {
+	values := make([]string, 0)
+	for _, rng := range r.Ranges {
+		values = append(values, rng.String())
+	}
+	for _, n := range r.Names {
+		values = append(values, fmt.Sprintf("%q", n))
+	}
+	return strings.Join(values, Comma+Space)
 }
@@ -36,8 +36,8 @@ func TestNewReserved(t *testing.T) {
 			start: 4,
 			end:   10,
 		}, want: &Reserved{
-			Start: 4,
-			End:   10,
+			Ranges: []*Range{{Start: 4, End: 10}},
+			Names:  make([]string, 0),
 		}},
 	}
 	for _, tt := range tests {
@@ -46,3 +46,21 @@ func TestNewReserved(t *testing.T) {
 		})
 	}
 }
+
+func TestNewReservedNames(t *testing.T) {
+	assert.Equal(t, &Reserved{Ranges: make([]*Range, 0), Names: []string{"foo", "bar"}}, NewReservedNames("foo", "bar"))
+}
+
+func TestReserved_Contains(t *testing.T) {
+	r := &Reserved{Ranges: []*Range{NewRange(2, 2), NewRange(9, 11)}, Names: []string{"foo"}}
+	assert.True(t, r.ContainsNumber(2))
+	assert.True(t, r.ContainsNumber(10))
+	assert.False(t, r.ContainsNumber(12))
+	assert.True(t, r.ContainsName("foo"))
+	assert.False(t, r.ContainsName("bar"))
+}
+
+func TestReserved_String(t *testing.T) {
+	r := &Reserved{Ranges: []*Range{NewRange(2, 2), {Start: 9, End: MaxFieldNumber, Max: MaxFieldNumber}}, Names: []string{"foo", "bar"}}
+	assert.Equal(t, `2, 9 to max, "foo", "bar"`, r.String())
+}
@@ -17,28 +17,46 @@
 package proto
 
 import (
-	"strconv"
 	"strings"
 )
 
+// ReservedVisitor reads reserved statements, Max is the value substituted for
+// the `max` keyword and defaults to MaxFieldNumber.
 type ReservedVisitor struct {
+	Max int32
 }
 
 func (rv *ReservedVisitor) CanVisit(line *Line) bool {
 	return strings.HasPrefix(line.Syntax, PrefixReserved) && line.Token == Semicolon
 }
 
-func (rv *ReservedVisitor) Visit(_ Scanner, in *Line, _ string) interface{} {
+// Visit marshals the comma separated ranges and names of the statement, e.g.
+// `reserved 2, 15, 9 to 11;` or `reserved "foo", "bar";`.
+func (rv *ReservedVisitor) Visit(scanner Scanner, in *Line, namespace string) interface{} {
 	Log.Debug("Visiting Reserved")
-	split := in.SplitSyntax()
-	if len(split) == 2 {
-		s, _ := strconv.ParseInt(split[1], 10, 64)
-		return NewReserved(int32(s), int32(s))
+	max := rv.Max
+	if max == 0 {
+		max = MaxFieldNumber
 	}
-	if len(split) == 4 {
-		s, _ := strconv.ParseInt(split[1], 10, 64)
-		e, _ := strconv.ParseInt(split[3], 10, 64)
-		return NewReserved(int32(s), int32(e))
+	out := &Reserved{Ranges: make([]*Range, 0), Names: make([]string, 0), Comment: in.Comment, Comments: in.Comments, Location: in.Location}
+	body := strings.TrimSpace(strings.TrimPrefix(in.Syntax, PrefixReserved))
+	for _, value := range strings.Split(body, Comma) {
+		value = strings.TrimSpace(value)
+		if len(value) == 0 {
+			continue
+		}
+		if strings.HasPrefix(value, DoubleQuote) || strings.HasPrefix(value, SingleQuote) {
+			out.Names = append(out.Names, strings.Trim(value, DoubleQuote+SingleQuote))
+		} else if r, ok := ParseRange(value, max); ok && IsNumeric(strings.Fields(value)[0]) {
+			out.Ranges = append(out.Ranges, r)
+		} else {
+			// Editions allow reserved names as bare identifiers
+			out.Names = append(out.Names, value)
+		}
 	}
-	return nil
+	if !out.IsValid() {
+		Report(scanner, SeverityError, in, namespace, "reserved statement without ranges or names")
+		return nil
+	}
+	return out
 }
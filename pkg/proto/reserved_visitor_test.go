@@ -75,8 +75,9 @@ func TestReservedVisitor_Visit(t *testing.T) {
 			},
 			in2: "test.Message",
 		}, want: &Reserved{
-			Start: 10,
-			End:   10,
+			Ranges:  []*Range{{Start: 10, End: 10, Max: MaxFieldNumber}},
+			Names:   make([]string, 0),
+			Comment: "Reserved 10",
 		}},
 		{name: "Is Reserved", args: args{
 			in0: testScanner,
@@ -87,8 +88,34 @@ func TestReservedVisitor_Visit(t *testing.T) {
 			},
 			in2: "test.Message",
 		}, want: &Reserved{
-			Start: 10,
-			End:   20,
+			Ranges:  []*Range{{Start: 10, End: 20, Max: MaxFieldNumber}},
+			Names:   make([]string, 0),
+			Comment: "Reserved 10 to 20",
+		}},
+		{name: "Is Reserved List", args: args{
+			in0: testScanner,
+			in:  NewLine("reserved 2, 15, 9 to 11, 40 to max;"),
+			in2: "test.Message",
+		}, want: &Reserved{
+			Ranges: []*Range{{Start: 2, End: 2, Max: MaxFieldNumber}, {Start: 15, End: 15, Max: MaxFieldNumber},
+				{Start: 9, End: 11, Max: MaxFieldNumber}, {Start: 40, End: MaxFieldNumber, Max: MaxFieldNumber}},
+			Names: make([]string, 0),
+		}},
+		{name: "Is Reserved Names", args: args{
+			in0: testScanner,
+			in:  NewLine(`reserved "foo", "bar";`),
+			in2: "test.Message",
+		}, want: &Reserved{
+			Ranges: make([]*Range, 0),
+			Names:  []string{"foo", "bar"},
+		}},
+		{name: "Is Reserved Identifiers", args: args{
+			in0: testScanner,
+			in:  NewLine(`reserved foo, bar;`),
+			in2: "test.Message",
+		}, want: &Reserved{
+			Ranges: make([]*Range, 0),
+			Names:  []string{"foo", "bar"},
 		}},
 	}
 	for _, tt := range tests {
@@ -98,3 +125,19 @@ func TestReservedVisitor_Visit(t *testing.T) {
 		})
 	}
 }
+
+func TestReservedVisitor_VisitEnumMax(t *testing.T) {
+	rv := &ReservedVisitor{Max: MaxEnumValue}
+	assert.Equal(t, &Reserved{
+		Ranges: []*Range{{Start: 10, End: MaxEnumValue, Max: MaxEnumValue}},
+		Names:  make([]string, 0),
+	}, rv.Visit(nil, NewLine("reserved 10 to max;"), "test.Enum"))
+}
+
+func TestReservedVisitor_VisitHexAndOctal(t *testing.T) {
+	rv := &ReservedVisitor{}
+	out := rv.Visit(nil, NewLine("reserved 0x10, 010 to 0x20;"), "test.Message").(*Reserved)
+	assert.Equal(t, []*Range{{Start: 16, End: 16, Max: MaxFieldNumber}, {Start: 8, End: 32, Max: MaxFieldNumber}}, out.Ranges)
+	assert.Empty(t, out.Names)
+	assert.Equal(t, "16, 8 to 32", out.String())
+}
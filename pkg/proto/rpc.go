@@ -19,15 +19,20 @@ package proto
 type Parameter struct {
 	Stream bool
 	Type   string
+	// Resolved is the declaration of the type, set by Link.
+	Resolved *Symbol
 }
 
 func NewParameter(stream bool, t string) *Parameter {
 	return &Parameter{Stream: stream, Type: t}
 }
 
+// RpcOption is an option of an rpc, Body is the option constant as written
+// and Constant its structured value.
 type RpcOption struct {
 	*Qualified
-	Body string
+	Body     string
+	Constant *OptionValue
 }
 
 func NewRpcOption(namespace string, name string, comment Comment, body string) *RpcOption {
@@ -37,7 +42,8 @@ func NewRpcOption(namespace string, name string, comment Comment, body string) *
 			Name:      name,
 			Comment:   comment,
 		},
-		Body: body,
+		Body:     body,
+		Constant: NewOptionValue(body),
 	}
 }
 
@@ -36,26 +36,39 @@ func (rv *RpcVisitor) CanVisit(line *Line) bool {
 	return rv.RpcLineMatcher.MatchString(line.Syntax)
 }
 
-func ParseInArgs(values []string, rpc *Rpc) {
-	inArgs := strings.Split(values[2], Comma)
-	for _, i := range inArgs {
-		if strings.HasPrefix(i, "stream") {
-			rpc.AddInputParameter(NewParameter(true, strings.TrimSpace(i[strings.Index(i, Space):])))
-		} else {
-			rpc.AddInputParameter(NewParameter(false, strings.TrimSpace(i)))
+// ParseParameters reads the comma separated parameters of an rpc, e.g.
+// `stream test.Book`, it returns false when a parameter is not a type
+// optionally preceded by the stream keyword.
+func ParseParameters(in string) ([]*Parameter, bool) {
+	out := make([]*Parameter, 0)
+	for _, i := range strings.Split(in, Comma) {
+		fields := strings.Fields(i)
+		switch {
+		case len(fields) == 1 && fields[0] != KeywordStream:
+			out = append(out, NewParameter(false, fields[0]))
+		case len(fields) == 2 && fields[0] == KeywordStream:
+			out = append(out, NewParameter(true, fields[1]))
+		default:
+			return out, false
 		}
 	}
+	return out, true
 }
 
-func ParseReturnArgs(values []string, rpc *Rpc) {
-	returnArgs := strings.Split(values[3], Comma)
-	for _, i := range returnArgs {
-		if strings.HasPrefix(i, "stream") {
-			rpc.AddReturnParameter(NewParameter(true, strings.TrimSpace(i[strings.Index(i, Space):])))
-		} else {
-			rpc.AddReturnParameter(NewParameter(false, strings.TrimSpace(i)))
-		}
-	}
+// ParseInArgs adds the request parameters of the rpc, it returns false when
+// they are invalid.
+func ParseInArgs(values []string, rpc *Rpc) bool {
+	parameters, ok := ParseParameters(values[2])
+	rpc.AddInputParameter(parameters...)
+	return ok
+}
+
+// ParseReturnArgs adds the response parameters of the rpc, it returns false
+// when they are invalid.
+func ParseReturnArgs(values []string, rpc *Rpc) bool {
+	parameters, ok := ParseParameters(values[3])
+	rpc.AddReturnParameter(parameters...)
+	return ok
 }
 
 func (rv *RpcVisitor) Visit(scanner Scanner, in *Line, namespace string) interface{} {
@@ -63,31 +76,47 @@ func (rv *RpcVisitor) Visit(scanner Scanner, in *Line, namespace string) interfa
 
 	values := rv.RpcLineMatcher.FindStringSubmatch(in.Syntax)
 	out := NewRpc(namespace, values[1], in.Comment)
-	ParseInArgs(values, out)
-	ParseReturnArgs(values, out)
+	out.Location = in.Location
+	out.Comments = in.Comments
+	if !ParseInArgs(values, out) || !ParseReturnArgs(values, out) {
+		Report(scanner, SeverityError, in, namespace, "invalid rpc `%s`", in.Syntax)
+		if in.Token == OpenBrace {
+			SkipBlock(scanner)
+		}
+		return nil
+	}
+
+	if in.Token != OpenBrace {
+		return out
+	}
 
+	optionVisitor := &OptionVisitor{}
+	comment := Comment(Empty)
 	for scanner.Scan() {
 		line := scanner.ReadLine()
-		if strings.HasPrefix(line.Syntax, "option") {
-			optionName := line.Syntax[strings.Index(line.Syntax, "(")+1 : strings.Index(line.Syntax, ")")]
-			optionBody := ""
-			for scanner.Scan() {
-				oBody := scanner.ReadLine()
-				optionBody += oBody.Syntax
-				if line.Token == Semicolon {
-					break
-				}
+		if line.Token == CloseBrace {
+			break
+		}
+		if line.Token == InlineCommentPrefix || line.Token == MultiLineCommentInitiator {
+			comment = comment.Append(line.Comment).AddSpace()
+		} else if optionVisitor.CanVisit(line) {
+			if line.Token == OpenBrace {
+				line = ReadStatement(scanner, line)
 			}
-			if len(strings.TrimSpace(optionBody)) > 0 {
-				out.AddRpcOption(NewRpcOption(
+			split := strings.SplitN(line.Syntax[len(PrefixOption):], "=", 2)
+			if len(split) == 2 {
+				option := NewRpcOption(
 					Join(Period, namespace, out.Name),
-					optionName,
-					"",
-					optionBody))
+					SpaceRemover.ReplaceAllString(split[0], Empty),
+					comment.AddSpace().Append(line.Comment).TrimSpace(),
+					strings.TrimSpace(split[1]))
+				option.Location = line.Location
+				option.Comments = line.Comments
+				out.AddRpcOption(option)
+			} else {
+				Report(scanner, SeverityError, line, Join(Period, namespace, out.Name), "invalid option `%s`", line.Syntax)
 			}
-		}
-		if line.Token == CloseBrace {
-			break
+			comment = comment.Clear()
 		}
 	}
 	return out
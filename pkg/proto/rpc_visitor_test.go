@@ -117,7 +117,7 @@ func TestRpcVisitor_Visit(t *testing.T) {
 				},
 				InputParameters:  []*Parameter{NewParameter(false, "google.protobuf.Empty")},
 				ReturnParameters: []*Parameter{NewParameter(true, "test.location.PhysicalLocation")},
-				Options:          []*RpcOption{NewRpcOption("test.LocationService.List", "google.api.http", "", "}")},
+				Options:          []*RpcOption{NewRpcOption("test.LocationService.List", "(google.api.http)", "", `{ get: "/locations" }`)},
 			},
 		},
 		{
@@ -142,7 +142,7 @@ func TestRpcVisitor_Visit(t *testing.T) {
 				},
 				InputParameters:  []*Parameter{NewParameter(false, "google.protobuf.Empty")},
 				ReturnParameters: []*Parameter{NewParameter(false, "test.location.PhysicalLocation")},
-				Options:          []*RpcOption{NewRpcOption("test.LocationService.List", "google.api.http", "", "}")},
+				Options:          []*RpcOption{NewRpcOption("test.LocationService.List", "(google.api.http)", "", `{ get: "/locations" }`)},
 			},
 		},
 	}
@@ -163,3 +163,43 @@ func TestRpcVisitor_Visit(t *testing.T) {
 		})
 	}
 }
+
+func TestRpcVisitor_VisitWithoutBody(t *testing.T) {
+	rv := NewRpcVisitor()
+	testScanner := NewTestScanner(`}`)
+	out := rv.Visit(testScanner, &Line{Syntax: "rpc Delete(DeleteRequest) returns (google.protobuf.Empty)", Token: Semicolon}, "test.Service").(*Rpc)
+	assert.Equal(t, "Delete", out.Name)
+	assert.Len(t, out.Options, 0)
+	// The closing brace of the service must not be consumed
+	assert.True(t, testScanner.Scan())
+}
+
+func TestRpcVisitor_VisitOptionNames(t *testing.T) {
+	// Option names are kept as declared, without whitespace
+	rv := NewRpcVisitor()
+	testScanner := NewTestScanner(`
+	option deprecated = true;
+	option ( my.option ).field = 1;
+}`)
+	out := rv.Visit(testScanner, &Line{Syntax: "rpc Delete(DeleteRequest) returns (google.protobuf.Empty)", Token: OpenBrace}, "test.Service").(*Rpc)
+	assert.Len(t, out.Options, 2)
+	assert.Equal(t, "deprecated", out.Options[0].Name)
+	assert.Equal(t, "(my.option).field", out.Options[1].Name)
+}
+
+func TestPackage_ReadRpcOptions(t *testing.T) {
+	p := NewPackage("data/test/options/options.proto")
+	diagnostics, err := p.Read(false)
+	assert.Nil(t, err)
+	assert.Empty(t, diagnostics)
+	assert.Len(t, p.Services, 1)
+	assert.Len(t, p.Services[0].Methods, 2)
+	get := p.Services[0].Methods[0]
+	assert.Len(t, get.Options, 2)
+	assert.Equal(t, "(google.api.http)", get.Options[0].Name)
+	assert.Equal(t, Comment("The REST binding"), get.Options[0].Comment)
+	assert.Equal(t, "/v1/books/*", get.Options[0].Constant.Get("get").Value)
+	assert.Equal(t, "/v1/{name=shelves/*/books/*}", get.Options[0].Constant.Get("additional_bindings").Get("get").Value)
+	assert.Equal(t, "deprecated", get.Options[1].Name)
+	assert.Equal(t, Comment("Deletes a book"), p.Services[0].Methods[1].Comment)
+}
@@ -19,6 +19,7 @@ package proto
 type Service struct {
 	*Qualified
 	Methods []*Rpc
+	Options []*Option
 }
 
 func NewService(namespace string, name string, comment Comment) *Service {
@@ -29,6 +30,7 @@ func NewService(namespace string, name string, comment Comment) *Service {
 			Comment:   comment,
 		},
 		Methods: make([]*Rpc, 0),
+		Options: make([]*Option, 0),
 	}
 }
 
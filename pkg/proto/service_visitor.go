@@ -24,7 +24,7 @@ type ServiceVisitor struct {
 
 func NewServiceVisitor() *ServiceVisitor {
 	visitors := make([]Visitor, 0)
-	visitors = append(visitors, NewRpcVisitor(), &CommentVisitor{})
+	visitors = append(visitors, NewRpcVisitor(), &OptionVisitor{}, &CommentVisitor{})
 	return &ServiceVisitor{Visitors: visitors}
 }
 
@@ -36,7 +36,14 @@ func (sv *ServiceVisitor) Visit(scanner Scanner, in *Line, namespace string) int
 	Log.Debugf("Visiting Service: %v\n", in)
 
 	values := in.SplitSyntax()
+	if len(values) != 2 {
+		Report(scanner, SeverityError, in, namespace, "invalid service `%s`", in.Syntax)
+		SkipBlock(scanner)
+		return nil
+	}
 	out := NewService(namespace, values[1], in.Comment)
+	out.Location = in.Location
+	out.Comments = in.Comments
 
 	comment := Comment("")
 
@@ -46,6 +53,7 @@ func (sv *ServiceVisitor) Visit(scanner Scanner, in *Line, namespace string) int
 		if line.Token == CloseBrace {
 			break
 		}
+		ReportUnrecognized(scanner, sv.Visitors, line, Join(Period, namespace, out.Name))
 		for _, visitor := range sv.Visitors {
 			if visitor.CanVisit(line) {
 				rt := visitor.Visit(scanner, line, Join(Period, namespace, out.Name))
@@ -54,6 +62,10 @@ func (sv *ServiceVisitor) Visit(scanner Scanner, in *Line, namespace string) int
 					t.Comment = comment.AddSpace().Append(t.Comment).TrimSpace()
 					out.AddRpc(t)
 					comment = comment.Clear()
+				case *Option:
+					t.Comment = comment.AddSpace().Append(t.Comment).TrimSpace()
+					out.Options = append(out.Options, t)
+					comment = comment.Clear()
 				case Comment:
 					comment = comment.Append(t).AddSpace()
 				}
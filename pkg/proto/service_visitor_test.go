@@ -107,9 +107,10 @@ func TestServiceVisitor_Visit(t *testing.T) {
 					},
 					InputParameters:  []*Parameter{NewParameter(false, "google.protobuf.Empty")},
 					ReturnParameters: []*Parameter{NewParameter(true, "test.location.PhysicalLocation")},
-					Options:          []*RpcOption{NewRpcOption("test.service.LocationService.List", "google.api.http", "", "}")},
+					Options:          []*RpcOption{NewRpcOption("test.service.LocationService.List", "(google.api.http)", "", `{ get: "/locations" }`)},
 				},
-			}},
+			},
+			Options: make([]*Option, 0)},
 		},
 	}
 	for _, tt := range tests {
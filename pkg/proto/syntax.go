@@ -0,0 +1,34 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+// Syntax is the `syntax = "proto3";` or `edition = "2023";` declaration of a
+// protobuf file.
+type Syntax struct {
+	Keyword string
+	Value   string
+}
+
+// NewSyntax is the Syntax constructor
+func NewSyntax(keyword string, value string) *Syntax {
+	return &Syntax{Keyword: keyword, Value: value}
+}
+
+// IsEdition returns true if the declaration is an edition rather than a syntax.
+func (s *Syntax) IsEdition() bool {
+	return s.Keyword == KeywordEdition
+}
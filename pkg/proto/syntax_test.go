@@ -0,0 +1,32 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSyntax(t *testing.T) {
+	assert.Equal(t, &Syntax{Keyword: "syntax", Value: "proto3"}, NewSyntax("syntax", "proto3"))
+}
+
+func TestSyntax_IsEdition(t *testing.T) {
+	assert.False(t, NewSyntax("syntax", "proto3").IsEdition())
+	assert.True(t, NewSyntax("edition", "2023").IsEdition())
+}
@@ -0,0 +1,41 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"strings"
+)
+
+// SyntaxVisitor reads the syntax or edition declaration of a file.
+type SyntaxVisitor struct {
+}
+
+// CanVisit determines if the line is a `syntax` or `edition` declaration.
+func (sv *SyntaxVisitor) CanVisit(in *Line) bool {
+	split := strings.SplitN(in.Syntax, "=", 2)
+	keyword := strings.TrimSpace(split[0])
+	return in.Token == Semicolon && len(split) == 2 &&
+		(keyword == KeywordSyntax || keyword == KeywordEdition)
+}
+
+// Visit marshals the line into a Syntax.
+func (sv *SyntaxVisitor) Visit(_ Scanner, in *Line, _ string) interface{} {
+	Log.Debug("Visiting Syntax")
+	split := strings.SplitN(in.Syntax, "=", 2)
+	value := RemoveDoubleQuotes(strings.ReplaceAll(strings.TrimSpace(split[1]), SingleQuote, Empty))
+	return NewSyntax(strings.TrimSpace(split[0]), value)
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyntaxVisitor_CanVisit(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *Line
+		want bool
+	}{
+		{name: "Syntax", in: NewLine(`syntax = "proto3";`), want: true},
+		{name: "Edition", in: NewLine(`edition = "2023";`), want: true},
+		{name: "Compact", in: NewLine(`syntax="proto2";`), want: true},
+		{name: "Attribute", in: NewLine(`string syntax = 1;`), want: false},
+		{name: "Comment", in: NewLine(`// syntax = "proto3";`), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sv := &SyntaxVisitor{}
+			assert.Equalf(t, tt.want, sv.CanVisit(tt.in), "CanVisit(%v)", tt.in)
+		})
+	}
+}
+
+func TestSyntaxVisitor_Visit(t *testing.T) {
+	sv := &SyntaxVisitor{}
+	assert.Equal(t, NewSyntax("syntax", "proto2"), sv.Visit(nil, NewLine(`syntax="proto2";`), ""))
+	assert.Equal(t, NewSyntax("edition", "2023"), sv.Visit(nil, NewLine(`edition = '2023';`), ""))
+}
@@ -23,6 +23,7 @@ import (
 
 type TestScanner struct {
 	internalScanner *bufio.Scanner
+	diagnostics     Diagnostics
 }
 
 func (ts *TestScanner) Scan() bool {
@@ -53,8 +54,17 @@ func (ts *TestScanner) ReadLine() *Line {
 	return NewLine(ts.internalScanner.Text())
 }
 
+func (ts *TestScanner) Report(d *Diagnostic) {
+	ts.diagnostics = append(ts.diagnostics, d)
+}
+
+func (ts *TestScanner) Diagnostics() Diagnostics {
+	return ts.diagnostics
+}
+
 func NewTestScanner(in string) *TestScanner {
 	return &TestScanner{
 		internalScanner: bufio.NewScanner(strings.NewReader(in)),
+		diagnostics:     make(Diagnostics, 0),
 	}
 }
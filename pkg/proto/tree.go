@@ -0,0 +1,84 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Tree is the protobuf files read from a directory, with the files they import.
+type Tree struct {
+	Directory string
+	// Packages are the files of the directory, in the order they are walked.
+	Packages []*Package
+	// Imported are the files imported by the Packages from the include roots
+	// or the bundled files.
+	Imported []*Package
+	Importer *Importer
+	// Diagnostics are the problems found while reading, linking and
+	// validating the files.
+	Diagnostics Diagnostics
+}
+
+// ReadTree reads the protobuf files of the directory and its subdirectories,
+// loads their imports from the include roots, the directory when none is
+// given, and validates them. Files that cannot be read are logged and
+// skipped, the error is returned if the directory cannot be walked.
+func ReadTree(directory string, roots ...string) (*Tree, error) {
+	out := &Tree{Directory: directory, Packages: make([]*Package, 0), Diagnostics: make(Diagnostics, 0)}
+	err := filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasSuffix(path, ProtobufSuffix) {
+			pkg := NewPackage(path)
+			pkgDiagnostics, err := pkg.Read(Log.debug)
+			if err != nil {
+				Log.Errorf("error while reading package %s with value: %v", path, err)
+			}
+			out.Diagnostics = append(out.Diagnostics, pkgDiagnostics...)
+			out.Packages = append(out.Packages, pkg)
+		}
+		return nil
+	})
+
+	if len(roots) == 0 {
+		roots = []string{directory}
+	}
+	out.Importer = NewImporter(roots...)
+	var importDiagnostics Diagnostics
+	out.Imported, importDiagnostics = out.Importer.Load(out.Packages...)
+	out.Diagnostics = append(out.Diagnostics, importDiagnostics...)
+	out.Diagnostics = append(out.Diagnostics, Validate(out.All()...)...)
+	return out, err
+}
+
+// All returns the files of the directory followed by the imported files.
+func (t *Tree) All() []*Package {
+	return append(append(make([]*Package, 0, len(t.Packages)+len(t.Imported)), t.Packages...), t.Imported...)
+}
+
+// RelativePath returns the path of a file of the directory relative to it, with
+// forward slashes, or the path itself for other files.
+func (t *Tree) RelativePath(p *Package) string {
+	if rel, err := filepath.Rel(t.Directory, p.Path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(p.Path)
+}
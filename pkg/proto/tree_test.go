@@ -0,0 +1,42 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadTree(t *testing.T) {
+	tree, err := ReadTree("data/breaking/old")
+	assert.Nil(t, err)
+	assert.Empty(t, tree.Diagnostics)
+	paths := make([]string, 0)
+	for _, p := range tree.Packages {
+		paths = append(paths, tree.RelativePath(p))
+	}
+	assert.Equal(t, []string{"library/library.proto", "library/loan.proto", "shelf/shelf.proto"}, paths)
+	// The shelf is read once, as a file of the directory and as an import
+	assert.Empty(t, tree.Imported)
+	assert.Len(t, tree.All(), 3)
+	assert.Equal(t, tree.Packages[2], tree.Packages[0].Imports[0].Package)
+	assert.Equal(t, "google/protobuf/empty.proto", tree.RelativePath(NewPackage("google/protobuf/empty.proto")))
+
+	_, err = ReadTree("data/missing")
+	assert.NotNil(t, err)
+}
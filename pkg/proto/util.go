@@ -17,9 +17,6 @@
 package proto
 
 import (
-	"bufio"
-	"os"
-	"regexp"
 	"strconv"
 	"strings"
 	"unicode"
@@ -33,8 +30,10 @@ func RemoveDoubleQuotes(in string) string {
 	return strings.ReplaceAll(in, DoubleQuote, Empty)
 }
 
+// ParseOrdinal parses a decimal, hexadecimal or octal number, returning 0 when
+// the value is not a number.
 func ParseOrdinal(in string) int {
-	i, err := strconv.ParseInt(in, 10, 64)
+	i, err := strconv.ParseInt(in, 0, 64)
 	if err != nil {
 		Log.Debugf("Failed to parse %s for integer", in)
 		return 0
@@ -42,6 +41,12 @@ func ParseOrdinal(in string) int {
 	return int(i)
 }
 
+// IsNumeric determines if the value is a decimal, hexadecimal or octal integer.
+func IsNumeric(in string) bool {
+	_, err := strconv.ParseInt(strings.TrimPrefix(in, Hyphen), 0, 64)
+	return err == nil
+}
+
 func FormatLine(in string) string {
 	return strings.TrimSpace(SpaceRemover.ReplaceAllString(in, " "))
 }
@@ -70,63 +75,22 @@ func NormalizeName(in string) string {
 	return strings.ReplaceAll(strings.ToLower(clean), Space, "_")
 }
 
-func ReadFileToArray(file *os.File) []string {
-	cleaner := regexp.MustCompile(`\s+|\n`)
-	lines := make([]string, 0)
-
-	scanner := bufio.NewScanner(file)
-	scanner.Split(bufio.ScanRunes)
-
-	line := ""
-	tokenReached := false
-
-	for scanner.Scan() {
-		rune := scanner.Text()
-		if !strings.HasPrefix(line, MultiLineCommentInitiator) && (rune == Semicolon || rune == OpenBrace || rune == CloseBrace) {
-			lines = append(lines, cleaner.ReplaceAllString(line+rune, Space))
-			tokenReached = true
-			line = ""
-		} else if strings.HasPrefix(line, InlineCommentPrefix) && rune == EndL {
-			// Swap the comment with the tokenized line, accounting for: [{|;|}] //some comment
-			if tokenReached {
-				lines = append(lines, cleaner.ReplaceAllString(strings.TrimSpace(line), Space))
-				// Swap first and last element
-				pLine := lines[len(lines)-2]
-				cLine := lines[len(lines)-1]
-				lines[len(lines)-2] = cLine
-				lines[len(lines)-1] = pLine
-			} else {
-				lines = append(lines, cleaner.ReplaceAllString(strings.TrimSpace(line), Space))
-			}
-			line = ""
-			tokenReached = false
-		} else if strings.HasPrefix(line, MultiLineCommentInitiator) && strings.HasSuffix(line, MultilineCommentTerminator) {
-			lines = append(lines, cleaner.ReplaceAllString(strings.TrimSpace(line), Space))
-			line = ""
-		} else {
-			if rune != EndL {
-				if rune == Space {
-					if len(line) > 0 {
-						line += rune
-					}
-				} else {
-					line += rune
-				}
-			} else {
-				// Add a space to account for new lines in multiline comment
-				if strings.HasPrefix(line, MultiLineCommentInitiator) {
-					line += CommentNewLine
-				}
-				tokenReached = false
+// UpperSnakeCase converts a PascalCase or camelCase name to UPPER_SNAKE_CASE,
+// keeping acronyms together, e.g. HTTPMethod is HTTP_METHOD.
+func UpperSnakeCase(in string) string {
+	runes := []rune(in)
+	out := ""
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			previous := runes[i-1]
+			next := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || unicode.IsUpper(previous) && next {
+				out += "_"
 			}
 		}
+		out += string(unicode.ToUpper(r))
 	}
-	if Log.debug {
-		for i, l := range lines {
-			Log.Debugf("%d. %s", i, l)
-		}
-	}
-	return lines
+	return out
 }
 
 // RemoveNameQualification formats a parameter into a single name, this is due
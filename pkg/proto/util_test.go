@@ -17,7 +17,6 @@
 package proto
 
 import (
-	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -101,31 +100,6 @@ func TestParseOrdinal(t *testing.T) {
 	}
 }
 
-func TestReadFileToArray(t *testing.T) {
-	type args struct {
-		file *os.File
-	}
-
-	fil, err := os.Open("data/input_test_file.txt")
-
-	if err != nil {
-		assert.Fail(t, "failed to open file", err)
-	}
-
-	tests := []struct {
-		name string
-		args args
-		want []string
-	}{
-		{name: "Test 001", args: args{file: fil}, want: []string{"// this", "// is", "// for", "// testing"}},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			assert.Equalf(t, tt.want, ReadFileToArray(tt.args.file), "ReadFileToArray(%v)", tt.args.file)
-		})
-	}
-}
-
 func TestRemoveDoubleQuotes(t *testing.T) {
 	type args struct {
 		in string
@@ -179,3 +153,30 @@ func TestRemoveSemicolon(t *testing.T) {
 		})
 	}
 }
+
+func TestIsNumeric(t *testing.T) {
+	assert.True(t, IsNumeric("15"))
+	assert.True(t, IsNumeric("-1"))
+	assert.True(t, IsNumeric("0x1F"))
+	assert.False(t, IsNumeric("max"))
+	assert.False(t, IsNumeric(`"foo"`))
+}
+
+func TestUpperSnakeCase(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "Pascal Case", in: "AddressType", want: "ADDRESS_TYPE"},
+		{name: "Camel Case", in: "addressType", want: "ADDRESS_TYPE"},
+		{name: "Acronym", in: "HTTPMethod", want: "HTTP_METHOD"},
+		{name: "Digits", in: "V2Status", want: "V2_STATUS"},
+		{name: "Single Word", in: "Status", want: "STATUS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, UpperSnakeCase(tt.in))
+		})
+	}
+}
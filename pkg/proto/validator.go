@@ -0,0 +1,151 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import "fmt"
+
+// Field numbers reserved for the protobuf implementation.
+const (
+	FirstReservedFieldNumber = 19000
+	LastReservedFieldNumber  = 19999
+)
+
+// OptionAllowAlias is the enum option allowing values to share a number.
+const OptionAllowAlias = "allow_alias"
+
+// Validate checks that the packages form a legal schema. The types they
+// reference are linked, as Link does, and the messages and enums of each
+// package are validated. The problems found are returned as diagnostics.
+func Validate(packages ...*Package) Diagnostics {
+	out := Link(packages...)
+	for _, p := range packages {
+		out = append(out, p.Validate()...)
+	}
+	return out
+}
+
+// Validate checks the messages and enums of the package, the type references
+// are checked by Link.
+func (p *Package) Validate() Diagnostics {
+	out := make(Diagnostics, 0)
+	features := DefaultFeatures(p.Syntax, p.Edition).Merge(p.Features)
+	for _, m := range p.Messages {
+		out = append(out, m.Validate(features)...)
+	}
+	for _, e := range p.Enums {
+		out = append(out, e.Validate(features)...)
+	}
+	return out
+}
+
+// Validate checks that the field numbers are unique, within the allowed range
+// and not reserved, and that the field names are not reserved. The nested
+// messages and enums are validated, scope is the effective feature set of the
+// enclosing declaration, or nil if unknown.
+func (m *Message) Validate(scope *Features) Diagnostics {
+	out := make(Diagnostics, 0)
+	if scope == nil {
+		scope = &Features{}
+	}
+	features := scope.Merge(m.Features)
+	numbers := make(map[int]*Attribute)
+	for _, a := range m.Attributes {
+		element := QualifiedName(Join(Period, a.Qualifier, a.Name))
+		if existing, ok := numbers[a.Ordinal]; ok {
+			out = append(out, NewDiagnostic(SeverityError, a.Location, element,
+				fmt.Sprintf("field number %d of `%s` is already used by `%s`", a.Ordinal, a.Name, existing.Name)))
+		} else {
+			numbers[a.Ordinal] = a
+		}
+		if a.Ordinal < 1 {
+			out = append(out, NewDiagnostic(SeverityError, a.Location, element,
+				fmt.Sprintf("field number %d of `%s` must be greater than 0", a.Ordinal, a.Name)))
+		} else if a.Ordinal > MaxFieldNumber {
+			out = append(out, NewDiagnostic(SeverityError, a.Location, element,
+				fmt.Sprintf("field number %d of `%s` is greater than %d", a.Ordinal, a.Name, MaxFieldNumber)))
+		} else if a.Ordinal >= FirstReservedFieldNumber && a.Ordinal <= LastReservedFieldNumber {
+			out = append(out, NewDiagnostic(SeverityError, a.Location, element,
+				fmt.Sprintf("field number %d of `%s` is reserved for the protobuf implementation (%d to %d)",
+					a.Ordinal, a.Name, FirstReservedFieldNumber, LastReservedFieldNumber)))
+		}
+		out = append(out, validateReserved(m.Reserved, a.Ordinal, a.Name, a.Location, element, "field")...)
+	}
+	for _, nested := range m.Messages {
+		out = append(out, nested.Validate(features)...)
+	}
+	for _, e := range m.Enums {
+		out = append(out, e.Validate(features)...)
+	}
+	return out
+}
+
+// Validate checks that the values are unique unless the enum allows aliases,
+// that the first value of an open enum is 0, and that the values and their
+// names are not reserved, scope is as for Message.Validate.
+func (e *Enum) Validate(scope *Features) Diagnostics {
+	out := make(Diagnostics, 0)
+	if scope == nil {
+		scope = &Features{}
+	}
+	features := scope.Merge(e.Features)
+	if len(e.Values) > 0 && e.Values[0].Ordinal != 0 && features.EnumType == EnumTypeOpen {
+		first := e.Values[0]
+		out = append(out, NewDiagnostic(SeverityError, first.Location, QualifiedName(Join(Period, e.Qualifier, first.Value)),
+			fmt.Sprintf("the first value of an open enum must be 0, `%s` is %d", first.Value, first.Ordinal)))
+	}
+	aliases := e.AllowsAlias()
+	values := make(map[int]*EnumValue)
+	for _, v := range e.Values {
+		element := QualifiedName(Join(Period, e.Qualifier, v.Value))
+		if existing, ok := values[v.Ordinal]; ok && !aliases {
+			out = append(out, NewDiagnostic(SeverityError, v.Location, element,
+				fmt.Sprintf("enum value %d of `%s` is already used by `%s`, set `%s = true` to alias it",
+					v.Ordinal, v.Value, existing.Value, OptionAllowAlias)))
+		} else if !ok {
+			values[v.Ordinal] = v
+		}
+		out = append(out, validateReserved(e.Reserved, v.Ordinal, v.Value, v.Location, element, "enum value")...)
+	}
+	return out
+}
+
+// AllowsAlias determines if the enum sets the allow_alias option.
+func (e *Enum) AllowsAlias() bool {
+	for _, o := range e.Options {
+		if o.Name == OptionAllowAlias && o.Value == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// validateReserved reports the number and the name of a field or enum value
+// declared by one of the reserved statements.
+func validateReserved(reserved []*Reserved, number int, name string, location Location, element string, kind string) Diagnostics {
+	out := make(Diagnostics, 0)
+	for _, r := range reserved {
+		if r.ContainsNumber(number) {
+			out = append(out, NewDiagnostic(SeverityError, location, element,
+				fmt.Sprintf("%s number %d of `%s` is reserved", kind, number, name)))
+		}
+		if r.ContainsName(name) {
+			out = append(out, NewDiagnostic(SeverityError, location, element,
+				fmt.Sprintf("%s name `%s` is reserved", kind, name)))
+		}
+	}
+	return out
+}
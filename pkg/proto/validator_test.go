@@ -0,0 +1,124 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "Valid", in: `syntax = "proto3";
+message A {
+  reserved 3, 4 to 6;
+  reserved "old";
+  string a = 1;
+  oneof o {
+    int32 b = 2;
+  }
+  map<string, B> c = 7;
+}
+enum B {
+  option allow_alias = true;
+  B_UNSPECIFIED = 0;
+  B_DEFAULT = 0;
+}`},
+		{name: "Duplicate Field Number", in: `message A {
+  string a = 1;
+  oneof o {
+    int32 b = 1;
+  }
+}`, want: "a.proto:4:5: error: field number 1 of `b` is already used by `a` (A.b)"},
+		{name: "Reserved Number", in: `message A {
+  reserved 2 to 4;
+  string a = 3;
+}`, want: "a.proto:3:3: error: field number 3 of `a` is reserved (A.a)"},
+		{name: "Reserved Name", in: `message A {
+  reserved "a";
+  string a = 1;
+}`, want: "a.proto:3:3: error: field name `a` is reserved (A.a)"},
+		{name: "Implementation Number", in: `message A {
+  string a = 19000;
+}`, want: "a.proto:2:3: error: field number 19000 of `a` is reserved for the protobuf implementation (19000 to 19999) (A.a)"},
+		{name: "Number Out Of Range", in: `message A {
+  message B {
+    string a = 536870912;
+  }
+}`, want: "a.proto:3:5: error: field number 536870912 of `a` is greater than 536870911 (A.B.a)"},
+		{name: "Duplicate Enum Value", in: `enum E {
+  E_A = 0;
+  E_B = 0;
+}`, want: "a.proto:3:3: error: enum value 0 of `E_B` is already used by `E_A`, set `allow_alias = true` to alias it (E.E_B)"},
+		{name: "Reserved Enum Value", in: `enum E {
+  reserved 1, "E_C";
+  E_A = 0;
+  E_B = 1;
+  E_C = 2;
+}`, want: "a.proto:4:3: error: enum value number 1 of `E_B` is reserved (E.E_B)\n" +
+			"a.proto:5:3: error: enum value name `E_C` is reserved (E.E_C)"},
+		{name: "Proto3 First Enum Value", in: `syntax = "proto3";
+message A {
+  enum E {
+    E_A = 1;
+  }
+}`, want: "a.proto:4:5: error: the first value of an open enum must be 0, `E_A` is 1 (A.E.E_A)"},
+		{name: "Proto2 First Enum Value", in: `syntax = "proto2";
+enum E {
+  E_A = 1;
+}`},
+		{name: "Closed Edition Enum", in: `edition = "2023";
+enum E {
+  option features.enum_type = CLOSED;
+  E_A = 1;
+}`},
+		{name: "Unresolved Type", in: `message A {
+  Missing a = 1;
+}`, want: "a.proto:2:3: warning: unresolved type Missing (A.a)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParseString("a.proto", tt.in)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, Validate(p).String())
+		})
+	}
+}
+
+func TestMessage_Validate(t *testing.T) {
+	var validatable Validatable = NewMessage()
+	assert.False(t, validatable.IsValid())
+	// The parser rejects such fields, models built otherwise are checked
+	m := NewMessage()
+	m.Qualifier, m.Name = "A", "A"
+	a := NewAttribute("A", Empty)
+	a.Name, a.Kind, a.Ordinal = "a", []string{"string"}, 0
+	a.Location = Location{File: "a.proto", Start: Position{Line: 2, Column: 3}}
+	m.Attributes = append(m.Attributes, a)
+	assert.Equal(t, "a.proto:2:3: error: field number 0 of `a` must be greater than 0 (A.a)", m.Validate(nil).String())
+	// Without a scope, the enum type is unknown
+	e := NewEnum("E", "E", Empty)
+	e.Values = append(e.Values, NewEnumValue("E", "1", "E_A", Empty))
+	assert.True(t, e.IsValid())
+	assert.Empty(t, e.Validate(nil))
+	assert.Len(t, e.Validate(DefaultFeatures(SyntaxProto3, Empty)), 1)
+}
@@ -16,7 +16,10 @@
 
 package proto
 
-import "errors"
+import (
+	"errors"
+	"io"
+)
 
 // Effective Final Variables
 
@@ -31,6 +34,12 @@ func SetDebug(debug bool) {
 	Log.debug = debug
 }
 
+// SetOutput directs the messages of the Log to the writer, e.g. the standard
+// error when a report is printed to the standard output.
+func SetOutput(w io.Writer) {
+	Log.writer = w
+}
+
 var RegisteredVisitors []Visitor
 
 // Initialize the Visitors
@@ -38,11 +47,16 @@ func init() {
 	// Handle Comments
 	RegisteredVisitors = append(RegisteredVisitors,
 		&CommentVisitor{},
+		&SyntaxVisitor{},
 		&PackageVisitor{},
 		&ImportVisitor{},
 		&OptionVisitor{},
 		&MessageVisitor{},
+		NewOneofVisitor(),
+		NewExtendVisitor(),
+		&GroupVisitor{},
 		&ReservedVisitor{},
+		&ExtensionRangeVisitor{},
 		NewEnumVisitor(),
 		NewAttributeVisitor(),
 		NewServiceVisitor())
@@ -0,0 +1,758 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	protobuf "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// DescriptorFormat selects the encoding of a written FileDescriptorSet.
+type DescriptorFormat int
+
+const (
+	// DescriptorBinary is the protobuf wire format, as written by protoc.
+	DescriptorBinary DescriptorFormat = iota
+	// DescriptorJSON is the protobuf JSON format.
+	DescriptorJSON
+)
+
+// DescriptorFormatNames are the names of the descriptor formats, used by the
+// -descriptor_set_format flag.
+var DescriptorFormatNames = map[string]DescriptorFormat{
+	"binary": DescriptorBinary,
+	"json":   DescriptorJSON,
+}
+
+// ParseDescriptorFormat reads a descriptor format from its name.
+func ParseDescriptorFormat(in string) (DescriptorFormat, error) {
+	if format, ok := DescriptorFormatNames[in]; ok {
+		return format, nil
+	}
+	return DescriptorBinary, fmt.Errorf("unknown descriptor format %q, expected binary or json", in)
+}
+
+// MarshalDescriptorSet encodes a FileDescriptorSet in the format.
+func MarshalDescriptorSet(set *descriptorpb.FileDescriptorSet, format DescriptorFormat) ([]byte, error) {
+	if format == DescriptorJSON {
+		return protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(set)
+	}
+	return protobuf.Marshal(set)
+}
+
+// PackagesToDescriptorSet converts linked packages into a FileDescriptorSet, as
+// protoc --descriptor_set_out --include_source_info writes it. The files are
+// named by names, or by their paths, and the imports of a file precede it.
+// Options that cannot be set, such as custom options whose extension is not
+// declared in the packages, are returned as warnings.
+func PackagesToDescriptorSet(packages []*Package, names map[*Package]string) (*descriptorpb.FileDescriptorSet, Diagnostics) {
+	included := make(map[*Package]bool)
+	for _, p := range packages {
+		included[p] = true
+	}
+	ordered := make([]*Package, 0, len(packages))
+	visited := make(map[*Package]bool)
+	var visit func(p *Package)
+	visit = func(p *Package) {
+		if visited[p] {
+			return
+		}
+		visited[p] = true
+		for _, i := range p.Imports {
+			if included[i.Package] {
+				visit(i.Package)
+			}
+		}
+		ordered = append(ordered, p)
+	}
+	for _, p := range packages {
+		visit(p)
+	}
+
+	out := &descriptorpb.FileDescriptorSet{}
+	writers := make([]*descriptorWriter, 0, len(ordered))
+	for _, p := range ordered {
+		name, ok := names[p]
+		if !ok {
+			name = p.Path
+		}
+		w := &descriptorWriter{p: p}
+		out.File = append(out.File, w.write(name))
+		writers = append(writers, w)
+	}
+
+	// The options are set once all the files are written, so the custom
+	// options are resolved to the extensions declared in any of them
+	diagnostics := make(Diagnostics, 0)
+	types := descriptorTypes(out)
+	for _, w := range writers {
+		for _, o := range w.options {
+			number, err := setOption(o.target.ProtoReflect(), o, types)
+			if err != nil {
+				location := o.location
+				if !location.IsValid() {
+					location = o.declaration
+				}
+				diagnostics = append(diagnostics, NewDiagnostic(SeverityWarning, location, o.element,
+					fmt.Sprintf("option `%s` is not written: %v", o.name, err)))
+				continue
+			}
+			w.location(child(o.path, int32(number)), o.location, o.comments)
+		}
+		w.file.SourceCodeInfo = &descriptorpb.SourceCodeInfo{Location: w.locations}
+	}
+	return out, diagnostics
+}
+
+// descriptorWriter converts a package into a file descriptor, the options are
+// collected to be set once all the files are written.
+type descriptorWriter struct {
+	p         *Package
+	file      *descriptorpb.FileDescriptorProto
+	locations []*descriptorpb.SourceCodeInfo_Location
+	options   []*descriptorOption
+}
+
+// descriptorOption is an option to set in the target options message, path is
+// the path of the options message in the SourceCodeInfo.
+type descriptorOption struct {
+	target   protobuf.Message
+	path     []int32
+	scope    string
+	element  string
+	name     string
+	value    *OptionValue
+	location Location
+	comments *Comments
+	// declaration is the location of the element, reported for the options
+	// without a location of their own, such as field options.
+	declaration Location
+}
+
+func (w *descriptorWriter) write(name string) *descriptorpb.FileDescriptorProto {
+	p := w.p
+	w.file = &descriptorpb.FileDescriptorProto{Name: protobuf.String(name)}
+	f := w.file
+	if len(p.Name) > 0 {
+		f.Package = protobuf.String(p.Name)
+		w.location([]int32{filePackagePath}, p.Location, p.Comments)
+	}
+	switch {
+	case len(p.Edition) > 0:
+		f.Syntax = protobuf.String("editions")
+		if edition, ok := descriptorpb.Edition_value["EDITION_"+p.Edition]; ok {
+			f.Edition = descriptorpb.Edition(edition).Enum()
+		}
+	case p.Syntax == SyntaxProto3:
+		f.Syntax = protobuf.String(SyntaxProto3)
+	}
+	for i, imp := range p.Imports {
+		f.Dependency = append(f.Dependency, imp.Path)
+		w.location([]int32{fileDependenciesPath, int32(i)}, imp.Location, imp.Comments)
+	}
+	for i, m := range p.Messages {
+		f.MessageType = append(f.MessageType, w.message(m, []int32{fileMessagesPath, int32(i)}))
+	}
+	for i, e := range p.Enums {
+		f.EnumType = append(f.EnumType, w.enum(e, []int32{fileEnumsPath, int32(i)}))
+	}
+	for i, s := range p.Services {
+		f.Service = append(f.Service, w.service(s, []int32{fileServicesPath, int32(i)}))
+	}
+	f.Extension = w.extensions(p.Extends, []int32{fileExtensionsPath})
+	if len(p.Options) > 0 {
+		f.Options = &descriptorpb.FileOptions{}
+		w.addOptions(f.Options, []int32{fileOptionsPath}, p.Name, p.Name, p.Location, p.Options)
+	}
+	return f
+}
+
+// location records the source location and comments of a path, elements
+// without a location are not recorded.
+func (w *descriptorWriter) location(path []int32, location Location, comments *Comments) {
+	if !location.IsValid() {
+		return
+	}
+	span := []int32{int32(location.Start.Line - 1), int32(location.Start.Column - 1)}
+	switch {
+	case location.End.Line == location.Start.Line:
+		span = append(span, int32(location.End.Column))
+	case location.End.Line > location.Start.Line:
+		span = append(span, int32(location.End.Line-1), int32(location.End.Column))
+	default:
+		span = append(span, span[1])
+	}
+	out := &descriptorpb.SourceCodeInfo_Location{Path: path, Span: span}
+	if comments != nil {
+		out.LeadingComments = sourceComment(comments.Leading)
+		out.TrailingComments = sourceComment(comments.Trailing)
+		for _, d := range comments.Detached {
+			out.LeadingDetachedComments = append(out.LeadingDetachedComments, *sourceComment(d))
+		}
+	}
+	w.locations = append(w.locations, out)
+}
+
+// sourceComment formats a comment as protoc records it, each line without the
+// comment markers and ended by a new line.
+func sourceComment(c Comment) *string {
+	if len(c) == 0 {
+		return nil
+	}
+	return protobuf.String(Space + strings.ReplaceAll(string(c), CommentNewLine, EndL+Space) + EndL)
+}
+
+// addOptions collects the options of an element, set in the target once the
+// files are written.
+func (w *descriptorWriter) addOptions(target protobuf.Message, path []int32, scope string, element string, declaration Location, options []*Option) {
+	for _, o := range options {
+		value := o.Constant
+		if value == nil {
+			value = NewOptionValue(o.Value)
+		}
+		w.options = append(w.options, &descriptorOption{target: target, path: path, scope: scope, element: element,
+			name: o.Name, value: value, location: o.Location, comments: o.Comments, declaration: declaration})
+	}
+}
+
+// nestedMessage is a nested message, or the entry message of a map field,
+// ordered by its location.
+type nestedMessage struct {
+	location   Location
+	message    *Message
+	descriptor *descriptorpb.DescriptorProto
+}
+
+func (w *descriptorWriter) message(m *Message, path []int32) *descriptorpb.DescriptorProto {
+	out := &descriptorpb.DescriptorProto{Name: protobuf.String(m.Name)}
+	w.location(path, m.Location, m.Comments)
+
+	nested := make([]*nestedMessage, 0, len(m.Messages))
+	for _, n := range m.Messages {
+		nested = append(nested, &nestedMessage{location: n.Location, message: n})
+	}
+	oneofs := make(map[string]int32)
+	for i, o := range m.Oneofs {
+		out.OneofDecl = append(out.OneofDecl, &descriptorpb.OneofDescriptorProto{Name: protobuf.String(o.Name)})
+		oneofs[o.Name] = int32(i)
+		w.location(child(path, messageOneofsPath, int32(i)), o.Location, o.Comments)
+	}
+	synthetic := make([]*descriptorpb.FieldDescriptorProto, 0)
+	for i, a := range m.Attributes {
+		field := w.field(a, child(path, messageFieldsPath, int32(i)))
+		if index, ok := oneofs[a.Oneof]; ok && len(a.Oneof) > 0 {
+			field.OneofIndex = protobuf.Int32(index)
+		} else if field.GetProto3Optional() {
+			synthetic = append(synthetic, field)
+		}
+		if a.Map {
+			entry := w.mapEntry(a)
+			field.TypeName = protobuf.String(Period + Join(Period, m.Qualifier, entry.GetName()))
+			nested = append(nested, &nestedMessage{location: a.Location, descriptor: entry})
+		}
+		out.Field = append(out.Field, field)
+	}
+	// The synthetic oneofs of proto3 optional fields follow the declared ones
+	for _, field := range synthetic {
+		name := "_" + field.GetName()
+		for _, ok := oneofs[name]; ok; _, ok = oneofs[name] {
+			name = "X" + name
+		}
+		oneofs[name] = int32(len(out.OneofDecl))
+		field.OneofIndex = protobuf.Int32(oneofs[name])
+		out.OneofDecl = append(out.OneofDecl, &descriptorpb.OneofDescriptorProto{Name: protobuf.String(name)})
+	}
+
+	sort.SliceStable(nested, func(i, j int) bool {
+		a, b := nested[i].location, nested[j].location
+		return a.IsValid() && b.IsValid() && a.Start.Before(b.Start)
+	})
+	for i, n := range nested {
+		if n.descriptor == nil {
+			n.descriptor = w.message(n.message, child(path, messageMessagesPath, int32(i)))
+		}
+		out.NestedType = append(out.NestedType, n.descriptor)
+	}
+	for i, e := range m.Enums {
+		out.EnumType = append(out.EnumType, w.enum(e, child(path, messageEnumsPath, int32(i))))
+	}
+	for _, r := range m.Extensions {
+		out.ExtensionRange = append(out.ExtensionRange, &descriptorpb.DescriptorProto_ExtensionRange{
+			Start: protobuf.Int32(r.Start), End: protobuf.Int32(r.End + 1)})
+	}
+	out.Extension = w.extensions(m.Extends, child(path, messageExtensionsPath))
+	for _, r := range m.Reserved {
+		w.reserved(r, child(path, messageReservedRangesPath), len(out.ReservedRange), child(path, messageReservedNamesPath), len(out.ReservedName))
+		for _, rr := range r.Ranges {
+			out.ReservedRange = append(out.ReservedRange, &descriptorpb.DescriptorProto_ReservedRange{
+				Start: protobuf.Int32(rr.Start), End: protobuf.Int32(rr.End + 1)})
+		}
+		out.ReservedName = append(out.ReservedName, r.Names...)
+	}
+	if len(m.Options) > 0 {
+		out.Options = &descriptorpb.MessageOptions{}
+		w.addOptions(out.Options, child(path, messageOptionsPath), m.Qualifier, m.Qualifier, m.Location, m.Options)
+	}
+	return out
+}
+
+// mapEntry returns the entry message protoc declares for a map field.
+func (w *descriptorWriter) mapEntry(a *Attribute) *descriptorpb.DescriptorProto {
+	entry := func(name string, number int32, index int) *descriptorpb.FieldDescriptorProto {
+		out := &descriptorpb.FieldDescriptorProto{
+			Name:     protobuf.String(name),
+			Number:   protobuf.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			JsonName: protobuf.String(name),
+		}
+		var resolved *Symbol
+		if index < len(a.Resolved) {
+			resolved = a.Resolved[index]
+		}
+		out.Type, out.TypeName = fieldType(strings.TrimSpace(a.Kind[index]), resolved)
+		return out
+	}
+	name := strings.Builder{}
+	upper := true
+	for _, r := range a.Name {
+		switch {
+		case r == '_':
+			upper = true
+		case upper:
+			name.WriteRune(unicode.ToUpper(r))
+			upper = false
+		default:
+			name.WriteRune(r)
+		}
+	}
+	return &descriptorpb.DescriptorProto{
+		Name:    protobuf.String(name.String() + "Entry"),
+		Field:   []*descriptorpb.FieldDescriptorProto{entry("key", 1, 0), entry("value", 2, 1)},
+		Options: &descriptorpb.MessageOptions{MapEntry: protobuf.Bool(true)},
+	}
+}
+
+// fieldType returns the type of a field of the kind, message and enum types
+// are named by their fully-qualified name once resolved.
+func fieldType(kind string, resolved *Symbol) (*descriptorpb.FieldDescriptorProto_Type, *string) {
+	if t, ok := descriptorpb.FieldDescriptorProto_Type_value["TYPE_"+strings.ToUpper(kind)]; ok && IsScalarType(kind) {
+		return descriptorpb.FieldDescriptorProto_Type(t).Enum(), nil
+	}
+	switch {
+	case resolved == nil:
+		return nil, protobuf.String(kind)
+	case resolved.Kind == SymbolEnum:
+		return descriptorpb.FieldDescriptorProto_TYPE_ENUM.Enum(), protobuf.String(Period + resolved.Name)
+	default:
+		return descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), protobuf.String(Period + resolved.Name)
+	}
+}
+
+func (w *descriptorWriter) field(a *Attribute, path []int32) *descriptorpb.FieldDescriptorProto {
+	out := &descriptorpb.FieldDescriptorProto{
+		Name:     protobuf.String(a.Name),
+		Number:   protobuf.Int32(int32(a.Ordinal)),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		JsonName: protobuf.String(jsonName(a.Name)),
+	}
+	w.location(path, a.Location, a.Comments)
+	switch {
+	case a.Repeated || a.Map:
+		out.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	case a.Required:
+		out.Label = descriptorpb.FieldDescriptorProto_LABEL_REQUIRED.Enum()
+	case a.Optional && w.p.Syntax == SyntaxProto3 && len(w.p.Edition) == 0:
+		out.Proto3Optional = protobuf.Bool(true)
+	}
+	if a.Map {
+		out.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+	} else if len(a.Kind) > 0 {
+		var resolved *Symbol
+		if len(a.Resolved) > 0 {
+			resolved = a.Resolved[0]
+		}
+		out.Type, out.TypeName = fieldType(a.Kind[0], resolved)
+		if out.GetType() == descriptorpb.FieldDescriptorProto_TYPE_MESSAGE &&
+			(a.Group || a.Features != nil && a.Features.MessageEncoding == "DELIMITED") {
+			out.Type = descriptorpb.FieldDescriptorProto_TYPE_GROUP.Enum()
+		}
+	}
+
+	options := make([]*Option, 0)
+	for _, annotation := range a.Annotations {
+		value := annotation.Constant
+		if value == nil {
+			value = NewOptionValue(fmt.Sprint(annotation.Value))
+		}
+		switch annotation.Name {
+		case AnnotationDefault:
+			out.DefaultValue = protobuf.String(value.Value)
+		case "json_name":
+			out.JsonName = protobuf.String(value.Value)
+		default:
+			option := NewOption(annotation.Name, value.String(), Empty)
+			option.Constant = value
+			options = append(options, option)
+		}
+	}
+	if len(options) > 0 {
+		out.Options = &descriptorpb.FieldOptions{}
+		w.addOptions(out.Options, child(path, fieldOptionsPath), a.Qualifier, Join(Period, a.Qualifier, a.Name), a.Location, options)
+	}
+	return out
+}
+
+func (w *descriptorWriter) extensions(extensions []*Extension, path []int32) []*descriptorpb.FieldDescriptorProto {
+	out := make([]*descriptorpb.FieldDescriptorProto, 0)
+	for _, e := range extensions {
+		extendee := e.Extendee
+		if e.Resolved != nil {
+			extendee = Period + e.Resolved.Name
+		}
+		w.location(path, e.Location, e.Comments)
+		for _, a := range e.Attributes {
+			field := w.field(a, child(path, int32(len(out))))
+			field.Extendee = protobuf.String(extendee)
+			out = append(out, field)
+		}
+	}
+	return out
+}
+
+func (w *descriptorWriter) enum(e *Enum, path []int32) *descriptorpb.EnumDescriptorProto {
+	out := &descriptorpb.EnumDescriptorProto{Name: protobuf.String(e.Name)}
+	w.location(path, e.Location, e.Comments)
+	for i, v := range e.Values {
+		valuePath := child(path, enumValuesPath, int32(i))
+		value := &descriptorpb.EnumValueDescriptorProto{Name: protobuf.String(v.Value), Number: protobuf.Int32(int32(v.Ordinal))}
+		w.location(valuePath, v.Location, v.Comments)
+		if len(v.Options) > 0 {
+			value.Options = &descriptorpb.EnumValueOptions{}
+			w.addOptions(value.Options, child(valuePath, enumValueOptionsPath), e.Qualifier, Join(Period, e.Qualifier, v.Value), v.Location, v.Options)
+		}
+		out.Value = append(out.Value, value)
+	}
+	for _, r := range e.Reserved {
+		w.reserved(r, child(path, enumReservedRangesPath), len(out.ReservedRange), child(path, enumReservedNamesPath), len(out.ReservedName))
+		for _, rr := range r.Ranges {
+			out.ReservedRange = append(out.ReservedRange, &descriptorpb.EnumDescriptorProto_EnumReservedRange{
+				Start: protobuf.Int32(rr.Start), End: protobuf.Int32(rr.End)})
+		}
+		out.ReservedName = append(out.ReservedName, r.Names...)
+	}
+	if len(e.Options) > 0 {
+		out.Options = &descriptorpb.EnumOptions{}
+		w.addOptions(out.Options, child(path, enumOptionsPath), e.Qualifier, e.Qualifier, e.Location, e.Options)
+	}
+	return out
+}
+
+// reserved records the location of a reserved statement at the path of its
+// ranges or names, and the location of each of them from the index of the
+// first.
+func (w *descriptorWriter) reserved(r *Reserved, rangesPath []int32, ranges int, namesPath []int32, names int) {
+	if len(r.Ranges) > 0 {
+		w.location(rangesPath, r.Location, r.Comments)
+	}
+	for i := range r.Ranges {
+		w.location(child(rangesPath, int32(ranges+i)), r.Location, nil)
+	}
+	if len(r.Names) > 0 {
+		w.location(namesPath, r.Location, r.Comments)
+	}
+	for i := range r.Names {
+		w.location(child(namesPath, int32(names+i)), r.Location, nil)
+	}
+}
+
+func (w *descriptorWriter) service(s *Service, path []int32) *descriptorpb.ServiceDescriptorProto {
+	out := &descriptorpb.ServiceDescriptorProto{Name: protobuf.String(s.Name)}
+	qualifier := Join(Period, s.Qualifier, s.Name)
+	w.location(path, s.Location, s.Comments)
+	for i, rpc := range s.Methods {
+		methodPath := child(path, serviceMethodsPath, int32(i))
+		method := &descriptorpb.MethodDescriptorProto{Name: protobuf.String(rpc.Name)}
+		w.location(methodPath, rpc.Location, rpc.Comments)
+		if len(rpc.InputParameters) > 0 {
+			method.InputType = parameterType(rpc.InputParameters[0])
+			if rpc.InputParameters[0].Stream {
+				method.ClientStreaming = protobuf.Bool(true)
+			}
+		}
+		if len(rpc.ReturnParameters) > 0 {
+			method.OutputType = parameterType(rpc.ReturnParameters[0])
+			if rpc.ReturnParameters[0].Stream {
+				method.ServerStreaming = protobuf.Bool(true)
+			}
+		}
+		if len(rpc.Options) > 0 {
+			method.Options = &descriptorpb.MethodOptions{}
+			options := make([]*Option, 0, len(rpc.Options))
+			for _, o := range rpc.Options {
+				option := NewOption(o.Name, o.Body, o.Comment)
+				option.Location = o.Location
+				option.Comments = o.Comments
+				if o.Constant != nil {
+					option.Constant = o.Constant
+				}
+				options = append(options, option)
+			}
+			w.addOptions(method.Options, child(methodPath, methodOptionsPath), qualifier, Join(Period, qualifier, rpc.Name), rpc.Location, options)
+		}
+		out.Method = append(out.Method, method)
+	}
+	if len(s.Options) > 0 {
+		out.Options = &descriptorpb.ServiceOptions{}
+		w.addOptions(out.Options, child(path, serviceOptionsPath), qualifier, qualifier, s.Location, s.Options)
+	}
+	return out
+}
+
+func parameterType(p *Parameter) *string {
+	if p.Resolved != nil {
+		return protobuf.String(Period + p.Resolved.Name)
+	}
+	return protobuf.String(p.Type)
+}
+
+// setOption sets an option, e.g. `deprecated`, `features.field_presence` or
+// `(google.api.http).get`, in the options message and returns the number of
+// the field of the options message it is set in.
+func setOption(m protoreflect.Message, o *descriptorOption, types *protoregistry.Types) (protoreflect.FieldNumber, error) {
+	segments, err := optionNameSegments(o.name)
+	if err != nil {
+		return 0, err
+	}
+	var number protoreflect.FieldNumber
+	for i, segment := range segments {
+		var fd protoreflect.FieldDescriptor
+		if strings.HasPrefix(segment, "(") {
+			xt := findExtension(types, o.scope, strings.Trim(segment, "()"), m.Descriptor().FullName())
+			if xt == nil {
+				return 0, fmt.Errorf("unknown extension %s of %s", segment, m.Descriptor().FullName())
+			}
+			fd = xt.TypeDescriptor()
+		} else if fd = m.Descriptor().Fields().ByName(protoreflect.Name(segment)); fd == nil {
+			return 0, fmt.Errorf("unknown field %s of %s", segment, m.Descriptor().FullName())
+		}
+		if i == 0 {
+			number = fd.Number()
+		}
+		if i == len(segments)-1 {
+			return number, setOptionField(m, fd, o.value, types)
+		}
+		if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+			return 0, fmt.Errorf("%s is not a message field", segment)
+		}
+		m = m.Mutable(fd).Message()
+	}
+	return number, nil
+}
+
+// optionNameSegments splits an option name into its fields, extensions keep
+// their parentheses.
+func optionNameSegments(name string) ([]string, error) {
+	out := make([]string, 0)
+	for len(name) > 0 {
+		end := strings.Index(name, Period)
+		if strings.HasPrefix(name, "(") {
+			end = strings.Index(name, ")") + 1
+			if end == 0 {
+				return nil, fmt.Errorf("missing `)`")
+			}
+		}
+		if end < 0 {
+			end = len(name)
+		}
+		out = append(out, strings.TrimSpace(name[:end]))
+		name = strings.TrimPrefix(name[end:], Period)
+	}
+	return out, nil
+}
+
+// findExtension resolves the name of an extension of the message from the
+// scope, searching from the innermost scope outwards.
+func findExtension(types *protoregistry.Types, scope string, name string, extendee protoreflect.FullName) protoreflect.ExtensionType {
+	if types == nil {
+		return nil
+	}
+	if strings.HasPrefix(name, Period) {
+		scope = Empty
+	}
+	name = QualifiedName(name)
+	for {
+		xt, err := types.FindExtensionByName(protoreflect.FullName(qualify(scope, name)))
+		if err == nil && xt.TypeDescriptor().ContainingMessage().FullName() == extendee {
+			return xt
+		}
+		if len(scope) == 0 {
+			return nil
+		}
+		scope = scope[:max(strings.LastIndex(scope, Period), 0)]
+	}
+}
+
+// setOptionField sets a field to the value, the values of a list are appended
+// to repeated fields.
+func setOptionField(m protoreflect.Message, fd protoreflect.FieldDescriptor, value *OptionValue, types *protoregistry.Types) error {
+	switch {
+	case fd.IsMap():
+		values := []*OptionValue{value}
+		if value.Kind == OptionValueList {
+			values = value.List
+		}
+		for _, entry := range values {
+			key, err := scalarOptionValue(fd.MapKey(), entry.Get("key"))
+			if err != nil {
+				return err
+			}
+			v := m.Mutable(fd).Map().NewValue()
+			if fd.MapValue().Message() != nil {
+				err = setOptionMessage(v.Message(), entry.Get("value"), types)
+			} else {
+				v, err = scalarOptionValue(fd.MapValue(), entry.Get("value"))
+			}
+			if err != nil {
+				return err
+			}
+			m.Mutable(fd).Map().Set(key.MapKey(), v)
+		}
+	case fd.IsList():
+		values := []*OptionValue{value}
+		if value.Kind == OptionValueList {
+			values = value.List
+		}
+		list := m.Mutable(fd).List()
+		for _, v := range values {
+			element, err := newOptionValue(fd, v, list.NewElement, types)
+			if err != nil {
+				return err
+			}
+			list.Append(element)
+		}
+	case fd.Message() != nil:
+		return setOptionMessage(m.Mutable(fd).Message(), value, types)
+	default:
+		v, err := scalarOptionValue(fd, value)
+		if err != nil {
+			return err
+		}
+		m.Set(fd, v)
+	}
+	return nil
+}
+
+func newOptionValue(fd protoreflect.FieldDescriptor, value *OptionValue, newElement func() protoreflect.Value, types *protoregistry.Types) (protoreflect.Value, error) {
+	if fd.Message() == nil {
+		return scalarOptionValue(fd, value)
+	}
+	out := newElement()
+	return out, setOptionMessage(out.Message(), value, types)
+}
+
+// setOptionMessage sets the fields of an aggregate value in the message.
+func setOptionMessage(m protoreflect.Message, value *OptionValue, types *protoregistry.Types) error {
+	if value == nil || value.Kind != OptionValueMessage {
+		return fmt.Errorf("expected a message value for %s, got `%s`", m.Descriptor().FullName(), value)
+	}
+	for _, f := range value.Fields {
+		var fd protoreflect.FieldDescriptor
+		if strings.HasPrefix(f.Name, OpenBracket) {
+			if xt := findExtension(types, Empty, strings.Trim(f.Name, "[]"), m.Descriptor().FullName()); xt != nil {
+				fd = xt.TypeDescriptor()
+			}
+		} else if fd = m.Descriptor().Fields().ByTextName(f.Name); fd == nil {
+			fd = m.Descriptor().Fields().ByName(protoreflect.Name(f.Name))
+		}
+		if fd == nil {
+			return fmt.Errorf("unknown field %s of %s", f.Name, m.Descriptor().FullName())
+		}
+		if err := setOptionField(m, fd, f.Value, types); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scalarOptionValue converts a scalar or identifier to the kind of the field.
+func scalarOptionValue(fd protoreflect.FieldDescriptor, value *OptionValue) (protoreflect.Value, error) {
+	if value == nil || value.Kind == OptionValueList || value.Kind == OptionValueMessage {
+		return protoreflect.Value{}, fmt.Errorf("expected a %s value for %s, got `%s`", fd.Kind(), fd.Name(), value)
+	}
+	text := value.Value
+	var err error
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		var v bool
+		if v, err = strconv.ParseBool(text); err == nil {
+			return protoreflect.ValueOfBool(v), nil
+		}
+	case protoreflect.EnumKind:
+		if v := fd.Enum().Values().ByName(protoreflect.Name(text)); v != nil {
+			return protoreflect.ValueOfEnum(v.Number()), nil
+		}
+		var v int64
+		if v, err = strconv.ParseInt(text, 0, 32); err == nil {
+			return protoreflect.ValueOfEnum(protoreflect.EnumNumber(v)), nil
+		}
+		err = fmt.Errorf("unknown value %s of %s", text, fd.Enum().FullName())
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		var v int64
+		if v, err = strconv.ParseInt(text, 0, 32); err == nil {
+			return protoreflect.ValueOfInt32(int32(v)), nil
+		}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		var v int64
+		if v, err = strconv.ParseInt(text, 0, 64); err == nil {
+			return protoreflect.ValueOfInt64(v), nil
+		}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		var v uint64
+		if v, err = strconv.ParseUint(text, 0, 32); err == nil {
+			return protoreflect.ValueOfUint32(uint32(v)), nil
+		}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		var v uint64
+		if v, err = strconv.ParseUint(text, 0, 64); err == nil {
+			return protoreflect.ValueOfUint64(v), nil
+		}
+	case protoreflect.FloatKind:
+		var v float64
+		if v, err = strconv.ParseFloat(text, 32); err == nil {
+			return protoreflect.ValueOfFloat32(float32(v)), nil
+		}
+	case protoreflect.DoubleKind:
+		var v float64
+		if v, err = strconv.ParseFloat(text, 64); err == nil {
+			return protoreflect.ValueOfFloat64(v), nil
+		}
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(text), nil
+	case protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes([]byte(text)), nil
+	}
+	return protoreflect.Value{}, fmt.Errorf("invalid %s value `%s`: %w", fd.Kind(), text, err)
+}
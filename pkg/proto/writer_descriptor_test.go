@@ -0,0 +1,133 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protojson"
+	protobuf "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestPackagesToDescriptorSet(t *testing.T) {
+	p := NewPackage("data/test/descriptor/library.proto")
+	p.Read(false)
+	assert.Empty(t, Link(p))
+	set, diagnostics := PackagesToDescriptorSet([]*Package{p}, map[*Package]string{p: "library.proto"})
+	assert.Empty(t, diagnostics)
+
+	// The descriptors are the ones protoc writes for the file
+	want := readTestDescriptorSet(t)
+	for _, file := range append(want.File, set.File...) {
+		file.SourceCodeInfo = nil
+	}
+	assert.True(t, protobuf.Equal(want, set), "%v", set)
+}
+
+func TestPackagesToDescriptorSet_RoundTrip(t *testing.T) {
+	// The packages read back from the written descriptor set have the markdown
+	// of the sources
+	for _, path := range []string{"proto2/search.proto", "editions/inventory.proto", "oneof/payment.proto", "location/model.proto", "descriptor/library.proto"} {
+		t.Run(path, func(t *testing.T) {
+			p := NewPackage(filepath.Join("data/test", path))
+			p.Read(false)
+			imported, _ := NewImporter("data/test").Load(p)
+			packages := append([]*Package{p}, imported...)
+			Link(packages...)
+			set, diagnostics := PackagesToDescriptorSet(packages, map[*Package]string{p: path})
+			assert.Empty(t, diagnostics)
+
+			read := DescriptorSetToPackages(set)
+			MarkBundled(read...)
+			Link(read...)
+			q := read[len(read)-1]
+			assert.Equal(t, path, q.Path)
+			// Block comments of several lines are read as one line
+			p.Comment, q.Comment, p.Comments, q.Comments = Empty, Empty, nil, nil
+			config := &WriterConfig{visualize: true, comments: CommentsAttached}
+			assert.Equal(t, PackageToMarkDown(p, config), PackageToMarkDown(q, config))
+		})
+	}
+}
+
+func TestPackagesToDescriptorSet_Options(t *testing.T) {
+	p, err := ParseString("a.proto", `syntax = "proto3";
+package test;
+import "google/protobuf/descriptor.proto";
+
+extend google.protobuf.FieldOptions {
+  bool sensitive = 50000;
+}
+
+message A {
+  // The secret
+  string secret = 1 [(sensitive) = true, deprecated = true];
+  string other = 2 [(unknown) = 1];
+}`)
+	assert.Nil(t, err)
+	imported, diagnostics := NewImporter().Load(p)
+	assert.Empty(t, diagnostics)
+	packages := append([]*Package{p}, imported...)
+	Link(packages...)
+
+	set, diagnostics := PackagesToDescriptorSet(packages, nil)
+	assert.Equal(t, "a.proto:12:3: warning: option `(unknown)` is not written: unknown extension (unknown) of google.protobuf.FieldOptions (test.A.other)", diagnostics.String())
+	assert.Equal(t, "google/protobuf/descriptor.proto", set.File[0].GetName())
+	file := set.File[1]
+	assert.Equal(t, []string{"google/protobuf/descriptor.proto"}, file.Dependency)
+	secret := file.MessageType[0].Field[0]
+	assert.Equal(t, "secret", secret.GetJsonName())
+	assert.True(t, secret.GetOptions().GetDeprecated())
+
+	a := DescriptorSetToPackages(set)[1].Messages[0]
+	assert.Equal(t, Comment("The secret"), a.Attributes[0].Comment)
+	// Annotations have no location, they are read in field number order
+	assert.Len(t, a.Attributes[0].Annotations, 2)
+	assert.Equal(t, "deprecated", a.Attributes[0].Annotations[0].Name)
+	assert.Equal(t, "(test.sensitive)", a.Attributes[0].Annotations[1].Name)
+	assert.Equal(t, "true", a.Attributes[0].Annotations[1].Value)
+	assert.Empty(t, a.Attributes[1].Annotations)
+}
+
+func TestParseDescriptorFormat(t *testing.T) {
+	format, err := ParseDescriptorFormat("json")
+	assert.Nil(t, err)
+	assert.Equal(t, DescriptorJSON, format)
+	format, err = ParseDescriptorFormat("binary")
+	assert.Nil(t, err)
+	assert.Equal(t, DescriptorBinary, format)
+	_, err = ParseDescriptorFormat("text")
+	assert.EqualError(t, err, `unknown descriptor format "text", expected binary or json`)
+}
+
+func TestMarshalDescriptorSet(t *testing.T) {
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{Name: protobuf.String("a.proto")}}}
+	for _, format := range []DescriptorFormat{DescriptorBinary, DescriptorJSON} {
+		data, err := MarshalDescriptorSet(set, format)
+		assert.Nil(t, err)
+		read := &descriptorpb.FileDescriptorSet{}
+		if format == DescriptorJSON {
+			assert.Nil(t, protojson.Unmarshal(data, read))
+		} else {
+			assert.Nil(t, protobuf.Unmarshal(data, read))
+		}
+		assert.True(t, protobuf.Equal(set, read))
+	}
+}
@@ -0,0 +1,223 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DotTreeFile is the name of the DOT graph of all the files written by the
+// -dot_out flag.
+const DotTreeFile = "tree.dot"
+
+// The styles of the DOT edges, the composition of a message by a singular
+// field, a repeated field or a map value, and the request and response of an
+// rpc, plain or streamed.
+const (
+	DotEdgeComposition = `dir=back, arrowtail=diamond`
+	DotEdgeRepeated    = `dir=back, arrowtail=diamond, headlabel="*"`
+	DotEdgeMap         = `dir=back, arrowtail=odiamond, style=dashed`
+	DotEdgeRpc         = `arrowhead=vee`
+	DotEdgeStream      = `arrowhead=vee, style=bold, color=blue`
+)
+
+// PackageToDot formats the declarations of a file into a Graphviz DOT graph.
+func PackageToDot(p *Package, wc *WriterConfig) string {
+	return PackagesToDot(p.Path, []*Package{p}, wc)
+}
+
+// PackagesToDot formats the declarations of files into a Graphviz DOT graph,
+// with record nodes for the messages, enums and services, a cluster for each
+// proto package, and a cluster for each message containing nested messages or
+// enums. The relationships to other types are edges styled by the kind of the
+// relationship, see DotEdgeComposition.
+func PackagesToDot(name string, packages []*Package, wc *WriterConfig) string {
+	g := &dotGraph{wc: wc, externals: make(map[string]bool)}
+	byName := make(map[string][]*Package)
+	names := make([]string, 0)
+	for _, p := range packages {
+		if _, ok := byName[p.Name]; !ok {
+			names = append(names, p.Name)
+		}
+		byName[p.Name] = append(byName[p.Name], p)
+	}
+	sort.Strings(names)
+
+	out := fmt.Sprintf("digraph %s {\n  rankdir=LR;\n  node [shape=record, fontsize=10];\n  edge [fontsize=9];\n", dotID(name))
+	for _, n := range names {
+		body := ""
+		indent := "  "
+		if len(n) > 0 {
+			indent += "  "
+		}
+		for _, p := range byName[n] {
+			for _, m := range p.Messages {
+				body += g.message(m, indent)
+			}
+			for _, e := range p.Enums {
+				body += g.enum(e, indent)
+			}
+			for _, s := range p.Services {
+				body += g.service(s, indent)
+			}
+		}
+		if len(n) == 0 {
+			out += body
+		} else {
+			out += fmt.Sprintf("  subgraph %s {\n    label=%s;\n%s  }\n", dotID("cluster_"+n), dotID(n), body)
+		}
+	}
+	return out + g.nodes + g.edges + "}\n"
+}
+
+// dotGraph collects the external nodes and the edges of a graph, they are
+// written after the clusters to keep the nodes in their clusters.
+type dotGraph struct {
+	wc        *WriterConfig
+	externals map[string]bool
+	nodes     string
+	edges     string
+}
+
+func (g *dotGraph) message(m *Message, indent string) string {
+	name := QualifiedName(m.Qualifier)
+	fields := make([]string, 0, len(m.Attributes))
+	for _, a := range m.Attributes {
+		fields = append(fields, fmt.Sprintf("%s : %s = %d", a.Name, dotAttributeType(a), a.Ordinal))
+		if a.Map {
+			g.edge(name, a.Kind[1], a.ResolvedKind(1), DotEdgeMap, a.Name)
+		} else if a.Repeated {
+			g.edge(name, a.Kind[0], a.ResolvedKind(0), DotEdgeRepeated, a.Name)
+		} else {
+			g.edge(name, a.Kind[0], a.ResolvedKind(0), DotEdgeComposition, a.Name)
+		}
+	}
+	node := fmt.Sprintf("%s [label=\"{%s|%s}\"];\n", dotID(name), dotEscape(m.Name), dotLines(fields))
+	if len(m.Messages) == 0 && len(m.Enums) == 0 {
+		return indent + node
+	}
+	inner := indent + "  "
+	out := fmt.Sprintf("%ssubgraph %s {\n%slabel=%s;\n%sstyle=dashed;\n%s%s",
+		indent, dotID("cluster_"+name), inner, dotID(m.Name), inner, inner, node)
+	for _, nested := range m.Messages {
+		out += g.message(nested, inner)
+	}
+	for _, e := range m.Enums {
+		out += g.enum(e, inner)
+	}
+	return out + indent + "}\n"
+}
+
+func (g *dotGraph) enum(e *Enum, indent string) string {
+	values := make([]string, 0, len(e.Values))
+	for _, v := range e.Values {
+		values = append(values, fmt.Sprintf("%s = %d", v.Value, v.Ordinal))
+	}
+	return fmt.Sprintf("%s%s [label=\"{%s\\n%s|%s}\"];\n", indent, dotID(QualifiedName(e.Qualifier)),
+		dotEscape("<<enumeration>>"), dotEscape(e.Name), dotLines(values))
+}
+
+func (g *dotGraph) service(s *Service, indent string) string {
+	name := QualifiedName(Join(Period, s.Qualifier, s.Name))
+	methods := make([]string, 0, len(s.Methods))
+	for _, rpc := range s.Methods {
+		methods = append(methods, fmt.Sprintf("%s(%s) : %s", rpc.Name,
+			dotParameters(rpc.InputParameters), dotParameters(rpc.ReturnParameters)))
+		for _, p := range append(append(make([]*Parameter, 0), rpc.InputParameters...), rpc.ReturnParameters...) {
+			if p.Stream {
+				g.edge(name, p.Type, p.Resolved, DotEdgeStream, rpc.Name)
+			} else {
+				g.edge(name, p.Type, p.Resolved, DotEdgeRpc, rpc.Name)
+			}
+		}
+	}
+	return fmt.Sprintf("%s%s [label=\"{%s\\n%s|%s}\"];\n", indent, dotID(name),
+		dotEscape("<<service>>"), dotEscape(s.Name), dotLines(methods))
+}
+
+// edge adds an edge from a node to a type, the edges to scalar types are
+// omitted, and the edges to bundled types follow the external style of the
+// configuration.
+func (g *dotGraph) edge(from string, kind string, resolved *Symbol, style string, label string) {
+	if IsScalarType(kind) {
+		return
+	}
+	to := QualifiedName(kind)
+	if resolved != nil {
+		to = resolved.Name
+	}
+	if IsExternal(resolved) {
+		if g.wc.external != ExternalNodes {
+			return
+		}
+		if !g.externals[to] {
+			g.externals[to] = true
+			g.nodes += fmt.Sprintf("  %s [label=\"{%s\\n%s}\", style=dashed];\n", dotID(to), dotEscape("<<external>>"), dotEscape(to))
+		}
+	}
+	g.edges += fmt.Sprintf("  %s -> %s [%s, label=%s];\n", dotID(from), dotID(to), style, dotID(label))
+}
+
+// dotAttributeType formats the type of a field with its label, e.g.
+// `repeated string` or `map<string, Book>`.
+func dotAttributeType(a *Attribute) string {
+	switch {
+	case a.Map:
+		return fmt.Sprintf("map<%s, %s>", strings.TrimSpace(a.Kind[0]), strings.TrimSpace(a.Kind[1]))
+	case a.Repeated:
+		return PrefixRepeated + Space + a.Kind[0]
+	case a.Optional:
+		return PrefixOptional + Space + a.Kind[0]
+	case a.Required:
+		return PrefixRequired + Space + a.Kind[0]
+	}
+	return a.Kind[0]
+}
+
+// dotParameters formats the parameters of an rpc, e.g. `stream Book`.
+func dotParameters(in []*Parameter) string {
+	out := make([]string, 0, len(in))
+	for _, p := range in {
+		if p.Stream {
+			out = append(out, "stream "+strings.TrimSpace(p.Type))
+		} else {
+			out = append(out, strings.TrimSpace(p.Type))
+		}
+	}
+	return strings.Join(out, Comma+Space)
+}
+
+// dotLines formats the lines of a record field, left aligned.
+func dotLines(lines []string) string {
+	out := ""
+	for _, l := range lines {
+		out += dotEscape(l) + `\l`
+	}
+	return out
+}
+
+// dotEscape escapes the characters of a record label.
+func dotEscape(in string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "{", `\{`, "}", `\}`, "|", `\|`, "<", `\<`, ">", `\>`).Replace(in)
+}
+
+// dotID quotes an identifier of a DOT graph.
+func dotID(in string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(in) + `"`
+}
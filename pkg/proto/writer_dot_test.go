@@ -0,0 +1,122 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageToDot(t *testing.T) {
+	p, err := ParseString("a.proto", `syntax = "proto3";
+package test.library;
+
+import "google/protobuf/timestamp.proto";
+
+message Book {
+  message Page {
+    string text = 1;
+  }
+  repeated Page pages = 1;
+  map<string, Book> related = 2;
+  google.protobuf.Timestamp published = 3;
+}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+}
+
+service Library {
+  rpc Read(Book) returns (stream Book.Page);
+}`)
+	assert.Nil(t, err)
+	Link(p)
+	timestamp := &Symbol{Name: "google.protobuf.Timestamp", Kind: SymbolMessage, Package: &Package{Bundled: true}}
+	p.Messages[0].Attributes[2].Resolved = []*Symbol{timestamp}
+
+	assert.Equal(t, `digraph "a.proto" {
+  rankdir=LR;
+  node [shape=record, fontsize=10];
+  edge [fontsize=9];
+  subgraph "cluster_test.library" {
+    label="test.library";
+    subgraph "cluster_test.library.Book" {
+      label="Book";
+      style=dashed;
+      "test.library.Book" [label="{Book|pages : repeated Page = 1\lrelated : map\<string, Book\> = 2\lpublished : google.protobuf.Timestamp = 3\l}"];
+      "test.library.Book.Page" [label="{Page|text : string = 1\l}"];
+    }
+    "test.library.Status" [label="{\<\<enumeration\>\>\nStatus|STATUS_UNSPECIFIED = 0\l}"];
+    "test.library.Library" [label="{\<\<service\>\>\nLibrary|Read(Book) : stream Book.Page\l}"];
+  }
+  "google.protobuf.Timestamp" [label="{\<\<external\>\>\ngoogle.protobuf.Timestamp}", style=dashed];
+  "test.library.Book" -> "test.library.Book.Page" [dir=back, arrowtail=diamond, headlabel="*", label="pages"];
+  "test.library.Book" -> "test.library.Book" [dir=back, arrowtail=odiamond, style=dashed, label="related"];
+  "test.library.Book" -> "google.protobuf.Timestamp" [dir=back, arrowtail=diamond, label="published"];
+  "test.library.Library" -> "test.library.Book" [arrowhead=vee, label="Read"];
+  "test.library.Library" -> "test.library.Book.Page" [arrowhead=vee, style=bold, color=blue, label="Read"];
+}
+`, PackageToDot(p, &WriterConfig{}))
+
+	assert.NotContains(t, PackageToDot(p, &WriterConfig{external: ExternalHidden}), "google.protobuf.Timestamp\"")
+}
+
+func TestPackagesToDot(t *testing.T) {
+	a, err := ParseString("a.proto", "package a;\nmessage A {}")
+	assert.Nil(t, err)
+	b, err := ParseString("b.proto", "package a;\nenum B { B_UNSPECIFIED = 0; }")
+	assert.Nil(t, err)
+	c, err := ParseString("c.proto", "message C {}")
+	assert.Nil(t, err)
+
+	// The files of a package share its cluster, declarations without a
+	// package are not clustered
+	assert.Equal(t, `digraph "tree" {
+  rankdir=LR;
+  node [shape=record, fontsize=10];
+  edge [fontsize=9];
+  "C" [label="{C|}"];
+  subgraph "cluster_a" {
+    label="a";
+    "a.A" [label="{A|}"];
+    "a.B" [label="{\<\<enumeration\>\>\nB|B_UNSPECIFIED = 0\l}"];
+  }
+}
+`, PackagesToDot("tree", []*Package{a, b, c}, &WriterConfig{}))
+}
+
+func TestWriteDotFiles(t *testing.T) {
+	p, err := ParseString("a.proto", "package a;\nmessage A {}")
+	assert.Nil(t, err)
+	directory := t.TempDir()
+	assert.Nil(t, writeDotFiles(directory, []*Package{p}, map[*Package]string{p: filepath.Join("a", "a.proto")}, &WriterConfig{}))
+
+	data, err := os.ReadFile(filepath.Join(directory, "a", "a.proto.dot"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "digraph \"a/a.proto\" {\n")
+	data, err = os.ReadFile(filepath.Join(directory, DotTreeFile))
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "digraph \"tree\" {\n")
+}
+
+func TestDotEscape(t *testing.T) {
+	assert.Equal(t, `map\<string, \{a\|b\}\>`, dotEscape("map<string, {a|b}>"))
+	assert.Equal(t, `"a\"b"`, dotID(`a"b`))
+}
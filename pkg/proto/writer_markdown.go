@@ -18,26 +18,79 @@ package proto
 
 import (
 	"fmt"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
 )
 
 const (
-	mermaidClassDiagramTemplate = "### %s Diagram\n\n```mermaid\nclassDiagram\ndirection LR\n%s\n```"
+	mermaidClassDiagramTemplate  = "### %s Diagram\n\n```mermaid\nclassDiagram\ndirection LR\n%s\n```"
+	plantUMLClassDiagramTemplate = "### %s Diagram\n\n```plantuml\n@startuml\nset separator none\nleft to right direction\n%s\n@enduml\n```"
 )
 
-func ToMermaid(title string, rt interface{}) string {
+// DiagramStyle selects the syntax of the diagrams embedded in the markdown.
+type DiagramStyle int
+
+const (
+	// DiagramMermaid embeds Mermaid class diagrams.
+	DiagramMermaid DiagramStyle = iota
+	// DiagramPlantUML embeds PlantUML class diagrams.
+	DiagramPlantUML
+)
+
+// DiagramStyleNames are the names of the diagram styles, used by the -diagram
+// flag.
+var DiagramStyleNames = map[string]DiagramStyle{
+	"mermaid":  DiagramMermaid,
+	"plantuml": DiagramPlantUML,
+}
+
+// ParseDiagramStyle reads a diagram style from its name.
+func ParseDiagramStyle(in string) (DiagramStyle, error) {
+	if style, ok := DiagramStyleNames[in]; ok {
+		return style, nil
+	}
+	return DiagramMermaid, fmt.Errorf("unknown diagram style %q, expected mermaid or plantuml", in)
+}
+
+// ToDiagram formats a package, enum, message or service into a diagram with
+// the style of the configuration.
+func ToDiagram(title string, rt interface{}, wc *WriterConfig) string {
+	if wc.diagram == DiagramPlantUML {
+		return ToPlantUML(title, rt, wc)
+	}
+	return ToMermaid(title, rt, wc)
+}
+
+// ToPlantUML formats a package, enum, message or service into a PlantUML
+// diagram section.
+func ToPlantUML(title string, rt interface{}, wc *WriterConfig) string {
 	out := ""
 	switch t := rt.(type) {
 	case *Package:
-		out += PackageToMermaid(t)
+		out += PackageToPlantUML(t, wc)
+	case *Enum:
+		out += EnumToPlantUML(t)
+	case *Message:
+		out += MessageToPlantUML(t, wc)
+	case *Service:
+		out += ServiceToPlantUML(t, wc)
+	}
+	return fmt.Sprintf(plantUMLClassDiagramTemplate, title, out)
+}
+
+func ToMermaid(title string, rt interface{}, wc *WriterConfig) string {
+	out := ""
+	switch t := rt.(type) {
+	case *Package:
+		out += PackageToMermaid(t, wc)
 	case *Enum:
 		out += EnumToMermaid(t)
 	case *Message:
-		out += MessageToMermaid(t)
+		out += MessageToMermaid(t, wc)
 	case *Service:
-		out += ServiceToMermaid(t)
+		out += ServiceToMermaid(t, wc)
 	}
 	return fmt.Sprintf(mermaidClassDiagramTemplate, title, out)
 }
@@ -45,63 +98,222 @@ func ToMermaid(title string, rt interface{}) string {
 type WriterConfig struct {
 	visualize    bool
 	pureMarkdown bool
+	// comments selects the attached comments rendered for each element
+	comments CommentStyle
+	// outputs are the markdown files written for the packages, imports of the
+	// packages are linked to them.
+	outputs map[*Package]string
+	// external selects how the types of bundled packages are rendered
+	external ExternalStyle
+	// diagram selects the syntax of the diagrams
+	diagram DiagramStyle
+	// current is the package being written, the types declared in the other
+	// written packages are linked to them.
+	current *Package
+}
+
+// Comment returns the comment of an element rendered with the comment style of
+// the configuration, elements without attached comments keep their comment.
+func (wc *WriterConfig) Comment(comment Comment, comments *Comments) Comment {
+	if wc.comments == CommentsAll || comments == nil {
+		return comment
+	}
+	return comments.Render(wc.comments)
+}
+
+// FormatReserved formats the reserved statements of a message or enum.
+func FormatReserved(name string, reserved []*Reserved, wc *WriterConfig) (body string) {
+	reservedTable := NewMarkdownTable()
+	reservedTable.AddHeader("Reserved", "Description")
+	for _, r := range reserved {
+		if wc.pureMarkdown {
+			reservedTable.Insert(fmt.Sprintf("`%s`", r.String()), wc.Comment(r.Comment, r.Comments).ToMarkdownText(false))
+		} else {
+			reservedTable.Insert(r.String(), wc.Comment(r.Comment, r.Comments).ToMarkdownText(false))
+		}
+	}
+	return fmt.Sprintf("### %s Reserved\n\n%s\n", name, reservedTable.String())
+}
+
+// FormatOptions formats the options declared in the body of a message, enum or service.
+func FormatOptions(name string, options []*Option, wc *WriterConfig) (body string) {
+	optionTable := NewMarkdownTable()
+	optionTable.AddHeader("Option", "Value", "Description")
+	for _, o := range options {
+		if wc.pureMarkdown {
+			optionTable.Insert(fmt.Sprintf("`%s`", o.Name), fmt.Sprintf("`%s`", o.Constant.String()), wc.Comment(o.Comment, o.Comments).ToMarkdownText(false))
+		} else {
+			optionTable.Insert(o.Name, o.Value, wc.Comment(o.Comment, o.Comments).ToMarkdownText(false))
+		}
+	}
+	return fmt.Sprintf("### %s Options\n\n%s\n", name, optionTable.String())
+}
+
+// FormatCompactOptions formats the bracketed options of an enum value, e.g.
+// `deprecated = true, (my.option) = "a"`.
+func FormatCompactOptions(options []*Option) string {
+	out := make([]string, 0, len(options))
+	for _, o := range options {
+		out = append(out, Join(" = ", o.Name, o.Constant.String()))
+	}
+	return strings.Join(out, Comma+Space)
+}
+
+// EnumHasValueOptions returns true if any enum value declares an option.
+func EnumHasValueOptions(enum *Enum) bool {
+	for _, v := range enum.Values {
+		if len(v.Options) > 0 {
+			return true
+		}
+	}
+	return false
 }
 
 func EnumToMarkdown(enum *Enum, wc *WriterConfig) (body string, diagram string) {
+	hasOptions := EnumHasValueOptions(enum)
 	enumTable := NewMarkdownTable()
-	enumTable.AddHeader("Name", "Ordinal", "Description")
+	if hasOptions {
+		enumTable.AddHeader("Name", "Ordinal", "Options", "Description")
+	} else {
+		enumTable.AddHeader("Name", "Ordinal", "Description")
+	}
 	for _, v := range enum.Values {
+		row := make([]string, 0)
 		if wc.pureMarkdown {
-			enumTable.Insert(fmt.Sprintf("`%s`", v.Value), strconv.Itoa(v.Ordinal), v.Comment.ToMarkdownText(false))
+			row = append(row, fmt.Sprintf("`%s`", v.Value), strconv.Itoa(v.Ordinal))
 		} else {
-			enumTable.Insert(v.Value, strconv.Itoa(v.Ordinal), v.Comment.ToMarkdownText(false))
+			row = append(row, v.Value, strconv.Itoa(v.Ordinal))
+		}
+		if hasOptions {
+			if wc.pureMarkdown && len(v.Options) > 0 {
+				row = append(row, fmt.Sprintf("`%s`", FormatCompactOptions(v.Options)))
+			} else {
+				row = append(row, FormatCompactOptions(v.Options))
+			}
 		}
+		enumTable.Insert(append(row, wc.Comment(v.Comment, v.Comments).ToMarkdownText(false))...)
 	}
 
 	// Convert to a string
 	if wc.visualize {
-		diagram = "\n" + ToMermaid(enum.Name, enum)
+		diagram = "\n" + ToDiagram(enum.Name, enum, wc)
 	}
 	if wc.pureMarkdown {
-		body = fmt.Sprintf("## Enum: %s\n\n%s\n\n%s\n\n%s\n\n", enum.Name, fmt.Sprintf(fqnPureMd, enum.Qualifier), enum.Comment.ToMarkdownText(true), enumTable.String())
+		body = fmt.Sprintf("## Enum: %s\n\n%s\n\n%s\n\n%s\n\n", enum.Name, fmt.Sprintf(fqnPureMd, enum.Qualifier), wc.Comment(enum.Comment, enum.Comments).ToMarkdownText(true), enumTable.String())
 	} else {
-		body = fmt.Sprintf("## Enum: %s\n%s\n\n%s\n\n%s\n\n", enum.Name, fmt.Sprintf(fqn, enum.Qualifier), enum.Comment.ToMarkdownBlockQuote(), enumTable.String())
+		body = fmt.Sprintf("## Enum: %s\n%s\n\n%s\n\n%s\n\n", enum.Name, fmt.Sprintf(fqn, enum.Qualifier), wc.Comment(enum.Comment, enum.Comments).ToMarkdownBlockQuote(), enumTable.String())
+	}
+	if len(enum.Options) > 0 {
+		body += FormatOptions(enum.Name, enum.Options, wc) + "\n"
+	}
+	if len(enum.Reserved) > 0 {
+		body += FormatReserved(enum.Name, enum.Reserved, wc) + "\n"
 	}
 	return body, diagram
 }
 
+// MessageHasDefaults returns true if any attribute declares a proto2 default value.
+func MessageHasDefaults(message *Message) bool {
+	for _, a := range message.Attributes {
+		if a.HasDefault() {
+			return true
+		}
+	}
+	return false
+}
+
+// AttributeLabel returns the label column value for an attribute. When features
+// of the attribute are set explicitly, the label reports the presence, repeated
+// encoding and enum semantics rather than the declared label.
+func AttributeLabel(a *Attribute) string {
+	label := ""
+	f := a.ExplicitFeatures
+	if a.Map {
+		label = "Map"
+	} else if a.Repeated {
+		label = "Repeated"
+		if f != nil && (IsPackableType(a.Kind[0]) || len(f.EnumType) > 0) {
+			if f.RepeatedFieldEncoding == RepeatedFieldEncodingPacked {
+				label += " (Packed)"
+			} else if f.RepeatedFieldEncoding == RepeatedFieldEncodingExpand {
+				label += " (Expanded)"
+			}
+		}
+	} else if len(a.Oneof) > 0 {
+		label = Join(Space, "Oneof", a.Oneof)
+	} else if a.Required || f != nil && f.FieldPresence == FieldPresenceLegacyRequired {
+		label = "Required"
+	} else if a.Optional {
+		label = "Optional"
+	} else if f != nil && f.FieldPresence == FieldPresenceExplicit && (IsScalarType(a.Kind[0]) || len(f.EnumType) > 0) {
+		label = "Optional"
+	}
+	if f != nil && f.EnumType == EnumTypeClosed {
+		label = strings.TrimSpace(Join(Space, label, "Closed Enum"))
+	}
+	if a.Group {
+		label = strings.TrimSpace(Join(Space, label, "Group"))
+	}
+	return label
+}
+
+// MessageFormatExtensions formats the proto2 extension ranges of a message.
+func MessageFormatExtensions(message *Message) (body string) {
+	extensionTable := NewMarkdownTable()
+	extensionTable.AddHeader("Extension Range")
+	for _, e := range message.Extensions {
+		extensionTable.Insert(e.String())
+	}
+	return fmt.Sprintf("### %s Extension Ranges\n\n%s\n", message.Name, extensionTable.String())
+}
+
 func MessageToMarkdown(message *Message, wc *WriterConfig) (body string, diagram string) {
+	hasDefaults := MessageHasDefaults(message)
 	attributeTable := NewMarkdownTable()
-	attributeTable.AddHeader("Field", "Ordinal", "Type", "Label", "Description")
+	if hasDefaults {
+		attributeTable.AddHeader("Field", "Ordinal", "Type", "Label", "Default", "Description")
+	} else {
+		attributeTable.AddHeader("Field", "Ordinal", "Type", "Label", "Description")
+	}
 
 	sort.Slice(message.Attributes, func(i, j int) bool {
 		return message.Attributes[i].Ordinal < message.Attributes[j].Ordinal
 	})
 
 	for _, a := range message.Attributes {
-		label := ""
-		if a.Map {
-			label = "Map"
-		} else if a.Repeated {
-			label = "Repeated"
-		} else if a.Optional {
-			label = "Optional"
-		}
+		row := make([]string, 0)
 		if wc.pureMarkdown {
-			attributeTable.Insert(fmt.Sprintf("`%s`", a.Name), strconv.Itoa(a.Ordinal), fmt.Sprintf("`%s`", strings.Join(a.Kind, Comma)), label, a.Comment.ToMarkdownText(false))
+			row = append(row, fmt.Sprintf("`%s`", a.Name), strconv.Itoa(a.Ordinal), wc.TypeCell(wc.AttributeType(a), a.Resolved...), AttributeLabel(a))
 		} else {
-			attributeTable.Insert(a.Name, strconv.Itoa(a.Ordinal), strings.Join(a.Kind, Comma), label, a.Comment.ToMarkdownText(false))
+			row = append(row, a.Name, strconv.Itoa(a.Ordinal), wc.TypeCell(wc.AttributeType(a), a.Resolved...), AttributeLabel(a))
 		}
+		if hasDefaults {
+			if wc.pureMarkdown && a.HasDefault() {
+				row = append(row, fmt.Sprintf("`%s`", EscapeTableCell(a.Default)))
+			} else {
+				row = append(row, EscapeTableCell(a.Default))
+			}
+		}
+		attributeTable.Insert(append(row, wc.Comment(a.Comment, a.Comments).ToMarkdownText(false))...)
 	}
 
 	if wc.visualize {
-		diagram = "\n" + ToMermaid(message.Name, message)
+		diagram = "\n" + ToDiagram(message.Name, message, wc)
 	}
 
 	if wc.pureMarkdown {
-		body = fmt.Sprintf("## Message: %s\n\n%s\n\n%s\n\n%s\n\n", message.Name, fmt.Sprintf(fqnPureMd, message.Qualifier), message.Comment.ToMarkdownText(true), attributeTable.String())
+		body = fmt.Sprintf("## Message: %s\n\n%s\n\n%s\n\n%s\n\n", message.Name, fmt.Sprintf(fqnPureMd, message.Qualifier), wc.Comment(message.Comment, message.Comments).ToMarkdownText(true), attributeTable.String())
 	} else {
-		body = fmt.Sprintf("## Message: %s\n%s\n\n%s\n\n%s\n\n", message.Name, fmt.Sprintf(fqn, message.Qualifier), message.Comment.ToMarkdownBlockQuote(), attributeTable.String())
+		body = fmt.Sprintf("## Message: %s\n%s\n\n%s\n\n%s\n\n", message.Name, fmt.Sprintf(fqn, message.Qualifier), wc.Comment(message.Comment, message.Comments).ToMarkdownBlockQuote(), attributeTable.String())
+	}
+	if len(message.Options) > 0 {
+		body += FormatOptions(message.Name, message.Options, wc) + "\n"
+	}
+	if len(message.Reserved) > 0 {
+		body += FormatReserved(message.Name, message.Reserved, wc) + "\n"
+	}
+	if message.HasExtensions() {
+		body += MessageFormatExtensions(message) + "\n"
 	}
 	for _, e := range message.Enums {
 		eBody, eDiagram := EnumToMarkdown(e, wc)
@@ -111,41 +323,79 @@ func MessageToMarkdown(message *Message, wc *WriterConfig) (body string, diagram
 	return body, diagram
 }
 
-func FormatServiceParameter(parameters []*Parameter) string {
+func FormatServiceParameter(parameters []*Parameter, wc *WriterConfig) string {
 	out := ""
 	for _, p := range parameters {
+		name := wc.TypeName(RemoveNameQualification(p.Type), p.Resolved)
 		if p.Stream {
-			out += fmt.Sprintf("Stream\\<%s\\>", RemoveNameQualification(p.Type))
+			out += fmt.Sprintf("Stream\\<%s\\>", name)
 		} else {
-			out += fmt.Sprintf("%s", RemoveNameQualification(p.Type))
+			out += fmt.Sprintf("%s", name)
 		}
 	}
 	return out
 }
 
+// ServiceFormatMethodOptions formats the options of the service methods, it
+// returns an empty string when no method declares an option.
+func ServiceFormatMethodOptions(s *Service, wc *WriterConfig) (body string) {
+	optionTable := NewMarkdownTable()
+	optionTable.AddHeader("Method", "Option", "Value", "Description")
+	count := 0
+	for _, m := range s.Methods {
+		for _, o := range m.Options {
+			count++
+			if wc.pureMarkdown {
+				optionTable.Insert(fmt.Sprintf("`%s`", m.Name), fmt.Sprintf("`%s`", o.Name), fmt.Sprintf("`%s`", o.Constant.String()), wc.Comment(o.Comment, o.Comments).ToMarkdownText(false))
+			} else {
+				optionTable.Insert(m.Name, o.Name, o.Constant.Text(), wc.Comment(o.Comment, o.Comments).ToMarkdownText(false))
+			}
+		}
+	}
+	if count == 0 {
+		return Empty
+	}
+	return fmt.Sprintf("### %s Method Options\n\n%s\n", s.Name, optionTable.String())
+}
+
+// ResolvedParameters returns the declarations of the parameter types.
+func ResolvedParameters(parameters []*Parameter) []*Symbol {
+	out := make([]*Symbol, 0, len(parameters))
+	for _, p := range parameters {
+		out = append(out, p.Resolved)
+	}
+	return out
+}
+
 func ServiceToMarkdown(s *Service, wc *WriterConfig) string {
 	methodTable := NewMarkdownTable()
 	methodTable.AddHeader("Method", "Parameter (In)", "Parameter (Out)", "Description")
 	for _, m := range s.Methods {
 		if wc.pureMarkdown {
 			methodTable.Insert(fmt.Sprintf("`%s`", m.Name),
-				fmt.Sprintf("`%s`", FormatServiceParameter(m.InputParameters)),
-				fmt.Sprintf("`%s`", FormatServiceParameter(m.ReturnParameters)), m.Comment.ToMarkdownText(false))
+				wc.TypeCell(FormatServiceParameter(m.InputParameters, wc), ResolvedParameters(m.InputParameters)...),
+				wc.TypeCell(FormatServiceParameter(m.ReturnParameters, wc), ResolvedParameters(m.ReturnParameters)...), wc.Comment(m.Comment, m.Comments).ToMarkdownText(false))
 		} else {
 			methodTable.Insert(m.Name,
-				FormatServiceParameter(m.InputParameters),
-				FormatServiceParameter(m.ReturnParameters), m.Comment.ToMarkdownText(false))
+				wc.TypeCell(FormatServiceParameter(m.InputParameters, wc), ResolvedParameters(m.InputParameters)...),
+				wc.TypeCell(FormatServiceParameter(m.ReturnParameters, wc), ResolvedParameters(m.ReturnParameters)...), wc.Comment(m.Comment, m.Comments).ToMarkdownText(false))
 		}
 	}
 	table := methodTable.String()
+	if len(s.Options) > 0 {
+		table += "\n" + FormatOptions(s.Name, s.Options, wc)
+	}
+	if options := ServiceFormatMethodOptions(s, wc); len(options) > 0 {
+		table += "\n" + options
+	}
 	if wc.visualize {
-		table = ToMermaid(s.Name, s) + "\n\n" + table
+		table = ToDiagram(s.Name, s, wc) + "\n\n" + table
 	}
 
 	if wc.pureMarkdown {
-		return fmt.Sprintf("## Service: %s\n\n%s\n\n%s\n\n%s\n\n", s.Name, fmt.Sprintf(fqnPureMd, s.Qualifier), s.Comment.ToMarkdownText(true), table)
+		return fmt.Sprintf("## Service: %s\n\n%s\n\n%s\n\n%s\n\n", s.Name, fmt.Sprintf(fqnPureMd, s.Qualifier), wc.Comment(s.Comment, s.Comments).ToMarkdownText(true), table)
 	}
-	return fmt.Sprintf("## Service: %s\n%s\n\n%s\n\n%s\n\n", s.Name, fmt.Sprintf(fqn, s.Qualifier), s.Comment.ToMarkdownBlockQuote(), table)
+	return fmt.Sprintf("## Service: %s\n%s\n\n%s\n\n%s\n\n", s.Name, fmt.Sprintf(fqn, s.Qualifier), wc.Comment(s.Comment, s.Comments).ToMarkdownBlockQuote(), table)
 }
 
 func HandleEnums(enums []*Enum, wc *WriterConfig) (body string) {
@@ -176,26 +426,138 @@ func HandleMessages(messages []*Message, wc *WriterConfig) (body string) {
 	return diagrams + body
 }
 
-func PackageFormatImports(p *Package) (body string) {
+// IsExternal determines if a resolved type is declared in a bundled package.
+func IsExternal(s *Symbol) bool {
+	return s != nil && s.Package != nil && s.Package.Bundled
+}
+
+// TypeName returns the name of a type displayed in the tables, the alias of a
+// bundled type with the alias style.
+func (wc *WriterConfig) TypeName(kind string, resolved *Symbol) string {
+	if wc.external != ExternalAliased || !IsExternal(resolved) {
+		return kind
+	}
+	if alias, ok := ExternalAliases[resolved.Name]; ok {
+		return strings.Replace(kind, strings.TrimSpace(kind), alias, 1)
+	}
+	return kind
+}
+
+// AttributeType returns the types of an attribute displayed in the tables.
+func (wc *WriterConfig) AttributeType(a *Attribute) string {
+	kinds := make([]string, len(a.Kind))
+	for i, kind := range a.Kind {
+		kinds[i] = wc.TypeName(kind, a.ResolvedKind(i))
+	}
+	return strings.Join(kinds, Comma)
+}
+
+// TypeCell formats the types of a table cell, as code with pure markdown. The
+// cell is linked to the markdown written for the package declaring a resolved
+// type when it is another written package.
+func (wc *WriterConfig) TypeCell(text string, resolved ...*Symbol) string {
+	if wc.pureMarkdown {
+		text = fmt.Sprintf("`%s`", text)
+	}
+	for _, r := range resolved {
+		if r != nil && r.Package != nil && r.Package != wc.current {
+			return wc.Link(wc.current, r.Package, text)
+		}
+	}
+	return text
+}
+
+// Link returns the text linked to the markdown written for the package to from
+// the markdown of the package from, or the text when either is not written.
+func (wc *WriterConfig) Link(from *Package, to *Package, text string) string {
+	source, ok := wc.outputs[from]
+	target, found := wc.outputs[to]
+	if !ok || !found {
+		return text
+	}
+	rel, err := filepath.Rel(filepath.Dir(source), target)
+	if err != nil {
+		return text
+	}
+	return fmt.Sprintf("[%s](%s)", text, filepath.ToSlash(rel))
+}
+
+func PackageFormatImports(p *Package, wc *WriterConfig) (body string) {
 	importTable := NewMarkdownTable()
 	importTable.AddHeader("Import", "Description")
 	for _, i := range p.Imports {
-		importTable.Insert(i.Path, i.Comment.ToMarkdownText(false))
+		importTable.Insert(wc.Link(p, i.Package, i.Path), wc.Comment(i.Comment, i.Comments).ToMarkdownText(false))
 	}
 	body = fmt.Sprintf("## Imports\n\n%s\n", importTable.String())
 	return body
 }
 
-func PackageFormatOptions(p *Package) (body string) {
+func PackageFormatOptions(p *Package, wc *WriterConfig) (body string) {
 	optionTable := NewMarkdownTable()
 	optionTable.AddHeader("Name", "Value", "Description")
 	for _, o := range p.Options {
-		optionTable.Insert(o.Name, o.Value, o.Comment.ToMarkdownText(false))
+		optionTable.Insert(o.Name, o.Value, wc.Comment(o.Comment, o.Comments).ToMarkdownText(false))
 	}
 	body = fmt.Sprintf("## Options\n\n%s\n", optionTable.String())
 	return body
 }
 
+// CollectExtensions returns the extensions declared in the package and in all
+// of its messages.
+func CollectExtensions(p *Package) []*Extension {
+	out := make([]*Extension, 0)
+	out = append(out, p.Extends...)
+	var collect func(messages []*Message)
+	collect = func(messages []*Message) {
+		for _, m := range messages {
+			out = append(out, m.Extends...)
+			collect(m.Messages)
+		}
+	}
+	collect(p.Messages)
+	return out
+}
+
+// PackageFormatExtensions formats the extend blocks of the package, listing the
+// extended type and the added attributes. The diagram only draws the blocks
+// declared at the top level, the blocks of messages are drawn with them.
+func PackageFormatExtensions(p *Package, wc *WriterConfig) (body string) {
+	extensions := CollectExtensions(p)
+	if len(extensions) == 0 {
+		return body
+	}
+	extensionTable := NewMarkdownTable()
+	extensionTable.AddHeader("Extendee", "Field", "Ordinal", "Type", "Label", "Description")
+	diagram := ""
+	for _, e := range extensions {
+		for _, a := range e.Attributes {
+			if wc.pureMarkdown {
+				extensionTable.Insert(fmt.Sprintf("`%s`", e.Extendee), fmt.Sprintf("`%s`", a.Name), strconv.Itoa(a.Ordinal),
+					wc.TypeCell(wc.AttributeType(a), a.Resolved...), AttributeLabel(a), wc.Comment(a.Comment, a.Comments).ToMarkdownText(false))
+			} else {
+				extensionTable.Insert(e.Extendee, a.Name, strconv.Itoa(a.Ordinal),
+					wc.TypeCell(wc.AttributeType(a), a.Resolved...), AttributeLabel(a), wc.Comment(a.Comment, a.Comments).ToMarkdownText(false))
+			}
+		}
+	}
+	for i, e := range p.Extends {
+		if wc.diagram == DiagramPlantUML {
+			diagram += ExtensionToPlantUML(e, i, wc)
+		} else {
+			diagram += ExtensionToMermaid(e, i, wc)
+		}
+	}
+	body = fmt.Sprintf("## Extensions\n\n%s\n", extensionTable.String())
+	if wc.visualize && len(diagram) > 0 {
+		if wc.diagram == DiagramPlantUML {
+			body += fmt.Sprintf(plantUMLClassDiagramTemplate, "Extensions", diagram) + "\n\n"
+		} else {
+			body += fmt.Sprintf(mermaidClassDiagramTemplate, "Extensions", diagram) + "\n\n"
+		}
+	}
+	return body
+}
+
 const fqn = "<div style=\"font-size: 12px; margin-top: -10px;\" class=\"fqn\">FQN: %s</div>"
 
 const fqnPureMd = "**FQN**: %s"
@@ -205,6 +567,9 @@ const footer = `
 <!-- https://github.com/GoogleCloudPlatform/proto-gen-md-diagrams -->`
 
 func PackageToMarkDown(p *Package, wc *WriterConfig) string {
+	config := *wc
+	config.current = p
+	wc = &config
 	out := ""
 	if len(p.Services) > 0 {
 		for _, s := range p.Services {
@@ -213,10 +578,11 @@ func PackageToMarkDown(p *Package, wc *WriterConfig) string {
 	}
 	out += HandleEnums(p.Enums, wc)
 	out += HandleMessages(p.Messages, wc)
+	out += PackageFormatExtensions(p, wc)
 	if wc.pureMarkdown {
-		out = fmt.Sprintf("# Package: %s\n\n%s\n\n%s\n\n%s\n\n%s\n%s\n", p.Name, p.Comment.ToMarkdownText(true), PackageFormatImports(p), PackageFormatOptions(p), out, footer)
+		out = fmt.Sprintf("# Package: %s\n\n%s\n\n%s\n\n%s\n\n%s\n%s\n", p.Name, wc.Comment(p.Comment, p.Comments).ToMarkdownText(true), PackageFormatImports(p, wc), PackageFormatOptions(p, wc), out, footer)
 	} else {
-		out = fmt.Sprintf("# Package: %s\n\n%s\n\n%s\n\n%s\n\n%s\n%s\n", p.Name, p.Comment.ToMarkdownBlockQuote(), PackageFormatImports(p), PackageFormatOptions(p), out, footer)
+		out = fmt.Sprintf("# Package: %s\n\n%s\n\n%s\n\n%s\n\n%s\n%s\n", p.Name, wc.Comment(p.Comment, p.Comments).ToMarkdownBlockQuote(), PackageFormatImports(p, wc), PackageFormatOptions(p, wc), out, footer)
 	}
 	return out
 }
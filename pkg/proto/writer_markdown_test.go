@@ -17,6 +17,7 @@
 package proto
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -112,7 +113,7 @@ func TestFormatServiceParameter(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equalf(t, tt.want, FormatServiceParameter(tt.args.parameters), "FormatServiceParameter(%v)", tt.args.parameters)
+			assert.Equalf(t, tt.want, FormatServiceParameter(tt.args.parameters, &WriterConfig{}), "FormatServiceParameter(%v)", tt.args.parameters)
 		})
 	}
 }
@@ -377,6 +378,14 @@ func TestMessageToMarkdown(t *testing.T) {
 	}
 }
 
+func TestWriterConfig_Comment(t *testing.T) {
+	comments := &Comments{Leading: "Leading", Trailing: "Trailing", Detached: []Comment{"Detached"}}
+	assert.Equal(t, Comment("All"), (&WriterConfig{}).Comment("All", comments))
+	assert.Equal(t, Comment("Leading Trailing"), (&WriterConfig{comments: CommentsAttached}).Comment("All", comments))
+	assert.Equal(t, Comment("Leading"), (&WriterConfig{comments: CommentsLeading}).Comment("All", comments))
+	assert.Equal(t, Comment("All"), (&WriterConfig{comments: CommentsLeading}).Comment("All", nil))
+}
+
 func TestPackageFormatImports(t *testing.T) {
 	type args struct {
 		p *Package
@@ -404,7 +413,7 @@ func TestPackageFormatImports(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equalf(t, tt.wantBody, PackageFormatImports(tt.args.p), "PackageFormatImports(%v)", tt.args.p)
+			assert.Equalf(t, tt.wantBody, PackageFormatImports(tt.args.p, &WriterConfig{}), "PackageFormatImports(%v)", tt.args.p)
 		})
 	}
 }
@@ -441,7 +450,7 @@ func TestPackageFormatOptions(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equalf(t, tt.wantBody, PackageFormatOptions(tt.args.p), "PackageFormatOptions(%v)", tt.args.p)
+			assert.Equalf(t, tt.wantBody, PackageFormatOptions(tt.args.p, &WriterConfig{}), "PackageFormatOptions(%v)", tt.args.p)
 		})
 	}
 }
@@ -592,3 +601,259 @@ func TestServiceToMarkdown(t *testing.T) {
 		})
 	}
 }
+
+func TestMessageToMarkdown_Proto2(t *testing.T) {
+	message := NewMessage()
+	message.Qualified = &Qualified{Qualifier: "test.Search", Name: "Search"}
+	message.Attributes = []*Attribute{
+		{Qualified: &Qualified{Name: "query"}, Required: true, Kind: []string{"string"}, Ordinal: 1},
+		{Qualified: &Qualified{Name: "page"}, Optional: true, Kind: []string{"int32"}, Ordinal: 2, Default: "10"},
+		{Qualified: &Qualified{Name: "result"}, Repeated: true, Group: true, Kind: []string{"Result"}, Ordinal: 3},
+	}
+	message.Extensions = []*ExtensionRange{NewExtensionRange(100, MaxFieldNumber)}
+
+	body, _ := MessageToMarkdown(message, &WriterConfig{})
+	assert.Equal(t, `## Message: Search
+<div style="font-size: 12px; margin-top: -10px;" class="fqn">FQN: test.Search</div>
+
+<div class="comment"><span></span><br/></div>
+
+| Field  | Ordinal | Type   | Label          | Default | Description |
+|--------|---------|--------|----------------|---------|-------------|
+| query  | 1       | string | Required       |         |             |
+| page   | 2       | int32  | Optional       | 10      |             |
+| result | 3       | Result | Repeated Group |         |             |
+
+
+### Search Extension Ranges
+
+| Extension Range |
+|-----------------|
+| 100 to max      |
+
+
+`, body)
+}
+
+func TestMessageToMarkdown_EscapedDefault(t *testing.T) {
+	message := NewMessage()
+	message.Qualified = &Qualified{Qualifier: "test.Search", Name: "Search"}
+	message.Attributes = []*Attribute{
+		{Qualified: &Qualified{Name: "separator"}, Optional: true, Kind: []string{"string"}, Ordinal: 1, Default: `"a|b"`},
+	}
+	body, _ := MessageToMarkdown(message, &WriterConfig{})
+	assert.Contains(t, body, "| separator | 1       | string | Optional | \"a\\|b\"  |             |\n")
+	body, _ = MessageToMarkdown(message, &WriterConfig{pureMarkdown: true})
+	assert.Contains(t, body, "`\"a\\|b\"`")
+}
+
+func TestPackageToMarkDown_Proto2File(t *testing.T) {
+	p := NewPackage("data/test/proto2/search.proto")
+	diagnostics, err := p.Read(false)
+	assert.Nil(t, err)
+	assert.Empty(t, diagnostics)
+
+	body := PackageToMarkDown(p, &WriterConfig{})
+	assert.Contains(t, body, `| Field            | Ordinal | Type   | Label    | Default | Description                     |
+|------------------|---------|--------|----------|---------|---------------------------------|
+| query            | 1       | string | Required |         | The query to search for         |
+| page_number      | 2       | int32  | Optional | 1       | The page number to return       |
+| results_per_page | 3       | int32  | Optional | 10      | The number of results per page  |
+`)
+	assert.Contains(t, body, "| 100 to max      |\n")
+	assert.Contains(t, body, "| result | 1       | Result | Repeated Group | A single search result  |\n")
+	assert.Contains(t, body, `| url   | 2       | string | Required | The url of the result    |
+| title | 3       | string | Optional | The title of the result  |
+`)
+}
+
+func TestAttributeLabel(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *Attribute
+		want string
+	}{
+		{name: "Unresolved", in: &Attribute{Kind: []string{"int32"}}, want: ""},
+		{name: "Default Features", in: &Attribute{Repeated: true, Kind: []string{"int32"},
+			Features: &Features{RepeatedFieldEncoding: RepeatedFieldEncodingPacked}}, want: "Repeated"},
+		{name: "Implicit", in: &Attribute{Kind: []string{"int32"},
+			ExplicitFeatures: &Features{FieldPresence: FieldPresenceImplicit}}, want: ""},
+		{name: "Explicit", in: &Attribute{Kind: []string{"int32"},
+			ExplicitFeatures: &Features{FieldPresence: FieldPresenceExplicit}}, want: "Optional"},
+		{name: "Explicit Message", in: &Attribute{Kind: []string{"Address"},
+			ExplicitFeatures: &Features{FieldPresence: FieldPresenceExplicit}}, want: ""},
+		{name: "Legacy Required", in: &Attribute{Kind: []string{"int32"},
+			ExplicitFeatures: &Features{FieldPresence: FieldPresenceLegacyRequired}}, want: "Required"},
+		{name: "Packed", in: &Attribute{Repeated: true, Kind: []string{"int32"},
+			ExplicitFeatures: &Features{RepeatedFieldEncoding: RepeatedFieldEncodingPacked}}, want: "Repeated (Packed)"},
+		{name: "Strings are not packed", in: &Attribute{Repeated: true, Kind: []string{"string"},
+			ExplicitFeatures: &Features{RepeatedFieldEncoding: RepeatedFieldEncodingPacked}}, want: "Repeated"},
+		{name: "Closed Enum", in: &Attribute{Kind: []string{"Kind"},
+			ExplicitFeatures: &Features{FieldPresence: FieldPresenceExplicit, EnumType: EnumTypeClosed}}, want: "Optional Closed Enum"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, AttributeLabel(tt.in), "AttributeLabel(%v)", tt.in)
+		})
+	}
+}
+
+func TestPackageFormatExtensions(t *testing.T) {
+	p := NewPackage("test.proto")
+	assert.Equal(t, "", PackageFormatExtensions(p, &WriterConfig{}))
+
+	e := NewExtension("test", "google.protobuf.FieldOptions", "")
+	e.AddAttribute(&Attribute{Qualified: &Qualified{Name: "sensitive", Comment: "Sensitive data"},
+		Optional: true, Kind: []string{"bool"}, Ordinal: 50000})
+	p.Extends = append(p.Extends, e)
+	assert.Equal(t, `## Extensions
+
+| Extendee                     | Field     | Ordinal | Type | Label    | Description     |
+|------------------------------|-----------|---------|------|----------|-----------------|
+| google.protobuf.FieldOptions | sensitive | 50000   | bool | Optional | Sensitive data  |
+
+`, PackageFormatExtensions(p, &WriterConfig{}))
+	assert.Contains(t, PackageFormatExtensions(p, &WriterConfig{visualize: true, diagram: DiagramPlantUML}),
+		"### Extensions Diagram\n\n```plantuml\n@startuml\nset separator none\nleft to right direction\n\nclass test_FieldOptionsExtension_1 <<extension>> {\n")
+}
+
+func TestFormatReserved(t *testing.T) {
+	reserved := []*Reserved{
+		{Ranges: []*Range{NewRange(2, 2), NewRange(9, 11)}, Comment: "Removed fields"},
+		NewReservedNames("foo", "bar"),
+	}
+	assert.Equal(t, `### Address Reserved
+
+| Reserved     | Description     |
+|--------------|-----------------|
+| 2, 9 to 11   | Removed fields  |
+| "foo", "bar" |                 |
+
+`, FormatReserved("Address", reserved, &WriterConfig{}))
+}
+
+func TestServiceFormatMethodOptions(t *testing.T) {
+	s := NewService("test", "Library", "")
+	s.AddRpc(NewRpc("test.Library", "ListBooks", ""))
+	assert.Equal(t, "", ServiceFormatMethodOptions(s, &WriterConfig{}))
+
+	rpc := NewRpc("test.Library", "GetBook", "")
+	rpc.AddRpcOption(NewRpcOption("test.Library.GetBook", "(google.api.http)", "REST", `{ get: '/v1' }`))
+	s.AddRpc(rpc)
+	assert.Equal(t, `### Library Method Options
+
+| Method  | Option            | Value          | Description |
+|---------|-------------------|----------------|-------------|
+| GetBook | (google.api.http) | { get: "/v1" } | REST        |
+
+`, ServiceFormatMethodOptions(s, &WriterConfig{}))
+}
+
+func TestFormatOptions(t *testing.T) {
+	options := []*Option{NewOption("deprecated", "true", "Replaced"), NewOption("(my.option)", "'a'", "")}
+	assert.Equal(t, `### Book Options
+
+| Option      | Value | Description |
+|-------------|-------|-------------|
+| deprecated  | true  | Replaced    |
+| (my.option) | a     |             |
+
+`, FormatOptions("Book", options, &WriterConfig{}))
+	assert.Equal(t, `### Book Options
+
+| Option        | Value  | Description |
+|---------------|--------|-------------|
+| `+"`deprecated`"+`  | `+"`true`"+` | Replaced    |
+| `+"`(my.option)`"+` | `+"`\"a\"`"+`  |             |
+
+`, FormatOptions("Book", options, &WriterConfig{pureMarkdown: true}))
+}
+
+func TestEnumToMarkdown_ValueOptions(t *testing.T) {
+	enum := NewEnum("test.Format", "Format", "")
+	enum.Values = append(enum.Values,
+		NewEnumValue("test.Format", "0", "FORMAT_UNSPECIFIED", ""),
+		&EnumValue{Namespace: "test.Format", Ordinal: 1, Value: "HARDBACK", Options: []*Option{NewOption("deprecated", "true", "")}})
+	enum.Options = append(enum.Options, NewOption("allow_alias", "true", ""))
+	body, _ := EnumToMarkdown(enum, &WriterConfig{})
+	assert.Contains(t, body, `| Name               | Ordinal | Options           | Description |
+|--------------------|---------|-------------------|-------------|
+| FORMAT_UNSPECIFIED | 0       |                   |             |
+| HARDBACK           | 1       | deprecated = true |             |
+`)
+	assert.Contains(t, body, "### Format Options")
+}
+
+func TestWriterConfig_Link(t *testing.T) {
+	service, model, other := NewPackage("a"), NewPackage("b"), NewPackage("c")
+	wc := &WriterConfig{outputs: map[*Package]string{
+		service: filepath.Join("out", "service", "service.proto.md"),
+		model:   filepath.Join("out", "location", "model.proto.md"),
+	}}
+	assert.Equal(t, "[model.proto](../location/model.proto.md)", wc.Link(service, model, "model.proto"))
+	assert.Equal(t, "other.proto", wc.Link(service, other, "other.proto"))
+	assert.Equal(t, "other.proto", wc.Link(service, nil, "other.proto"))
+	assert.Equal(t, "model.proto", (&WriterConfig{}).Link(service, model, "model.proto"))
+}
+
+func TestWriterConfig_AttributeType(t *testing.T) {
+	timestamp := &Symbol{Name: "google.protobuf.Timestamp", Kind: SymbolMessage, Package: &Package{Bundled: true}}
+	created := &Attribute{Kind: []string{"google.protobuf.Timestamp"}, Resolved: []*Symbol{timestamp}}
+	history := &Attribute{Map: true, Kind: []string{"string", " google.protobuf.Timestamp"}, Resolved: []*Symbol{nil, timestamp}}
+	unlinked := &Attribute{Kind: []string{"google.protobuf.Timestamp"}}
+
+	wc := &WriterConfig{external: ExternalAliased}
+	assert.Equal(t, "timestamp (RFC 3339)", wc.AttributeType(created))
+	assert.Equal(t, "string, timestamp (RFC 3339)", wc.AttributeType(history))
+	assert.Equal(t, "google.protobuf.Timestamp", wc.AttributeType(unlinked))
+	assert.Equal(t, "google.protobuf.Timestamp", (&WriterConfig{}).AttributeType(created))
+	assert.Equal(t, "Stream\\<timestamp (RFC 3339)\\>", FormatServiceParameter([]*Parameter{{Stream: true, Type: "google.protobuf.Timestamp", Resolved: timestamp}}, wc))
+}
+
+func TestWriterConfig_TypeCell(t *testing.T) {
+	service, model := NewPackage("service.proto"), NewPackage("model.proto")
+	location := &Symbol{Name: "test.Location", Kind: SymbolMessage, Package: model}
+	local := &Symbol{Name: "test.Request", Kind: SymbolMessage, Package: service}
+	wc := &WriterConfig{current: service, outputs: map[*Package]string{
+		service: filepath.Join("out", "service", "service.proto.md"),
+		model:   filepath.Join("out", "location", "model.proto.md"),
+	}}
+	assert.Equal(t, "[Location](../location/model.proto.md)", wc.TypeCell("Location", location))
+	assert.Equal(t, "[string, Location](../location/model.proto.md)", wc.TypeCell("string, Location", nil, location))
+	assert.Equal(t, "Request", wc.TypeCell("Request", local))
+	assert.Equal(t, "string", wc.TypeCell("string"))
+	wc.pureMarkdown = true
+	assert.Equal(t, "[`Location`](../location/model.proto.md)", wc.TypeCell("Location", location))
+	assert.Equal(t, "`Request`", wc.TypeCell("Request", local))
+}
+
+func TestPackageToMarkDown_LinkedTypes(t *testing.T) {
+	model, err := ParseString("location/model.proto", `syntax = "proto3";
+package test.location;
+message Location {
+  string name = 1;
+}
+`)
+	assert.Nil(t, err)
+	service, err := ParseString("service/service.proto", `syntax = "proto3";
+package test.service;
+import "location/model.proto";
+message Request {
+  test.location.Location location = 1;
+}
+service Locations {
+  rpc Get(Request) returns (test.location.Location);
+}
+`)
+	assert.Nil(t, err)
+	service.Imports[0].Package = model
+	Link(service, model)
+	wc := &WriterConfig{outputs: map[*Package]string{
+		service: filepath.Join("out", "service", "service.proto.md"),
+		model:   filepath.Join("out", "location", "model.proto.md"),
+	}}
+	out := PackageToMarkDown(service, wc)
+	assert.Contains(t, out, "| location | 1       | [test.location.Location](../location/model.proto.md) |")
+	assert.Contains(t, out, "| Get    | Request        | [Location](../location/model.proto.md) |")
+	assert.Nil(t, wc.current)
+}
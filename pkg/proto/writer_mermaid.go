@@ -18,15 +18,16 @@ package proto
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
 // PackageToMermaid formats a Package into Mermaid syntax
-func PackageToMermaid(p *Package) string {
+func PackageToMermaid(p *Package, wc *WriterConfig) string {
 	out := fmt.Sprintf(`%%%%`+" Mermaid Diagram for package: %s\n", p.Name)
 
 	for _, m := range p.Messages {
-		out += MessageToMermaid(m)
+		out += MessageToMermaid(m, wc)
 	}
 
 	for _, e := range p.Enums {
@@ -34,9 +35,37 @@ func PackageToMermaid(p *Package) string {
 	}
 
 	for _, s := range p.Services {
-		out += ServiceToMermaid(s)
+		out += ServiceToMermaid(s, wc)
 	}
 
+	for i, e := range p.Extends {
+		out += ExtensionToMermaid(e, i, wc)
+	}
+
+	return out
+}
+
+// ExtensionClassName returns the class name of the extend block at the index
+// of the blocks of its scope, e.g. test_Book_FieldOptionsExtension_1 for the
+// first block of the test.Book message extending google.protobuf.FieldOptions.
+func ExtensionClassName(e *Extension, index int) string {
+	name := RemoveNameQualification(e.Extendee) + "Extension"
+	scope := strings.ReplaceAll(QualifiedName(e.Qualifier), Period, "_")
+	return Join("_", scope, name, strconv.Itoa(index+1))
+}
+
+// ExtensionToMermaid formats the extend block at the index of the blocks of its
+// scope into mermaid text, with an inheritance relationship to the extended
+// message.
+func ExtensionToMermaid(e *Extension, index int, wc *WriterConfig) string {
+	name := ExtensionClassName(e, index)
+	out := fmt.Sprintf("\n%s\nclass %s {\n  <<extension>>\n", e.Comment.ToMermaid(), name)
+	for _, a := range e.Attributes {
+		out += fmt.Sprintf("  %s\n", a.ToMermaid())
+	}
+	out += "}\n"
+	out += TypeRelationshipToMermaid(name, "--|>", e.Extendee, e.Resolved, wc, " : extends")
+	out += AttributeRelationshipsToMermaid(name, e.Attributes, wc)
 	return out
 }
 
@@ -50,32 +79,87 @@ func EnumToMermaid(e *Enum) string {
 	return out
 }
 
-// MessageToMermaid formats a Message into mermaid text
-func MessageToMermaid(m *Message) string {
-	out := fmt.Sprintf("\n%s\nclass %s {\n", m.Comment.ToMermaid(), m.Name)
-	for _, a := range m.Attributes {
-		out += fmt.Sprintf("  %s\n", a.ToMermaid())
+// TypeRelationshipToMermaid formats the relationship from a class to a type,
+// relationships to the types of bundled packages are rendered with the
+// external style of the configuration.
+func TypeRelationshipToMermaid(name string, arrow string, kind string, resolved *Symbol, wc *WriterConfig, label string) string {
+	if !IsExternal(resolved) {
+		return fmt.Sprintf("%s %s `%s`%s\n", name, arrow, kind, label)
 	}
-	out += "}\n"
+	if wc.external != ExternalNodes {
+		return Empty
+	}
+	kind = strings.TrimSpace(kind)
+	return fmt.Sprintf("%s %s `%s`%s\nclass `%s` {\n  <<external>>\n}\n", name, arrow, kind, label, kind)
+}
 
-	// Handle Attribute Relationships
-	for _, a := range m.Attributes {
+// AttributeRelationshipsToMermaid formats the relationships from a class to the
+// types of its attributes.
+func AttributeRelationshipsToMermaid(name string, attributes []*Attribute, wc *WriterConfig) string {
+	out := ""
+	for _, a := range attributes {
+		if a.Group {
+			// Groups are rendered with the nested message relationships
+			continue
+		}
 		if len(a.Kind) == 1 {
-			if !strings.Contains(Protobuf3Types, a.Kind[0]) {
-				out += fmt.Sprintf("%s --> `%s`\n", m.Name, a.Kind[0])
+			if !IsScalarType(a.Kind[0]) {
+				out += TypeRelationshipToMermaid(name, "-->", a.Kind[0], a.ResolvedKind(0), wc, Empty)
 			}
 		} else if len(a.Kind) == 2 {
-			if !strings.Contains(Protobuf3Types, strings.TrimSpace(a.Kind[1])) {
-				out += fmt.Sprintf("%s .. `%s`\n", m.Name, a.Kind[1])
+			if !IsScalarType(a.Kind[1]) {
+				out += TypeRelationshipToMermaid(name, "..", a.Kind[1], a.ResolvedKind(1), wc, Empty)
 			}
 		}
 	}
+	return out
+}
+
+// OneofToMermaid formats a Oneof of the named message into a mermaid class with
+// a choice relationship from the message.
+func OneofToMermaid(messageName string, o *Oneof, wc *WriterConfig) string {
+	name := Join("_", messageName, o.Name)
+	out := fmt.Sprintf("\n%s\nclass %s {\n  <<oneof>>\n", o.Comment.ToMermaid(), name)
+	for _, a := range o.Attributes {
+		out += fmt.Sprintf("  %s\n", a.ToMermaid())
+	}
+	out += "}\n"
+	out += fmt.Sprintf("%s ..> `%s` : oneof\n", messageName, name)
+	out += AttributeRelationshipsToMermaid(name, o.Attributes, wc)
+	return out
+}
+
+// MessageToMermaid formats a Message into mermaid text
+func MessageToMermaid(m *Message, wc *WriterConfig) string {
+	attributes := make([]*Attribute, 0)
+	for _, a := range m.Attributes {
+		if len(a.Oneof) == 0 {
+			attributes = append(attributes, a)
+		}
+	}
+
+	out := fmt.Sprintf("\n%s\nclass %s {\n", m.Comment.ToMermaid(), m.Name)
+	for _, a := range attributes {
+		out += fmt.Sprintf("  %s\n", a.ToMermaid())
+	}
+	for _, e := range m.Extensions {
+		out += fmt.Sprintf("  extensions %s\n", e.String())
+	}
+	out += "}\n"
+
+	// Handle Attribute Relationships
+	out += AttributeRelationshipsToMermaid(m.Name, attributes, wc)
+
+	// Handle Oneof Relationships
+	for _, o := range m.Oneofs {
+		out += OneofToMermaid(m.Name, o, wc)
+	}
 
 	// Handle Message Relationships
 	if m.HasMessages() {
 		for _, msg := range m.Messages {
 			out += fmt.Sprintf("%s --o `%s`\n", m.Name, msg.Name)
-			out += MessageToMermaid(msg)
+			out += MessageToMermaid(msg, wc)
 		}
 	}
 
@@ -85,6 +169,11 @@ func MessageToMermaid(m *Message) string {
 		out += EnumToMermaid(e)
 	}
 
+	// Handle Scoped Extensions
+	for i, e := range m.Extends {
+		out += ExtensionToMermaid(e, i, wc)
+	}
+
 	return out
 }
 
@@ -106,7 +195,7 @@ func FormatParametersForMermaid(in []*Parameter) string {
 }
 
 // FormatRelationships formats a service relationship
-func FormatRelationships(name string, in []*Parameter) string {
+func FormatRelationships(name string, in []*Parameter, wc *WriterConfig) string {
 	out := ""
 	for _, i := range in {
 		t := strings.TrimSpace(i.Type)
@@ -115,16 +204,16 @@ func FormatRelationships(name string, in []*Parameter) string {
 		}
 
 		if i.Stream {
-			out += fmt.Sprintf("%s --o `%s`\n", name, t)
+			out += TypeRelationshipToMermaid(name, "--o", t, i.Resolved, wc, Empty)
 		} else {
-			out += fmt.Sprintf("%s --> `%s`\n", name, t)
+			out += TypeRelationshipToMermaid(name, "-->", t, i.Resolved, wc, Empty)
 		}
 	}
 	return out
 }
 
 // Formats a Service into mermaid text
-func ServiceToMermaid(s *Service) string {
+func ServiceToMermaid(s *Service, wc *WriterConfig) string {
 	relationships := ""
 
 	out := fmt.Sprintf("class %s {\n  <<service>>\n", s.Name)
@@ -134,8 +223,8 @@ func ServiceToMermaid(s *Service) string {
 			FormatParametersForMermaid(m.InputParameters),
 			FormatParametersForMermaid(m.ReturnParameters))
 
-		relationships += FormatRelationships(s.Name, m.InputParameters)
-		relationships += FormatRelationships(s.Name, m.ReturnParameters)
+		relationships += FormatRelationships(s.Name, m.InputParameters, wc)
+		relationships += FormatRelationships(s.Name, m.ReturnParameters, wc)
 	}
 	out += "}\n"
 	out += relationships
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageToMermaid_Oneof(t *testing.T) {
+	m := NewMessage()
+	m.Name = "Payment"
+	id := &Attribute{Qualified: &Qualified{Name: "id"}, Kind: []string{"string"}, Ordinal: 1}
+	card := &Attribute{Qualified: &Qualified{Name: "card"}, Kind: []string{"Card"}, Ordinal: 2}
+	o := NewOneof("test.Payment", "method", "")
+	o.AddAttribute(card)
+	m.Attributes = []*Attribute{id, card}
+	m.Oneofs = []*Oneof{o}
+
+	assert.Equal(t, "\n%% \n\nclass Payment {\n  + string id\n}\n"+
+		"\n%% \n\nclass Payment_method {\n  <<oneof>>\n  + Card card\n}\n"+
+		"Payment ..> `Payment_method` : oneof\n"+
+		"Payment_method --> `Card`\n", MessageToMermaid(m, &WriterConfig{}))
+}
+
+func TestExtensionToMermaid(t *testing.T) {
+	e := NewExtension("test", "google.protobuf.FieldOptions", "")
+	e.AddAttribute(&Attribute{Qualified: &Qualified{Name: "sensitive"}, Optional: true, Kind: []string{"bool"}, Ordinal: 50000})
+	assert.Equal(t, "\n%% \n\nclass test_FieldOptionsExtension_1 {\n  <<extension>>\n  + Optional~bool~ sensitive\n}\n"+
+		"test_FieldOptionsExtension_1 --|> `google.protobuf.FieldOptions` : extends\n", ExtensionToMermaid(e, 0, &WriterConfig{}))
+}
+
+func TestExtensionClassName(t *testing.T) {
+	p, err := ParseString("test.proto", `syntax = "proto2";
+package test;
+import "google/protobuf/descriptor.proto";
+extend google.protobuf.FieldOptions {
+  optional bool sensitive = 50000;
+}
+extend google.protobuf.FieldOptions {
+  optional bool hidden = 50001;
+}
+message Book {
+  extend google.protobuf.FieldOptions {
+    optional bool tracked = 50002;
+  }
+}
+`)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(p.Extends))
+	assert.Equal(t, "test_FieldOptionsExtension_1", ExtensionClassName(p.Extends[0], 0))
+	assert.Equal(t, "test_FieldOptionsExtension_2", ExtensionClassName(p.Extends[1], 1))
+	assert.Equal(t, "test_Book_FieldOptionsExtension_1", ExtensionClassName(p.Messages[0].Extends[0], 0))
+
+	// Message extensions are drawn in the message diagram, not with the file extensions.
+	out := PackageFormatExtensions(p, &WriterConfig{visualize: true})
+	assert.Contains(t, out, "| google.protobuf.FieldOptions | tracked")
+	assert.Contains(t, out, "class test_FieldOptionsExtension_2 {")
+	assert.NotContains(t, out, "test_Book_FieldOptionsExtension_1")
+	assert.Contains(t, MessageToMermaid(p.Messages[0], &WriterConfig{}), "class test_Book_FieldOptionsExtension_1 {")
+}
+
+func TestAttributeRelationshipsToMermaid(t *testing.T) {
+	attributes := []*Attribute{
+		{Qualified: &Qualified{Name: "count"}, Kind: []string{"int32"}, Ordinal: 1},
+		{Qualified: &Qualified{Name: "value"}, Kind: []string{"int"}, Ordinal: 2},
+		{Qualified: &Qualified{Name: "printer"}, Kind: []string{"Stringer"}, Ordinal: 3},
+		{Qualified: &Qualified{Name: "labels"}, Map: true, Kind: []string{"string", " Int"}, Ordinal: 4},
+		{Qualified: &Qualified{Name: "names"}, Map: true, Kind: []string{"string", " string"}, Ordinal: 5},
+	}
+	assert.Equal(t, "A --> `int`\nA --> `Stringer`\nA .. ` Int`\n", AttributeRelationshipsToMermaid("A", attributes, &WriterConfig{}))
+}
+
+func TestTypeRelationshipToMermaid(t *testing.T) {
+	timestamp := &Symbol{Name: "google.protobuf.Timestamp", Kind: SymbolMessage, Package: &Package{Bundled: true}}
+	local := &Symbol{Name: "test.Address", Kind: SymbolMessage, Package: &Package{}}
+	tests := []struct {
+		name     string
+		kind     string
+		resolved *Symbol
+		external ExternalStyle
+		want     string
+	}{
+		{name: "Unresolved", kind: "Address", want: "A --> `Address`\n"},
+		{name: "Local", kind: "Address", resolved: local, external: ExternalHidden, want: "A --> `Address`\n"},
+		{name: "External Node", kind: " google.protobuf.Timestamp", resolved: timestamp, external: ExternalNodes,
+			want: "A --> `google.protobuf.Timestamp`\nclass `google.protobuf.Timestamp` {\n  <<external>>\n}\n"},
+		{name: "External Hidden", kind: "google.protobuf.Timestamp", resolved: timestamp, external: ExternalHidden, want: ""},
+		{name: "External Aliased", kind: "google.protobuf.Timestamp", resolved: timestamp, external: ExternalAliased, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, TypeRelationshipToMermaid("A", "-->", tt.kind, tt.resolved, &WriterConfig{external: tt.external}, Empty))
+		})
+	}
+}
@@ -0,0 +1,233 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PackageToPlantUML formats a Package into PlantUML syntax, the declarations
+// of a named package are enclosed in a package block.
+func PackageToPlantUML(p *Package, wc *WriterConfig) string {
+	out := fmt.Sprintf("' PlantUML Diagram for package: %s\n", p.Name)
+	body := ""
+	for _, m := range p.Messages {
+		body += MessageToPlantUML(m, wc)
+	}
+	for _, e := range p.Enums {
+		body += EnumToPlantUML(e)
+	}
+	for _, s := range p.Services {
+		body += ServiceToPlantUML(s, wc)
+	}
+	for i, e := range p.Extends {
+		body += ExtensionToPlantUML(e, i, wc)
+	}
+	if len(p.Name) == 0 {
+		return out + body
+	}
+	return out + fmt.Sprintf("package %s {\n%s}\n", p.Name, body)
+}
+
+// ExtensionToPlantUML formats the extend block at the index of the blocks of its
+// scope into PlantUML text, with an inheritance relationship to the extended
+// message.
+func ExtensionToPlantUML(e *Extension, index int, wc *WriterConfig) string {
+	name := ExtensionClassName(e, index)
+	out := fmt.Sprintf("\n%sclass %s <<extension>> {\n", e.Comment.ToPlantUML(), name)
+	for _, a := range e.Attributes {
+		out += fmt.Sprintf("  %s\n", a.ToPlantUML())
+	}
+	out += "}\n"
+	out += TypeRelationshipToPlantUML(name, "--|>", e.Extendee, e.Resolved, wc, " : extends")
+	out += AttributeRelationshipsToPlantUML(name, e.Attributes, wc)
+	return out
+}
+
+// EnumToPlantUML formats an Enum into PlantUML text.
+func EnumToPlantUML(e *Enum) string {
+	return enumToPlantUML(e.Name, e)
+}
+
+// enumToPlantUML formats an Enum into PlantUML text, as the named class.
+func enumToPlantUML(name string, e *Enum) string {
+	out := fmt.Sprintf("\n%senum %s <<enumeration>> {\n", e.Comment.ToPlantUML(), name)
+	for _, v := range e.Values {
+		out += fmt.Sprintf("  %s\n", v.Value)
+	}
+	out += "}\n"
+	return out
+}
+
+// PlantUMLTypeName returns the class name of a type reference, the nested
+// messages and enums of a resolved type are joined to their parents with an
+// underscore, e.g. `library.Book.Status` becomes `library.Book_Status`.
+func PlantUMLTypeName(kind string, resolved *Symbol) string {
+	kind = QualifiedName(strings.TrimSpace(kind))
+	if !resolved.IsType() {
+		return kind
+	}
+	name := resolved.Name
+	if resolved.Package != nil && len(resolved.Package.Name) > 0 {
+		name = strings.TrimPrefix(name, resolved.Package.Name+Period)
+	}
+	nested := strings.Split(name, Period)
+	if len(nested) == 1 {
+		return kind
+	}
+	// The package qualification is kept as written
+	written := strings.Split(kind, Period)
+	if len(written) <= len(nested) {
+		return strings.Join(nested, "_")
+	}
+	return Join(Period, strings.Join(written[:len(written)-len(nested)], Period), strings.Join(nested, "_"))
+}
+
+// TypeRelationshipToPlantUML formats the relationship from a class to a type,
+// relationships to the types of bundled packages are rendered with the
+// external style of the configuration.
+func TypeRelationshipToPlantUML(name string, arrow string, kind string, resolved *Symbol, wc *WriterConfig, label string) string {
+	kind = PlantUMLTypeName(kind, resolved)
+	if !IsExternal(resolved) {
+		return fmt.Sprintf("%s %s %s%s\n", name, arrow, kind, label)
+	}
+	if wc.external != ExternalNodes {
+		return Empty
+	}
+	return fmt.Sprintf("%s %s %s%s\nclass %s <<external>>\n", name, arrow, kind, label, kind)
+}
+
+// AttributeRelationshipsToPlantUML formats the relationships from a class to
+// the types of its attributes.
+func AttributeRelationshipsToPlantUML(name string, attributes []*Attribute, wc *WriterConfig) string {
+	out := ""
+	for _, a := range attributes {
+		if a.Group {
+			// Groups are rendered with the nested message relationships
+			continue
+		}
+		if len(a.Kind) == 1 {
+			if !IsScalarType(a.Kind[0]) {
+				out += TypeRelationshipToPlantUML(name, "-->", a.Kind[0], a.ResolvedKind(0), wc, Empty)
+			}
+		} else if len(a.Kind) == 2 {
+			if !IsScalarType(a.Kind[1]) {
+				out += TypeRelationshipToPlantUML(name, "..", a.Kind[1], a.ResolvedKind(1), wc, Empty)
+			}
+		}
+	}
+	return out
+}
+
+// OneofToPlantUML formats a Oneof of the named message into a PlantUML class
+// with a choice relationship from the message.
+func OneofToPlantUML(messageName string, o *Oneof, wc *WriterConfig) string {
+	name := Join("_", messageName, o.Name)
+	out := fmt.Sprintf("\n%sclass %s <<oneof>> {\n", o.Comment.ToPlantUML(), name)
+	for _, a := range o.Attributes {
+		out += fmt.Sprintf("  %s\n", a.ToPlantUML())
+	}
+	out += "}\n"
+	out += fmt.Sprintf("%s ..> %s : oneof\n", messageName, name)
+	out += AttributeRelationshipsToPlantUML(name, o.Attributes, wc)
+	return out
+}
+
+// MessageToPlantUML formats a Message into PlantUML text, with its nested
+// messages and enums. The classes of the nested declarations are named after
+// their parents, e.g. `Book_Status`, as the oneof classes are.
+func MessageToPlantUML(m *Message, wc *WriterConfig) string {
+	return messageToPlantUML(m.Name, m, wc)
+}
+
+// messageToPlantUML formats a Message into PlantUML text, as the named class.
+func messageToPlantUML(name string, m *Message, wc *WriterConfig) string {
+	attributes := make([]*Attribute, 0)
+	for _, a := range m.Attributes {
+		if len(a.Oneof) == 0 {
+			attributes = append(attributes, a)
+		}
+	}
+
+	out := fmt.Sprintf("\n%sclass %s {\n", m.Comment.ToPlantUML(), name)
+	for _, a := range attributes {
+		out += fmt.Sprintf("  %s\n", a.ToPlantUML())
+	}
+	for _, e := range m.Extensions {
+		out += fmt.Sprintf("  extensions %s\n", e.String())
+	}
+	out += "}\n"
+
+	out += AttributeRelationshipsToPlantUML(name, attributes, wc)
+	for _, o := range m.Oneofs {
+		out += OneofToPlantUML(name, o, wc)
+	}
+	for _, msg := range m.Messages {
+		nested := Join("_", name, msg.Name)
+		out += fmt.Sprintf("%s --o %s\n", name, nested)
+		out += messageToPlantUML(nested, msg, wc)
+	}
+	for _, e := range m.Enums {
+		nested := Join("_", name, e.Name)
+		out += fmt.Sprintf("%s --o %s\n", name, nested)
+		out += enumToPlantUML(nested, e)
+	}
+	for i, e := range m.Extends {
+		out += ExtensionToPlantUML(e, i, wc)
+	}
+	return out
+}
+
+// FormatParametersForPlantUML formats the parameters of an rpc, streamed
+// parameters as generic Stream types.
+func FormatParametersForPlantUML(in []*Parameter) string {
+	out := make([]string, 0, len(in))
+	for _, p := range in {
+		if p.Stream {
+			out = append(out, fmt.Sprintf("Stream<%s>", RemoveNameQualification(p.Type)))
+		} else {
+			out = append(out, RemoveNameQualification(p.Type))
+		}
+	}
+	return strings.Join(out, Comma)
+}
+
+// ServiceToPlantUML formats a Service into PlantUML text, as an interface with
+// a service stereotype.
+func ServiceToPlantUML(s *Service, wc *WriterConfig) string {
+	relationships := ""
+	out := fmt.Sprintf("\n%sinterface %s <<service>> {\n", s.Comment.ToPlantUML(), s.Name)
+	for _, m := range s.Methods {
+		out += fmt.Sprintf("  +%s(%s) %s\n", m.Name,
+			FormatParametersForPlantUML(m.InputParameters),
+			FormatParametersForPlantUML(m.ReturnParameters))
+		for _, p := range append(append(make([]*Parameter, 0), m.InputParameters...), m.ReturnParameters...) {
+			t := strings.TrimSpace(p.Type)
+			if strings.HasSuffix(t, s.Name) {
+				t = RemoveNameQualification(t)
+			}
+			if p.Stream {
+				relationships += TypeRelationshipToPlantUML(s.Name, "--o", t, p.Resolved, wc, Empty)
+			} else {
+				relationships += TypeRelationshipToPlantUML(s.Name, "-->", t, p.Resolved, wc, Empty)
+			}
+		}
+	}
+	out += "}\n"
+	return out + relationships
+}
@@ -0,0 +1,179 @@
+/*
+ * Copyright 2023 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageToPlantUML(t *testing.T) {
+	p, err := ParseString("a.proto", `syntax = "proto3";
+package test.library;
+
+import "google/protobuf/timestamp.proto";
+
+// A book
+message Book {
+  string name = 1;
+  repeated string authors = 2;
+  map<string, Book> related = 3;
+  google.protobuf.Timestamp published = 4;
+  enum Status {
+    STATUS_UNSPECIFIED = 0;
+  }
+  Status status = 5;
+}
+
+service Library {
+  rpc GetBook(Book) returns (stream Book);
+}`)
+	assert.Nil(t, err)
+	Link(p)
+	timestamp := &Symbol{Name: "google.protobuf.Timestamp", Kind: SymbolMessage, Package: &Package{Name: "google.protobuf", Bundled: true}}
+	p.Messages[0].Attributes[3].Resolved = []*Symbol{timestamp}
+
+	assert.Equal(t, `' PlantUML Diagram for package: test.library
+package test.library {
+
+' A book
+class Book {
+  + string name
+  + List<string> authors
+  + Map<string, Book> related
+  + google.protobuf.Timestamp published
+  + Status status
+}
+Book .. Book
+Book --> google.protobuf.Timestamp
+class google.protobuf.Timestamp <<external>>
+Book --> Book_Status
+Book --o Book_Status
+
+enum Book_Status <<enumeration>> {
+  STATUS_UNSPECIFIED
+}
+
+interface Library <<service>> {
+  +GetBook(Book) Stream<Book>
+}
+Library --> Book
+Library --o Book
+}
+`, PackageToPlantUML(p, &WriterConfig{}))
+
+	assert.NotContains(t, PackageToPlantUML(p, &WriterConfig{external: ExternalHidden}), "google.protobuf.Timestamp <<external>>")
+	p.Name = ""
+	assert.NotContains(t, PackageToPlantUML(p, &WriterConfig{}), "\npackage ")
+}
+
+func TestMessageToPlantUML_Oneof(t *testing.T) {
+	m := NewMessage()
+	m.Name = "Payment"
+	id := &Attribute{Qualified: &Qualified{Name: "id"}, Kind: []string{"string"}, Ordinal: 1}
+	card := &Attribute{Qualified: &Qualified{Name: "card"}, Kind: []string{"Card"}, Ordinal: 2}
+	o := NewOneof("test.Payment", "method", "")
+	o.AddAttribute(card)
+	m.Attributes = []*Attribute{id, card}
+	m.Oneofs = []*Oneof{o}
+
+	assert.Equal(t, "\nclass Payment {\n  + string id\n}\n"+
+		"\nclass Payment_method <<oneof>> {\n  + Card card\n}\n"+
+		"Payment ..> Payment_method : oneof\n"+
+		"Payment_method --> Card\n", MessageToPlantUML(m, &WriterConfig{}))
+}
+
+func TestMessageToPlantUML_Nested(t *testing.T) {
+	p, err := ParseString("a.proto", `package test;
+message Book {
+  message Review {
+    Review parent = 1;
+  }
+  enum Status {
+    STATUS_UNSPECIFIED = 0;
+  }
+  Review review = 1;
+  Status status = 2;
+}
+message Shelf {
+  enum Status {
+    STATUS_UNSPECIFIED = 0;
+  }
+  Status status = 1;
+  Book.Review review = 2;
+}`)
+	assert.Nil(t, err)
+	Link(p)
+	assert.Equal(t, "\nclass Book {\n  + Review review\n  + Status status\n}\n"+
+		"Book --> Book_Review\n"+
+		"Book --> Book_Status\n"+
+		"Book --o Book_Review\n"+
+		"\nclass Book_Review {\n  + Review parent\n}\n"+
+		"Book_Review --> Book_Review\n"+
+		"Book --o Book_Status\n"+
+		"\nenum Book_Status <<enumeration>> {\n  STATUS_UNSPECIFIED\n}\n", MessageToPlantUML(p.Messages[0], &WriterConfig{}))
+	assert.Equal(t, "\nclass Shelf {\n  + Status status\n  + Book.Review review\n}\n"+
+		"Shelf --> Shelf_Status\n"+
+		"Shelf --> Book_Review\n"+
+		"Shelf --o Shelf_Status\n"+
+		"\nenum Shelf_Status <<enumeration>> {\n  STATUS_UNSPECIFIED\n}\n", MessageToPlantUML(p.Messages[1], &WriterConfig{}))
+}
+
+func TestPlantUMLTypeName(t *testing.T) {
+	library := &Package{Name: "test.library"}
+	tests := []struct {
+		name     string
+		kind     string
+		resolved *Symbol
+		want     string
+	}{
+		{name: "Unresolved", kind: ".test.library.Book.Status", want: "test.library.Book.Status"},
+		{name: "Top Level", kind: "Book", resolved: &Symbol{Name: "test.library.Book", Kind: SymbolMessage, Package: library}, want: "Book"},
+		{name: "Nested", kind: "Status", resolved: &Symbol{Name: "test.library.Book.Status", Kind: SymbolEnum, Package: library}, want: "Book_Status"},
+		{name: "Nested Qualified", kind: "Book.Status", resolved: &Symbol{Name: "test.library.Book.Status", Kind: SymbolEnum, Package: library}, want: "Book_Status"},
+		{name: "Other Package", kind: "library.Book.Status", resolved: &Symbol{Name: "test.library.Book.Status", Kind: SymbolEnum, Package: library}, want: "library.Book_Status"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, PlantUMLTypeName(tt.kind, tt.resolved))
+		})
+	}
+}
+
+func TestExtensionToPlantUML(t *testing.T) {
+	e := NewExtension("test", ".google.protobuf.FieldOptions", "")
+	e.AddAttribute(&Attribute{Qualified: &Qualified{Name: "sensitive"}, Optional: true, Kind: []string{"bool"}, Ordinal: 50000})
+	assert.Equal(t, "\nclass test_FieldOptionsExtension_2 <<extension>> {\n  + Optional<bool> sensitive\n}\n"+
+		"test_FieldOptionsExtension_2 --|> google.protobuf.FieldOptions : extends\n", ExtensionToPlantUML(e, 1, &WriterConfig{}))
+}
+
+func TestParseDiagramStyle(t *testing.T) {
+	style, err := ParseDiagramStyle("plantuml")
+	assert.Nil(t, err)
+	assert.Equal(t, DiagramPlantUML, style)
+	_, err = ParseDiagramStyle("dot")
+	assert.EqualError(t, err, `unknown diagram style "dot", expected mermaid or plantuml`)
+}
+
+func TestToDiagram(t *testing.T) {
+	e := NewEnum("test", "Status", "")
+	e.Values = []*EnumValue{{Value: "STATUS_UNSPECIFIED"}}
+	assert.Equal(t, "### Status Diagram\n\n```plantuml\n@startuml\nset separator none\nleft to right direction\n"+
+		"\nenum Status <<enumeration>> {\n  STATUS_UNSPECIFIED\n}\n\n@enduml\n```", ToDiagram("Status", e, &WriterConfig{diagram: DiagramPlantUML}))
+	assert.Equal(t, ToMermaid("Status", e, &WriterConfig{}), ToDiagram("Status", e, &WriterConfig{}))
+}